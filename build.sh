@@ -4,8 +4,10 @@ set -e
 
 echo "Building Twitter CLI..."
 
-# Build for current platform
-go build -o twt
+# Build for current platform. The sqlite_fts5 tag compiles in the FTS5
+# full-text search the search index needs; without it search only matches
+# text directly.
+go build -tags sqlite_fts5 -o twt
 
 echo "✅ Build complete: ./twt"
 
@@ -17,15 +19,15 @@ then
     echo "Building for multiple platforms..."
     
     # macOS
-    GOOS=darwin GOARCH=amd64 go build -o dist/twt-darwin-amd64
-    GOOS=darwin GOARCH=arm64 go build -o dist/twt-darwin-arm64
+    GOOS=darwin GOARCH=amd64 go build -tags sqlite_fts5 -o dist/twt-darwin-amd64
+    GOOS=darwin GOARCH=arm64 go build -tags sqlite_fts5 -o dist/twt-darwin-arm64
     
     # Linux
-    GOOS=linux GOARCH=amd64 go build -o dist/twt-linux-amd64
-    GOOS=linux GOARCH=arm64 go build -o dist/twt-linux-arm64
+    GOOS=linux GOARCH=amd64 go build -tags sqlite_fts5 -o dist/twt-linux-amd64
+    GOOS=linux GOARCH=arm64 go build -tags sqlite_fts5 -o dist/twt-linux-arm64
     
     # Windows
-    GOOS=windows GOARCH=amd64 go build -o dist/twt-windows-amd64.exe
+    GOOS=windows GOARCH=amd64 go build -tags sqlite_fts5 -o dist/twt-windows-amd64.exe
     
     echo "✅ All builds complete in ./dist/"
 fi
\ No newline at end of file
@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"time"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -12,36 +11,20 @@ import (
 var blockCmd = &cobra.Command{
 	Use:   "block [username]",
 	Short: "Block a user",
-	Args:  cobra.ExactArgs(1),
+	Long: `Blocks a user. Neither of you can follow, like, reply to, retweet or
+message the other, any follows between you are removed, and their posts,
+mentions and notifications stop showing up for you.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		targetUsername := args[0]
 
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in")
-		}
-
-		userStore := store.NewUserStore(DB)
-
-		blocker, err := userStore.GetByUsername(username)
+		blocker, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		blocked, err := userStore.GetByUsername(targetUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", targetUsername)
-		}
-
-		if blocker.ID == blocked.ID {
-			return fmt.Errorf("you cannot block yourself")
-		}
-
-		// Insert block
-		query := `INSERT OR IGNORE INTO blocks (blocker_id, blocked_id, created_at) VALUES (?, ?, ?)`
-		_, err = DB.Exec(query, blocker.ID, blocked.ID, time.Now().Unix())
-		if err != nil {
-			return fmt.Errorf("failed to block user: %w", err)
+		if _, err := services.Blocks.Block(blocker.ID, targetUsername); err != nil {
+			return explainUserNotFound(err, targetUsername)
 		}
 
 		fmt.Printf("Blocked @%s\n", targetUsername)
@@ -56,33 +39,16 @@ var unblockCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		targetUsername := args[0]
 
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in")
-		}
-
-		userStore := store.NewUserStore(DB)
-
-		blocker, err := userStore.GetByUsername(username)
+		blocker, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		blocked, err := userStore.GetByUsername(targetUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", targetUsername)
-		}
-
-		// Remove block
-		query := `DELETE FROM blocks WHERE blocker_id = ? AND blocked_id = ?`
-		result, err := DB.Exec(query, blocker.ID, blocked.ID)
-		if err != nil {
-			return fmt.Errorf("failed to unblock user: %w", err)
-		}
-
-		rows, _ := result.RowsAffected()
-		if rows == 0 {
-			return fmt.Errorf("user @%s was not blocked", targetUsername)
+		if _, err := services.Blocks.Unblock(blocker.ID, targetUsername); err != nil {
+			if errors.Is(err, store.ErrNotBlocked) {
+				return fmt.Errorf("user @%s was not blocked", targetUsername)
+			}
+			return explainUserNotFound(err, targetUsername)
 		}
 
 		fmt.Printf("Unblocked @%s\n", targetUsername)
@@ -94,40 +60,30 @@ var blockListCmd = &cobra.Command{
 	Use:   "blocked",
 	Short: "List blocked users",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		page, err := pageFlags(cmd)
 		if err != nil {
 			return err
 		}
 
-		query := `
-			SELECT u.username 
-			FROM blocks b
-			JOIN users u ON b.blocked_id = u.id
-			WHERE b.blocker_id = ?
-			ORDER BY u.username
-		`
-
-		rows, err := DB.Query(query, user.ID)
+		blockedUsers, err := services.Blocks.Blocked(user.ID, page.Probe())
 		if err != nil {
-			return fmt.Errorf("failed to get blocked users: %w", err)
+			return err
 		}
-		defer rows.Close()
+		blockedUsers, next := store.Paginate(blockedUsers, page)
 
-		var blockedUsers []string
-		for rows.Next() {
-			var username string
-			if err := rows.Scan(&username); err != nil {
-				return err
-			}
-			blockedUsers = append(blockedUsers, username)
+		if machineReadable() {
+			return renderPage(blockedUsers, page, next)
 		}
 
+		if len(blockedUsers) == 0 && paging(page) {
+			fmt.Println("No more users.")
+			return nil
+		}
 		if len(blockedUsers) == 0 {
 			fmt.Println("You haven't blocked anyone.")
 			return nil
@@ -135,9 +91,10 @@ var blockListCmd = &cobra.Command{
 
 		fmt.Println("Blocked users:")
 		for _, u := range blockedUsers {
-			fmt.Printf("  @%s\n", u)
+			fmt.Printf("  @%s\n", u.Username)
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -146,4 +103,6 @@ func init() {
 	rootCmd.AddCommand(blockCmd)
 	rootCmd.AddCommand(unblockCmd)
 	rootCmd.AddCommand(blockListCmd)
+
+	addPageFlags(blockListCmd, 50)
 }
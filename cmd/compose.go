@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+var composeCmd = &cobra.Command{
+	Use:   "compose",
+	Short: "Write a post or thread in your editor",
+	Long: `Opens $VISUAL or $EDITOR (vi if neither is set) to write a post. Put a line
+of just --- between posts to write a thread; anything over 280 characters is
+split between words too. Thread posts are numbered ("1/5") and published as
+a chain of replies, all at once. Save an empty file to cancel.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := loggedInUser(); err != nil {
+			return err
+		}
+
+		text, err := editText("")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			fmt.Println("Nothing to post.")
+			return nil
+		}
+
+		return publishThread(text, postImages)
+	},
+}
+
+// readText reads a post's text from a file, or from stdin for "-"
+func readText(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// editText opens the user's editor on a file holding initial and returns
+// what they saved
+func editText(initial string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "twt-compose-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create draft file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(initial)
+	f.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write draft file: %w", err)
+	}
+
+	// The editor may come with arguments, as in "code --wait"
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], f.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	return readText(f.Name())
+}
+
+// publishThread splits text into a thread and publishes it, attaching
+// images to the first post
+func publishThread(text string, images []string) error {
+	user, err := loggedInUser()
+	if err != nil {
+		return err
+	}
+
+	parts := parser.SplitThread(text, validation.MaxPostLength)
+	if len(parts) == 0 {
+		return errors.New("post cannot be empty")
+	}
+
+	posts := make([]service.NewPost, len(parts))
+	for i, part := range parts {
+		posts[i] = service.NewPost{Text: part}
+	}
+	posts[0].Images = images
+
+	published, err := services.Posts.PublishThread(user, posts)
+	if err != nil {
+		return err
+	}
+
+	if len(published) == 1 {
+		fmt.Printf("Posted: %s\n", published[0].Post.ID)
+		return nil
+	}
+
+	fmt.Printf("Posted a thread of %d posts:\n", len(published))
+	for i, p := range published {
+		fmt.Printf("  %d/%d  %s\n", i+1, len(published), p.Post.ID)
+	}
+	return nil
+}
+
+func init() {
+	composeCmd.Flags().StringArrayVar(&postImages, "image", []string{}, "Attach image(s) to the first post")
+
+	rootCmd.AddCommand(composeCmd)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/config"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// serviceOptions reads the settings the services are built with from the
+// config file
+func serviceOptions() (service.Options, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return service.Options{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fanout, err := service.ParseFanout(cfg.FeedFanout)
+	if err != nil {
+		return service.Options{}, fmt.Errorf("config %s: %w", config.GetConfigPath(), err)
+	}
+
+	var editWindow time.Duration
+	if cfg.EditWindow != "" {
+		if editWindow, err = parser.ParseDuration(cfg.EditWindow); err != nil {
+			return service.Options{}, fmt.Errorf("config %s: edit_window: %w", config.GetConfigPath(), err)
+		}
+	}
+
+	return service.Options{Fanout: fanout, FanoutLimit: cfg.FanoutLimit, EditWindow: editWindow}, nil
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show settings",
+	Long: `Shows the settings saved in the config file.
+
+  feed_fanout   read (default): feeds are queried from the posts of everyone
+                you follow when read. write: each post is copied into its
+                followers' timelines when posted, so reading is one lookup.
+  fanout_limit  With feed_fanout write, posts by users with more followers
+                than this aren't copied but merged in when feeds are read
+                (default 1000).
+  edit_window   How long after posting a post can be edited with twt edit
+                (default 1h).
+
+  twt config set feed_fanout write
+  twt config set fanout_limit 5000
+  twt config set edit_window 30m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		limit := opts.FanoutLimit
+		if limit == 0 {
+			limit = service.DefaultFanoutLimit
+		}
+
+		fmt.Printf("feed_fanout   %s\n", opts.Fanout)
+		fmt.Printf("fanout_limit  %d\n", limit)
+
+		window := opts.EditWindow
+		if window == 0 {
+			window = service.DefaultEditWindow
+		}
+		fmt.Printf("edit_window   %s\n", window)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return err
+		}
+		before, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "feed_fanout":
+			fanout, err := service.ParseFanout(value)
+			if err != nil {
+				return err
+			}
+			cfg.FeedFanout = string(fanout)
+		case "fanout_limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				return fmt.Errorf("fanout_limit must be a positive number")
+			}
+			cfg.FanoutLimit = limit
+		case "edit_window":
+			if _, err := parser.ParseDuration(value); err != nil {
+				return err
+			}
+			cfg.EditWindow = value
+		default:
+			return fmt.Errorf("unknown setting %q: use feed_fanout, fanout_limit or edit_window", key)
+		}
+
+		if err := config.SaveConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("%s set to %s\n", key, value)
+
+		after, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		// Timelines aren't maintained while feeds are built on read, and
+		// the limit decides whose posts they hold
+		if after.Fanout == service.FanoutOnWrite && (after.Fanout != before.Fanout || after.FanoutLimit != before.FanoutLimit) {
+			if err := service.NewFeedService(stores.Posts, stores.Timelines, stores.Ranking, stores.Hashtags, after).Rebuild(); err != nil {
+				return err
+			}
+			fmt.Println("Rebuilt home timelines")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
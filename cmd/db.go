@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance",
+	Long:  `Inspect and manage the database schema`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Open without migrating so status and rollback see the real state
+		var err error
+		DB, err = db.Open(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		return nil
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		applied, err := db.Migrate(DB)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date.")
+		}
+
+		return nil
+	},
+}
+
+var dbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses, err := db.Status(DB)
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		for _, s := range statuses {
+			if s.Applied {
+				appliedAt := time.Unix(s.AppliedAt, 0).Format("2006-01-02 15:04")
+				fmt.Printf("  ✓ %04d_%s (applied %s)\n", s.Migration.Version, s.Migration.Name, appliedAt)
+			} else {
+				fmt.Printf("  ✗ %04d_%s (pending)\n", s.Migration.Version, s.Migration.Name)
+				pending++
+			}
+		}
+
+		fmt.Println()
+		if pending == 0 {
+			fmt.Println("Database is up to date.")
+		} else {
+			fmt.Printf("%d pending migration(s). Run: twt db migrate\n", pending)
+		}
+
+		return nil
+	},
+}
+
+var dbRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Revert the most recent migrations",
+	Long:  `Reverts the most recent migrations, newest first. Other commands refuse to run against a database whose schema is behind, so after a rollback either run an older twt binary or re-apply the migrations with twt db migrate.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+
+		reverted, err := db.Rollback(DB, steps)
+		for _, m := range reverted {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(reverted) == 0 {
+			fmt.Println("Nothing to roll back.")
+		}
+
+		return nil
+	},
+}
+
+var dbReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the full-text search index",
+	Long:  `Re-indexes every post and message for twt search. The index updates itself as posts and messages change, so this is only needed if it gets out of sync. Only builds with the sqlite_fts5 tag have an index.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		posts, messages, err := db.RebuildSearchIndex(DB)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Indexed %d post(s) and %d message(s)\n", posts, messages)
+		return nil
+	},
+}
+
+var dbReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Check and repair engagement counters",
+	Long:  `Recounts the like, retweet, reply, media, follower, following and post counters against the rows they count, and fixes any that have drifted. Use --dry-run to only report them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		drift, err := db.ReconcileCounters(DB, !dryRun)
+		if err != nil {
+			return err
+		}
+
+		if len(drift) == 0 {
+			fmt.Println("All counters are correct.")
+			return nil
+		}
+
+		for _, d := range drift {
+			fmt.Printf("  %s.%s %s: %d, counted %d\n", d.Table, d.Column, d.ID, d.Stored, d.Actual)
+		}
+
+		fmt.Println()
+		if dryRun {
+			fmt.Printf("%d counter(s) drifted. Run without --dry-run to fix them.\n", len(drift))
+		} else {
+			fmt.Printf("Fixed %d counter(s)\n", len(drift))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	dbRollbackCmd.Flags().Int("steps", 1, "Number of migrations to roll back")
+	dbReconcileCmd.Flags().Bool("dry-run", false, "Report drift without fixing it")
+
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbStatusCmd)
+	dbCmd.AddCommand(dbRollbackCmd)
+	dbCmd.AddCommand(dbReindexCmd)
+	dbCmd.AddCommand(dbReconcileCmd)
+
+	rootCmd.AddCommand(dbCmd)
+}
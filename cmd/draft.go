@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/display"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	draftImages     []string
+	draftAt         string
+	draftUnschedule bool
+)
+
+var draftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "Save posts to publish later",
+	Long: `Saves posts as drafts, to publish by hand with twt draft publish or, with
+--at, at a set time. Scheduled posts are published by twt scheduler run.
+Images are copied when a draft is saved, so they're still there when it's
+published.`,
+}
+
+var draftSaveCmd = &cobra.Command{
+	Use:   "save [text]",
+	Short: "Save a draft",
+	Long:  `Saves a draft. Pass - as the text to read it from stdin, or leave it out to write it in $EDITOR.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		at, err := parseAt(draftAt)
+		if err != nil {
+			return err
+		}
+
+		text, err := draftText(args, "")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(text) == "" {
+			fmt.Println("Nothing to save.")
+			return nil
+		}
+
+		draft, err := services.Drafts.Save(user, service.NewDraft{Text: text, Images: draftImages, PublishAt: at})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved %s (%s)\n", draft.ID, draftStatus(*draft))
+		return nil
+	},
+}
+
+var draftListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your drafts and scheduled posts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		drafts, err := services.Drafts.Drafts(user.ID, page.Probe())
+		if err != nil {
+			return err
+		}
+		drafts, next := store.Paginate(drafts, page)
+
+		if machineReadable() {
+			return renderPage(drafts, page, next)
+		}
+
+		if len(drafts) == 0 && paging(page) {
+			fmt.Println("No more drafts.")
+			return nil
+		}
+		if len(drafts) == 0 {
+			fmt.Println("You have no drafts.")
+			return nil
+		}
+
+		for _, d := range drafts {
+			fmt.Printf("%s  %s\n", d.ID, draftStatus(d))
+			fmt.Println(d.Text)
+			if len(d.Images) > 0 {
+				fmt.Printf("📷 %d image(s)\n", len(d.Images))
+			}
+			fmt.Println()
+		}
+
+		printNextPage(page, next)
+		return nil
+	},
+}
+
+var draftEditCmd = &cobra.Command{
+	Use:   "edit [draft_id] [text]",
+	Short: "Edit a draft or scheduled post",
+	Long: `Replaces a draft's text, images (--image) or publish time (--at, or
+--unschedule to keep it as a draft). With none of these, opens the draft in
+$EDITOR. Pass - as the text to read it from stdin. Editing a scheduled post
+that failed to publish lets the scheduler try it again.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		if draftAt != "" && draftUnschedule {
+			return errors.New("--at can't be used with --unschedule")
+		}
+
+		var change service.DraftChange
+		if change.PublishAt, err = parseAt(draftAt); err != nil {
+			return err
+		}
+		change.Unschedule = draftUnschedule
+		if cmd.Flags().Changed("image") {
+			change.Images = draftImages
+		}
+
+		// With nothing else to change, edit the text
+		if len(args) > 1 || (draftAt == "" && !draftUnschedule && change.Images == nil) {
+			draft, err := services.Drafts.Draft(user.ID, args[0])
+			if err != nil {
+				return err
+			}
+
+			text, err := draftText(args[1:], draft.Text)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(text) == "" {
+				return errors.New("draft cannot be empty (use twt draft delete to delete it)")
+			}
+			change.Text = &text
+		}
+
+		draft, err := services.Drafts.Edit(user.ID, args[0], change)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved %s (%s)\n", draft.ID, draftStatus(*draft))
+		return nil
+	},
+}
+
+var draftDeleteCmd = &cobra.Command{
+	Use:   "delete [draft_id]",
+	Short: "Delete a draft or scheduled post",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		if _, err := services.Drafts.Delete(user.ID, args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted draft %s\n", args[0])
+		return nil
+	},
+}
+
+var draftPublishCmd = &cobra.Command{
+	Use:   "publish [draft_id]",
+	Short: "Publish a draft now",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		published, err := services.Drafts.Publish(user, args[0])
+		if err != nil {
+			return err
+		}
+
+		showPublished(published)
+		return nil
+	},
+}
+
+// schedulePost saves a post for twt scheduler run to publish at a time
+func schedulePost(text string, images []string, when string) error {
+	user, err := loggedInUser()
+	if err != nil {
+		return err
+	}
+
+	at, err := parseAt(when)
+	if err != nil {
+		return err
+	}
+
+	draft, err := services.Drafts.Save(user, service.NewDraft{Text: text, Images: images, PublishAt: at})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Scheduled %s for %s\n", draft.ID, formatPublishAt(*draft.PublishAt))
+	fmt.Println("Posts are published by twt scheduler run.")
+	return nil
+}
+
+// draftText reads a draft's text from args: the text itself, or - for
+// stdin. With no args it's written in $EDITOR, starting from initial.
+func draftText(args []string, initial string) (string, error) {
+	if len(args) == 0 {
+		return editText(initial)
+	}
+	if args[0] == "-" {
+		return readText("-")
+	}
+	return args[0], nil
+}
+
+// parseAt parses an --at flag, which may be empty
+func parseAt(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	at, err := parser.ParseTime(s, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func formatPublishAt(publishAt int64) string {
+	return time.Unix(publishAt, 0).Format("Mon Jan 2 15:04")
+}
+
+// draftStatus says whether a draft is scheduled, and if so whether it
+// failed to publish
+func draftStatus(d models.Draft) string {
+	switch {
+	case d.PublishAt == nil:
+		return "draft"
+	case d.Error != nil:
+		return fmt.Sprintf("failed to publish at %s: %s", formatPublishAt(*d.PublishAt), *d.Error)
+	default:
+		return "scheduled for " + formatPublishAt(*d.PublishAt)
+	}
+}
+
+var schedulerEvery time.Duration
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Publish scheduled posts and close polls",
+}
+
+var schedulerRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Publish every scheduled post that's due",
+	Long: `Publishes every user's scheduled posts whose time has come, including any
+missed while the scheduler wasn't running, and notifies the authors of polls
+that have closed. Run it from cron, or keep it running with --every. A post
+that can't be published, say because its image has gone missing, is marked
+failed in twt draft list until edited.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := runScheduler()
+		if err != nil {
+			return err
+		}
+
+		if schedulerEvery == 0 {
+			if n == 0 {
+				fmt.Println("No scheduled posts or polls are due.")
+			}
+			return nil
+		}
+
+		return watch(schedulerEvery, func() error {
+			_, err := runScheduler()
+			return err
+		})
+	},
+}
+
+// runScheduler publishes due posts and reports what became of each, then
+// notifies the authors of closed polls, returning how many of both there
+// were
+func runScheduler() (int, error) {
+	now := time.Now()
+	results, err := services.Drafts.PublishDue(now)
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("Failed to publish draft %s: %v\n", r.Draft.ID, r.Err)
+			continue
+		}
+
+		// Say how long a missed post was overdue
+		late := ""
+		if now.Sub(time.Unix(*r.Draft.PublishAt, 0)) >= time.Minute {
+			late = ", due " + display.FormatTimeAgo(*r.Draft.PublishAt)
+		}
+		fmt.Printf("Published %s (scheduled for %s%s)\n", r.Post.Post.ID, formatPublishAt(*r.Draft.PublishAt), late)
+	}
+
+	if err != nil {
+		return len(results), err
+	}
+
+	closed, err := services.Polls.NotifyClosed(now)
+	for _, post := range closed {
+		fmt.Printf("Poll closed on %s\n", post.ID)
+	}
+
+	return len(results) + len(closed), err
+}
+
+func init() {
+	draftSaveCmd.Flags().StringArrayVar(&draftImages, "image", []string{}, "Attach image(s) to the draft")
+	draftSaveCmd.Flags().StringVar(&draftAt, "at", "", `Schedule the post (e.g. "2026-11-01 09:00", 09:00 or 2h)`)
+	draftEditCmd.Flags().StringArrayVar(&draftImages, "image", []string{}, "Replace the draft's images")
+	draftEditCmd.Flags().StringVar(&draftAt, "at", "", "Reschedule the post")
+	draftEditCmd.Flags().BoolVar(&draftUnschedule, "unschedule", false, "Keep the post as a draft instead of publishing it")
+	schedulerRunCmd.Flags().DurationVar(&schedulerEvery, "every", 0, "Keep running, checking for due posts this often (e.g. 1m)")
+	addPageFlags(draftListCmd, 20)
+
+	draftCmd.AddCommand(draftSaveCmd)
+	draftCmd.AddCommand(draftListCmd)
+	draftCmd.AddCommand(draftEditCmd)
+	draftCmd.AddCommand(draftDeleteCmd)
+	draftCmd.AddCommand(draftPublishCmd)
+	schedulerCmd.AddCommand(schedulerRunCmd)
+
+	rootCmd.AddCommand(draftCmd)
+	rootCmd.AddCommand(schedulerCmd)
+}
@@ -2,46 +2,93 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/display"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	feedLimit  int
-	feedOffset int
+	feedFollow    bool
+	feedBell      bool
+	feedInterval  time.Duration
+	feedAlgo      string
+	feedExplain   bool
+	feedResetSeen bool
 )
 
 var feedCmd = &cobra.Command{
 	Use:   "feed",
 	Short: "View your personalized feed",
-	Long:  `Shows posts from users you follow and your own posts, sorted by time`,
+	Long: `Shows posts from users you follow and your own posts, sorted by time.
+
+With --follow, keeps running after the feed is shown and prints new posts as
+they arrive until you press Ctrl-C. --bell rings the terminal bell when a new
+post mentions you.
+
+With --algo ranked, shows a "For You" feed instead: posts from the people you
+follow, posts they liked or retweeted, trending posts and posts from further
+out in your network, best first. Each post is shown once; run it again for
+the next ones, or use --reset-seen to start over. --explain shows how each
+post was scored.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Check if logged in
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		// Get user ID
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		switch feedAlgo {
+		case "chrono":
+		case "ranked":
+			return rankedFeed(cmd, user)
+		default:
+			return fmt.Errorf("unknown --algo %q: use chrono or ranked", feedAlgo)
+		}
+
+		if feedFollow {
+			if err := checkLiveOutput("--follow"); err != nil {
+				return err
+			}
+		}
+
+		// Start the cursor before reading the feed so nothing posted in
+		// between is missed
+		cursor := watchCursor()
+
+		page, err := pageFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
 		// Get feed
-		postStore := store.NewPostStore(DB)
-		posts, err := postStore.GetFeed(user.ID, feedLimit, feedOffset)
+		posts, err := services.Feed.Feed(user.ID, page.Probe())
 		if err != nil {
 			return err
 		}
+		posts, next := store.Paginate(posts, page)
+
+		if feedFollow {
+			for _, pwa := range posts {
+				cursor = laterCursor(cursor, pwa.Cursor())
+			}
+		}
+
+		if machineReadable() {
+			if !feedFollow {
+				return renderPage(posts, page, next)
+			}
+			if err := render(posts); err != nil {
+				return err
+			}
+			return followFeed(user, cursor)
+		}
 
 		// Display feed
-		if len(posts) == 0 {
-			if feedOffset > 0 {
+		if len(posts) == 0 && !feedFollow {
+			if paging(page) {
 				fmt.Println("No more posts.")
 			} else {
 				fmt.Println("Your feed is empty. Follow some users and start posting!")
@@ -49,25 +96,111 @@ var feedCmd = &cobra.Command{
 			return nil
 		}
 
-		mediaStore := store.NewMediaStore(DB)
-		for _, pwa := range posts {
-			mediaCount, _ := mediaStore.GetMediaCount(pwa.Post.ID)
-			fmt.Println(display.FormatPostWithMedia(pwa, mediaCount))
+		printFeedPosts(posts)
+
+		if feedFollow {
+			fmt.Println("Waiting for new posts... (Ctrl-C to stop)")
 			fmt.Println()
+			return followFeed(user, cursor)
 		}
 
-		// Show pagination info
-		if len(posts) == feedLimit {
-			fmt.Printf("\nShowing %d posts. Use --offset %d to see more.\n", feedLimit, feedOffset+feedLimit)
+		printNextPage(page, next)
+		return nil
+	},
+}
+
+// rankedFeed shows the next posts of the user's ranked feed
+func rankedFeed(cmd *cobra.Command, user *models.User) error {
+	page, err := pageFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if paging(page) || feedFollow {
+		return fmt.Errorf("--before, --after and --follow only work with --algo chrono")
+	}
+
+	if feedResetSeen {
+		if err := services.Feed.ResetSeen(user.ID); err != nil {
+			return err
 		}
+	}
+
+	ranked, err := services.Feed.Ranked(user.ID, page.Limit)
+	if err != nil {
+		return err
+	}
+
+	if machineReadable() {
+		return render(ranked)
+	}
 
+	if len(ranked) == 0 {
+		fmt.Println("You're all caught up. Use --reset-seen to see posts again.")
 		return nil
-	},
+	}
+
+	for _, c := range ranked {
+		fmt.Println(display.FormatPostWithMedia(c.PostWithAuthor, c.Post.MediaCount))
+		if feedExplain {
+			var factors []string
+			for _, f := range c.Factors {
+				factors = append(factors, fmt.Sprintf("%s %.2f", f.Scorer, f.Value))
+			}
+			fmt.Printf("  score %.3f from %s: %s\n", c.Score, c.Source, strings.Join(factors, " × "))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func printFeedPosts(posts []store.PostWithAuthor) {
+	for _, pwa := range posts {
+		fmt.Println(display.FormatPostWithMedia(pwa, pwa.Post.MediaCount))
+		fmt.Println()
+	}
+}
+
+// followFeed prints posts that reach the user's feed after cursor, oldest
+// first, until interrupted
+func followFeed(user *models.User, cursor models.Cursor) error {
+	fetch := func(p store.Page) ([]store.PostWithAuthor, error) {
+		return services.Feed.Feed(user.ID, p)
+	}
+
+	return watch(feedInterval, func() error {
+		var err error
+		cursor, err = pollAfter(cursor, fetch, func(posts []store.PostWithAuthor) error {
+			if machineReadable() {
+				if err := render(posts); err != nil {
+					return err
+				}
+			} else {
+				printFeedPosts(posts)
+			}
+
+			if feedBell {
+				for _, pwa := range posts {
+					if pwa.Post.AuthorID != user.ID && mentions(pwa.Post.Text, user.Username) {
+						ringBell()
+						break
+					}
+				}
+			}
+			return nil
+		})
+		return err
+	})
 }
 
 func init() {
-	feedCmd.Flags().IntVar(&feedLimit, "limit", 20, "Number of posts to show")
-	feedCmd.Flags().IntVar(&feedOffset, "offset", 0, "Number of posts to skip")
+	addPageFlags(feedCmd, 20)
+	feedCmd.Flags().BoolVarP(&feedFollow, "follow", "f", false, "Keep printing new posts as they arrive")
+	feedCmd.Flags().BoolVar(&feedBell, "bell", false, "Ring the terminal bell on new mentions (with --follow)")
+	feedCmd.Flags().DurationVar(&feedInterval, "interval", 2*time.Second, "How often to check for new posts (with --follow)")
+	feedCmd.Flags().StringVar(&feedAlgo, "algo", "chrono", "Feed order: chrono or ranked")
+	feedCmd.Flags().BoolVar(&feedExplain, "explain", false, "Show how each post was scored (with --algo ranked)")
+	feedCmd.Flags().BoolVar(&feedResetSeen, "reset-seen", false, "Show posts already seen again (with --algo ranked)")
 
 	rootCmd.AddCommand(feedCmd)
 }
@@ -16,21 +16,33 @@ var hashtagCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tag := args[0]
-		limit, _ := cmd.Flags().GetInt("limit")
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		// Remove # if user included it
 		if len(tag) > 0 && tag[0] == '#' {
 			tag = tag[1:]
 		}
 
-		hashtagStore := store.NewHashtagStore(DB)
-		posts, err := hashtagStore.GetPostsByHashtag(tag, limit)
+		hashtagStore := stores.Hashtags
+		posts, err := hashtagStore.GetPostsByHashtag(tag, viewerID(), page.Probe())
 		if err != nil {
 			return err
 		}
+		posts, next := store.Paginate(posts, page)
+
+		if machineReadable() {
+			return renderPage(posts, page, next)
+		}
 
 		if len(posts) == 0 {
-			fmt.Printf("No posts found with #%s\n", tag)
+			if paging(page) {
+				fmt.Printf("No more posts with #%s\n", tag)
+			} else {
+				fmt.Printf("No posts found with #%s\n", tag)
+			}
 			return nil
 		}
 
@@ -39,11 +51,12 @@ var hashtagCmd = &cobra.Command{
 		// Display posts with highlighted text
 		for _, pwa := range posts {
 			timeAgo := display.FormatTimeAgo(pwa.Post.CreatedAt)
-			fmt.Printf("%s  @%s  %s\n", pwa.Post.ID, pwa.Username, timeAgo)
+			fmt.Printf("%s  @%s  %s%s\n", pwa.Post.ID, pwa.Username, timeAgo, display.Edited(pwa.Post))
 			fmt.Printf("%s\n", parser.HighlightText(pwa.Post.Text))
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -58,12 +71,16 @@ var trendingCmd = &cobra.Command{
 		// Calculate "since" timestamp
 		since := time.Now().AddDate(0, 0, -days).Unix()
 
-		hashtagStore := store.NewHashtagStore(DB)
+		hashtagStore := stores.Hashtags
 		trending, err := hashtagStore.GetTrendingHashtags(limit, since)
 		if err != nil {
 			return err
 		}
 
+		if machineReadable() {
+			return render(trending)
+		}
+
 		if len(trending) == 0 {
 			fmt.Println("No trending hashtags found.")
 			return nil
@@ -80,7 +97,7 @@ var trendingCmd = &cobra.Command{
 }
 
 func init() {
-	hashtagCmd.Flags().Int("limit", 50, "Number of posts to show")
+	addPageFlags(hashtagCmd, 50)
 	trendingCmd.Flags().Int("limit", 10, "Number of hashtags to show")
 	trendingCmd.Flags().Int("days", 7, "Look back this many days")
 
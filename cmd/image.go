@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 	"runtime"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +23,7 @@ var imageDownloadCmd = &cobra.Command{
 		postID := args[0]
 		outputDir, _ := cmd.Flags().GetString("output")
 
-		mediaStore := store.NewMediaStore(DB)
+		mediaStore := stores.Media
 		mediaList, err := mediaStore.GetByPostID(postID)
 		if err != nil {
 			return err
@@ -71,7 +70,7 @@ var imageViewCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		mediaStore := store.NewMediaStore(DB)
+		mediaStore := stores.Media
 		mediaList, err := mediaStore.GetByPostID(postID)
 		if err != nil {
 			return err
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/display"
 	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
@@ -14,44 +13,51 @@ var mentionsCmd = &cobra.Command{
 	Use:   "mentions",
 	Short: "View posts that mention you",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		limit, _ := cmd.Flags().GetInt("limit")
-
-		username, err := config.GetCurrentUser()
+		page, err := pageFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		mentionStore := store.NewMentionStore(DB)
-		posts, err := mentionStore.GetMentions(user.ID, limit)
+		mentionStore := stores.Mentions
+		posts, err := mentionStore.GetMentions(user.ID, page.Probe())
 		if err != nil {
 			return err
 		}
+		posts, next := store.Paginate(posts, page)
+
+		if machineReadable() {
+			return renderPage(posts, page, next)
+		}
 
 		if len(posts) == 0 {
-			fmt.Println("No one has mentioned you yet.")
+			if paging(page) {
+				fmt.Println("No more mentions.")
+			} else {
+				fmt.Println("No one has mentioned you yet.")
+			}
 			return nil
 		}
 
-		fmt.Printf("Posts mentioning @%s:\n\n", username)
+		fmt.Printf("Posts mentioning @%s:\n\n", user.Username)
 
 		for _, pwa := range posts {
 			timeAgo := display.FormatTimeAgo(pwa.Post.CreatedAt)
-			fmt.Printf("%s  @%s  %s\n", pwa.Post.ID, pwa.Username, timeAgo)
+			fmt.Printf("%s  @%s  %s%s\n", pwa.Post.ID, pwa.Username, timeAgo, display.Edited(pwa.Post))
 			fmt.Printf("%s\n", parser.HighlightText(pwa.Post.Text))
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
 
 func init() {
-	mentionsCmd.Flags().Int("limit", 50, "Number of mentions to show")
+	addPageFlags(mentionsCmd, 50)
 	rootCmd.AddCommand(mentionsCmd)
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/display"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
@@ -24,39 +23,13 @@ var messageSendCmd = &cobra.Command{
 		receiverUsername := args[0]
 		text := strings.Join(args[1:], " ")
 
-		senderUsername, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		userStore := store.NewUserStore(DB)
-
-		sender, err := userStore.GetByUsername(senderUsername)
-		if err != nil {
-			return fmt.Errorf("failed to get sender: %w", err)
-		}
-
-		receiver, err := userStore.GetByUsername(receiverUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", receiverUsername)
-		}
-
-		if sender.ID == receiver.ID {
-			return fmt.Errorf("you cannot message yourself")
-		}
-
-		// Send message
-		messageStore := store.NewMessageStore(DB)
-		message, err := messageStore.Send(sender.ID, receiver.ID, text)
+		sender, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Create notification
-		notifStore := store.NewNotificationStore(DB)
-		messageID := message.ID
-		if err := notifStore.Create(receiver.ID, sender.ID, "message", &messageID); err != nil {
-			fmt.Printf("Warning: failed to create notification: %v\n", err)
+		if _, err := services.Messages.Send(sender.ID, receiverUsername, text); err != nil {
+			return explainUserNotFound(err, receiverUsername)
 		}
 
 		fmt.Printf("Message sent to @%s\n", receiverUsername)
@@ -68,29 +41,35 @@ var messageInboxCmd = &cobra.Command{
 	Use:   "inbox",
 	Short: "View your inbox",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		limit, _ := cmd.Flags().GetInt("limit")
-
-		// Check if logged in
-		username, err := config.GetCurrentUser()
+		page, err := pageFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		// Check if logged in
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
 		// Get inbox
-		messageStore := store.NewMessageStore(DB)
-		messages, err := messageStore.GetInbox(user.ID, limit)
+		messageStore := stores.Messages
+		messages, err := messageStore.GetInbox(user.ID, page.Probe())
 		if err != nil {
 			return err
 		}
+		messages, next := store.Paginate(messages, page)
+
+		if machineReadable() {
+			return renderPage(messages, page, next)
+		}
 
 		if len(messages) == 0 {
-			fmt.Println("Your inbox is empty.")
+			if paging(page) {
+				fmt.Println("No more messages.")
+			} else {
+				fmt.Println("Your inbox is empty.")
+			}
 			return nil
 		}
 
@@ -109,6 +88,7 @@ var messageInboxCmd = &cobra.Command{
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -119,50 +99,43 @@ var messageConversationCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		otherUsername := args[0]
-		limit, _ := cmd.Flags().GetInt("limit")
-
-		// Check if logged in
-		currentUsername, err := config.GetCurrentUser()
+		page, err := pageFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-
-		// Get current user
-		currentUser, err := userStore.GetByUsername(currentUsername)
+		// Check if logged in
+		currentUser, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("failed to get current user: %w", err)
+			return err
 		}
 
-		// Get other user
-		otherUser, err := userStore.GetByUsername(otherUsername)
+		// Reading the first page of a conversation marks it as read
+		messages, err := services.Messages.Conversation(currentUser.ID, otherUsername, page.Probe())
 		if err != nil {
-			return fmt.Errorf("user @%s not found", otherUsername)
+			return explainUserNotFound(err, otherUsername)
 		}
+		messages, next := store.Paginate(messages, page)
 
-		// Get conversation
-		messageStore := store.NewMessageStore(DB)
-		messages, err := messageStore.GetConversation(currentUser.ID, otherUser.ID, limit)
-		if err != nil {
-			return err
+		if machineReadable() {
+			return renderPage(messages, page, next)
 		}
 
 		if len(messages) == 0 {
-			fmt.Printf("No messages with @%s yet.\n", otherUsername)
+			if paging(page) {
+				fmt.Printf("No more messages with @%s.\n", otherUsername)
+			} else {
+				fmt.Printf("No messages with @%s yet.\n", otherUsername)
+			}
 			return nil
 		}
 
-		// Mark as read
-		if err := messageStore.MarkAsRead(currentUser.ID, otherUser.ID); err != nil {
-			return err
-		}
-
-		// Display conversation
+		// Display conversation, oldest first
 		fmt.Printf("Conversation with @%s:\n", otherUsername)
 		fmt.Println()
 
-		for _, m := range messages {
+		for i := len(messages) - 1; i >= 0; i-- {
+			m := messages[i]
 			timeAgo := display.FormatTimeAgo(m.Message.CreatedAt)
 
 			if m.Message.SenderID == currentUser.ID {
@@ -177,6 +150,7 @@ var messageConversationCmd = &cobra.Command{
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -186,24 +160,32 @@ var messageListCmd = &cobra.Command{
 	Short: "List all conversations",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check if logged in
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		page, err := pageFlags(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
 		// Get conversations
-		messageStore := store.NewMessageStore(DB)
-		conversations, err := messageStore.GetConversations(user.ID)
+		messageStore := stores.Messages
+		conversations, err := messageStore.GetConversations(user.ID, page.Probe())
 		if err != nil {
 			return err
 		}
+		conversations, next := store.Paginate(conversations, page)
 
+		if machineReadable() {
+			return renderPage(conversations, page, next)
+		}
+
+		if len(conversations) == 0 && paging(page) {
+			fmt.Println("No more conversations.")
+			return nil
+		}
 		if len(conversations) == 0 {
 			fmt.Println("No conversations yet.")
 			return nil
@@ -225,6 +207,7 @@ var messageListCmd = &cobra.Command{
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -234,19 +217,13 @@ var messageUnreadCmd = &cobra.Command{
 	Short: "Show unread message count",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check if logged in
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
-		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
 		// Get unread count
-		messageStore := store.NewMessageStore(DB)
+		messageStore := stores.Messages
 		count, err := messageStore.GetUnreadCount(user.ID)
 		if err != nil {
 			return err
@@ -279,19 +256,12 @@ var messageDeleteCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		messageID := args[0]
 
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		messageStore := store.NewMessageStore(DB)
-		if err := messageStore.DeleteMessage(messageID, user.ID); err != nil {
+		if err := services.Messages.Delete(user.ID, messageID); err != nil {
 			return err
 		}
 
@@ -303,49 +273,63 @@ var messageDeleteCmd = &cobra.Command{
 var messageSearchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search messages by text",
-	Args:  cobra.ExactArgs(1),
+	Long: `Full-text search over your messages, best matches first. Supports "phrases" and
+prefix* words like twt search. Results are ranked rather than ordered by time,
+so they are paged with --limit and --offset instead of --before and --after.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := args[0]
+		limit, _ := cmd.Flags().GetInt("limit")
+		offset, _ := cmd.Flags().GetInt("offset")
 
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		messageStore := stores.Messages
+		results, err := messageStore.SearchMessages(user.ID, query, limit, offset)
 		if err != nil {
 			return err
 		}
 
-		messageStore := store.NewMessageStore(DB)
-		messages, err := messageStore.SearchMessages(user.ID, query)
-		if err != nil {
-			return err
+		if machineReadable() {
+			return render(results)
 		}
 
-		if len(messages) == 0 {
-			fmt.Printf("No messages found matching '%s'\n", query)
+		if len(results) == 0 {
+			if offset > 0 {
+				fmt.Println("No more results.")
+			} else {
+				fmt.Printf("No messages found matching '%s'\n", query)
+			}
 			return nil
 		}
 
-		fmt.Printf("Found %d message(s) matching '%s':\n\n", len(messages), query)
+		fmt.Printf("Found %d message(s) matching '%s':\n\n", len(results), query)
 
-		for _, m := range messages {
+		for _, m := range results {
 			timeAgo := display.FormatTimeAgo(m.Message.CreatedAt)
 			fmt.Printf("[@%s → @%s] (%s)\n", m.SenderName, m.ReceiverName, timeAgo)
-			fmt.Printf("%s\n", m.Message.Text)
+			fmt.Printf("%s\n", display.FormatSnippet(m.Snippet))
 			fmt.Println()
 		}
 
+		if len(results) == limit {
+			fmt.Printf("Showing %d results. Use --offset %d to see more.\n", limit, offset+limit)
+		}
+
 		return nil
 	},
 }
 
 func init() {
 	// Add flags
-	messageInboxCmd.Flags().Int("limit", 20, "Number of messages to show")
-	messageConversationCmd.Flags().Int("limit", 50, "Number of messages to show")
+	addPageFlags(messageInboxCmd, 20)
+	addPageFlags(messageConversationCmd, 50)
+	addPageFlags(messageListCmd, 20)
+	messageSearchCmd.Flags().Int("limit", 20, "Number of results to show")
+	messageSearchCmd.Flags().Int("offset", 0, "Number of results to skip")
 
 	// Add subcommands
 	messageCmd.AddCommand(messageSendCmd)
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var muteFor string
+
+var muteCmd = &cobra.Command{
+	Use:   "mute [@user|word|#tag]",
+	Short: "Mute a user, word or hashtag",
+	Long: `Hides a user's posts, posts containing a word or phrase, or posts with a
+hashtag from your feed, hashtag pages, search results and notifications.
+Muted users aren't told. Use --for to lift the mute automatically.
+
+  twt mute @alice
+  twt mute spoilers --for 7d
+  twt mute '#worldcup' --for 24h`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var duration time.Duration
+		if muteFor != "" {
+			var err error
+			duration, err = parser.ParseDuration(muteFor)
+			if err != nil {
+				return err
+			}
+		}
+
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		mute, err := services.Mutes.Mute(user.ID, args[0], duration)
+		if err != nil {
+			return explainUserNotFound(err, strings.TrimPrefix(args[0], "@"))
+		}
+
+		fmt.Printf("Muted %s%s\n", muteTarget(*mute), muteExpiry(*mute))
+		return nil
+	},
+}
+
+var unmuteCmd = &cobra.Command{
+	Use:   "unmute [@user|word|#tag]",
+	Short: "Unmute a user, word or hashtag",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		mute, err := services.Mutes.Unmute(user.ID, args[0])
+		if errors.Is(err, store.ErrNotMuted) {
+			return fmt.Errorf("%s was not muted", args[0])
+		}
+		if err != nil {
+			return explainUserNotFound(err, strings.TrimPrefix(args[0], "@"))
+		}
+
+		fmt.Printf("Unmuted %s\n", muteTarget(*mute))
+		return nil
+	},
+}
+
+var mutedCmd = &cobra.Command{
+	Use:   "muted",
+	Short: "List muted users, words and hashtags",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		mutes, err := services.Mutes.Muted(user.ID, page.Probe())
+		if err != nil {
+			return err
+		}
+		mutes, next := store.Paginate(mutes, page)
+
+		if machineReadable() {
+			return renderPage(mutes, page, next)
+		}
+
+		if len(mutes) == 0 && paging(page) {
+			fmt.Println("No more mutes.")
+			return nil
+		}
+		if len(mutes) == 0 {
+			fmt.Println("You haven't muted anything.")
+			return nil
+		}
+
+		fmt.Println("Muted:")
+		for _, m := range mutes {
+			fmt.Printf("  %s%s\n", muteTarget(m), muteExpiry(m))
+		}
+
+		printNextPage(page, next)
+		return nil
+	},
+}
+
+// muteTarget writes a mute the way it's typed: @user, #tag or word
+func muteTarget(m models.Mute) string {
+	switch m.Kind {
+	case models.MuteUser:
+		return "@" + m.Value
+	case models.MuteHashtag:
+		return "#" + m.Value
+	default:
+		return fmt.Sprintf("%q", m.Value)
+	}
+}
+
+func muteExpiry(m models.Mute) string {
+	if m.ExpiresAt == nil {
+		return ""
+	}
+	return " until " + time.Unix(*m.ExpiresAt, 0).Format("Jan 2 15:04")
+}
+
+func init() {
+	muteCmd.Flags().StringVar(&muteFor, "for", "", "Lift the mute after this long (e.g. 30m, 24h, 7d)")
+
+	rootCmd.AddCommand(muteCmd)
+	rootCmd.AddCommand(unmuteCmd)
+	rootCmd.AddCommand(mutedCmd)
+
+	addPageFlags(mutedCmd, 50)
+}
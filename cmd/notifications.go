@@ -1,44 +1,92 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/display"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	notifUnreadOnly bool
-	notifLimit      int
+	notifWatch      bool
+	notifBell       bool
+	notifInterval   time.Duration
 )
 
 var notificationsCmd = &cobra.Command{
 	Use:     "notifications",
 	Aliases: []string{"notifs"},
 	Short:   "View notifications",
+	Long: `Shows your notifications, with likes, retweets and follows grouped.
+
+With --watch, keeps running after they are shown and prints new notifications
+and direct messages as they arrive until you press Ctrl-C. --bell rings the
+terminal bell when someone mentions you.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		page, err := pageFlags(cmd)
 		if err != nil {
 			return err
 		}
 
-		// Get notifications
-		notifStore := store.NewNotificationStore(DB)
-		notifications, err := notifStore.GetNotifications(user.ID, notifUnreadOnly, notifLimit)
+		if notifWatch {
+			if err := checkLiveOutput("--watch"); err != nil {
+				return err
+			}
+		}
+
+		notifStore := stores.Notifications
+
+		// Anything newer than the cursors is printed live; nothing created
+		// while the list below is read is missed
+		notifCursor, messageCursor := watchCursor(), watchCursor()
+
+		// Scripts get every notification; people get them grouped
+		if machineReadable() {
+			notifications, err := notifStore.GetNotifications(user.ID, notifUnreadOnly, page.Probe())
+			if err != nil {
+				return err
+			}
+			notifications, next := store.Paginate(notifications, page)
+			if !notifWatch {
+				return renderPage(notifications, page, next)
+			}
+			if err := render(notifications); err != nil {
+				return err
+			}
+			return watchNotifications(user, notifCursor, messageCursor)
+		}
+
+		groups, err := notifStore.GetGroupedNotifications(user.ID, notifUnreadOnly, page.Probe())
 		if err != nil {
 			return err
 		}
+		groups, next := store.Paginate(groups, page)
+
+		if notifWatch {
+			if len(groups) > 0 {
+				printNotificationGroups(groups)
+				fmt.Println()
+			}
+			fmt.Println("Waiting for notifications and messages... (Ctrl-C to stop)")
+			fmt.Println()
+			return watchNotifications(user, notifCursor, messageCursor)
+		}
 
-		if len(notifications) == 0 {
-			if notifUnreadOnly {
+		if len(groups) == 0 {
+			if paging(page) {
+				fmt.Println("No more notifications.")
+			} else if notifUnreadOnly {
 				fmt.Println("No unread notifications.")
 			} else {
 				fmt.Println("No notifications yet.")
@@ -54,76 +102,102 @@ var notificationsCmd = &cobra.Command{
 		}
 		fmt.Println()
 
-		for _, n := range notifications {
-			timeAgo := display.FormatTimeAgo(n.Notification.CreatedAt)
-			unreadIndicator := ""
-			if !n.Notification.Read {
-				unreadIndicator = " 🔴"
-			}
+		total := printNotificationGroups(groups)
+
+		fmt.Println()
+		fmt.Printf("Showing %d notification(s)\n", total)
+		printNextPage(page, next)
+
+		return nil
+	},
+}
+
+// printNotificationGroups prints one line per group and returns how many
+// notifications they hold
+func printNotificationGroups(groups []models.NotificationGroup) int {
+	total := 0
+	for _, g := range groups {
+		timeAgo := display.FormatTimeAgo(g.CreatedAt)
+		unreadIndicator := ""
+		if !g.Read {
+			unreadIndicator = " 🔴"
+		}
+
+		fmt.Printf("%s (%s)%s\n", display.FormatNotificationGroup(g), timeAgo, unreadIndicator)
+		total += g.Count
+	}
+	return total
+}
+
+// watchNotifications prints notifications and direct messages the user gets
+// after the cursors, oldest first, until interrupted. Messages are printed in
+// full, so their notifications are skipped.
+func watchNotifications(user *models.User, notifCursor, messageCursor models.Cursor) error {
+	fetchNotifications := func(p store.Page) ([]models.NotificationWithDetails, error) {
+		return stores.Notifications.GetNotifications(user.ID, false, p)
+	}
+	fetchMessages := func(p store.Page) ([]models.MessageWithUser, error) {
+		return stores.Messages.GetInbox(user.ID, p)
+	}
 
-			// Format based on type
-			var message string
-			switch n.Notification.Type {
-			case "like":
-				if n.TargetText != nil {
-					truncated := truncateText(*n.TargetText, 30)
-					message = fmt.Sprintf("@%s liked your post: \"%s\"", n.ActorName, truncated)
-				} else {
-					message = fmt.Sprintf("@%s liked your post", n.ActorName)
+	return watch(notifInterval, func() error {
+		var err error
+		notifCursor, err = pollAfter(notifCursor, fetchNotifications, func(notifications []models.NotificationWithDetails) error {
+			if machineReadable() {
+				if err := render(notifications); err != nil {
+					return err
 				}
-			case "retweet":
-				if n.TargetText != nil {
-					truncated := truncateText(*n.TargetText, 30)
-					message = fmt.Sprintf("@%s retweeted your post: \"%s\"", n.ActorName, truncated)
-				} else {
-					message = fmt.Sprintf("@%s retweeted your post", n.ActorName)
+			}
+
+			for _, n := range notifications {
+				if n.Notification.Type == "message" {
+					continue
 				}
-			case "follow":
-				message = fmt.Sprintf("@%s followed you", n.ActorName)
-			case "message":
-				if n.TargetText != nil {
-					truncated := truncateText(*n.TargetText, 30)
-					message = fmt.Sprintf("@%s sent you a message: \"%s\"", n.ActorName, truncated)
-				} else {
-					message = fmt.Sprintf("@%s sent you a message", n.ActorName)
+				if !machineReadable() {
+					g := models.NotificationGroup{
+						Type:       n.Notification.Type,
+						TargetID:   n.Notification.TargetID,
+						TargetText: n.TargetText,
+						Actors:     []string{n.ActorName},
+						Count:      1,
+						CreatedAt:  n.Notification.CreatedAt,
+					}
+					fmt.Printf("🔔 %s (%s)\n", display.FormatNotificationGroup(g), display.FormatTimeAgo(g.CreatedAt))
 				}
-			case "mention":
-				if n.TargetText != nil {
-					truncated := truncateText(*n.TargetText, 30)
-					message = fmt.Sprintf("@%s mentioned you in a post: \"%s\"", n.ActorName, truncated)
-				} else {
-					message = fmt.Sprintf("@%s mentioned you in a post", n.ActorName)
+				if notifBell && n.Notification.Type == "mention" {
+					ringBell()
 				}
-			default:
-				message = fmt.Sprintf("@%s performed an action", n.ActorName)
 			}
-
-			fmt.Printf("%s (%s)%s\n", message, timeAgo, unreadIndicator)
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
-		fmt.Println()
-		fmt.Printf("Showing %d notification(s)\n", len(notifications))
-
-		return nil
-	},
+		messageCursor, err = pollAfter(messageCursor, fetchMessages, func(messages []models.MessageWithUser) error {
+			if machineReadable() {
+				return render(messages)
+			}
+			for _, m := range messages {
+				fmt.Printf("✉️  From @%s (%s)\n", m.SenderName, display.FormatTimeAgo(m.Message.CreatedAt))
+				fmt.Printf("%s\n", m.Message.Text)
+			}
+			return nil
+		})
+		return err
+	})
 }
 
 var notificationsReadCmd = &cobra.Command{
 	Use:   "read",
 	Short: "Mark all notifications as read",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		notifStore := store.NewNotificationStore(DB)
+		notifStore := stores.Notifications
 		if err := notifStore.MarkAsRead(user.ID); err != nil {
 			return err
 		}
@@ -137,18 +211,12 @@ var notificationsCountCmd = &cobra.Command{
 	Use:   "count",
 	Short: "Show unread notification count",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		notifStore := store.NewNotificationStore(DB)
+		notifStore := stores.Notifications
 		count, err := notifStore.GetUnreadCount(user.ID)
 		if err != nil {
 			return err
@@ -171,43 +239,172 @@ var notificationsClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear all read notifications",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in")
+			return err
+		}
+
+		notifStore := stores.Notifications
+		if err := notifStore.DeleteAllRead(user.ID); err != nil {
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		fmt.Println("Cleared all read notifications")
+		return nil
+	},
+}
+
+var (
+	settingsEnabled       bool
+	settingsOnlyFollowing bool
+	settingsMinLikes      int
+)
+
+var notificationsSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Show which notifications you get",
+	Long: `Shows your notification settings. Each type (like, retweet, follow, message,
+mention, reply, quote, poll) can be turned off, limited to people you follow, or, for
+likes and retweets, held back until the post has enough likes. No
+notifications are created during quiet hours.
+
+  twt notifications settings set like --min-likes 5
+  twt notifications settings set mention --only-following
+  twt notifications settings set all --enabled=false
+  twt notifications settings quiet 22:00-07:00`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		notifStore := store.NewNotificationStore(DB)
-		if err := notifStore.DeleteAllRead(user.ID); err != nil {
+		settings, err := services.Notifications.Settings(user.ID)
+		if err != nil {
 			return err
 		}
 
-		fmt.Println("Cleared all read notifications")
+		if machineReadable() {
+			return render(settings)
+		}
+
+		fmt.Printf("%-9s %-8s %-17s %s\n", "TYPE", "ENABLED", "FROM", "MIN LIKES")
+		for _, rule := range settings.Rules {
+			printRule(rule)
+		}
+
+		fmt.Println()
+		if settings.QuietHours != nil {
+			fmt.Printf("Quiet hours: %s\n", settings.QuietHours)
+		} else {
+			fmt.Println("Quiet hours: off")
+		}
+
+		return nil
+	},
+}
+
+var notificationsSettingsSetCmd = &cobra.Command{
+	Use:   "set [type|all]",
+	Short: "Change which notifications of a type you get",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var change service.RuleChange
+		if cmd.Flags().Changed("enabled") {
+			change.Enabled = &settingsEnabled
+		}
+		if cmd.Flags().Changed("only-following") {
+			change.OnlyFollowing = &settingsOnlyFollowing
+		}
+		if cmd.Flags().Changed("min-likes") {
+			change.MinLikes = &settingsMinLikes
+		}
+		if change == (service.RuleChange{}) {
+			return errors.New("nothing to change: use --enabled, --only-following or --min-likes")
+		}
+
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		rules, err := services.Notifications.UpdateRule(user.ID, args[0], change)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-9s %-8s %-17s %s\n", "TYPE", "ENABLED", "FROM", "MIN LIKES")
+		for _, rule := range rules {
+			printRule(rule)
+		}
+
 		return nil
 	},
 }
 
-func truncateText(text string, maxLen int) string {
-	if len(text) <= maxLen {
-		return text
+var notificationsSettingsQuietCmd = &cobra.Command{
+	Use:   "quiet [HH:MM-HH:MM|off]",
+	Short: "Set daily quiet hours with no notifications",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		quiet, err := services.Notifications.SetQuietHours(user.ID, args[0])
+		if err != nil {
+			return err
+		}
+
+		if quiet == nil {
+			fmt.Println("Quiet hours turned off")
+		} else {
+			fmt.Printf("Quiet hours set to %s\n", quiet)
+		}
+
+		return nil
+	},
+}
+
+// printRule prints one row of the notification settings table
+func printRule(rule models.NotificationRule) {
+	enabled := "on"
+	if !rule.Enabled {
+		enabled = "off"
+	}
+
+	from := "everyone"
+	if rule.OnlyFollowing {
+		from = "people you follow"
 	}
-	return text[:maxLen-3] + "..."
+
+	minLikes := "-"
+	if rule.MinLikes > 0 {
+		minLikes = fmt.Sprint(rule.MinLikes)
+	}
+
+	fmt.Printf("%-9s %-8s %-17s %s\n", rule.Type, enabled, from, minLikes)
 }
 
 func init() {
 	// Flags
 	notificationsCmd.Flags().BoolVar(&notifUnreadOnly, "unread", false, "Show only unread notifications")
-	notificationsCmd.Flags().IntVar(&notifLimit, "limit", 20, "Number of notifications to show")
+	addPageFlags(notificationsCmd, 20)
+	notificationsCmd.Flags().BoolVarP(&notifWatch, "watch", "w", false, "Keep printing new notifications and messages as they arrive")
+	notificationsCmd.Flags().BoolVar(&notifBell, "bell", false, "Ring the terminal bell on new mentions (with --watch)")
+	notificationsCmd.Flags().DurationVar(&notifInterval, "interval", 2*time.Second, "How often to check for new notifications (with --watch)")
 
 	// Subcommands
 	notificationsCmd.AddCommand(notificationsReadCmd)
 	notificationsCmd.AddCommand(notificationsCountCmd)
 	notificationsCmd.AddCommand(notificationsClearCmd)
 
+	notificationsSettingsSetCmd.Flags().BoolVar(&settingsEnabled, "enabled", true, "Get notifications of this type")
+	notificationsSettingsSetCmd.Flags().BoolVar(&settingsOnlyFollowing, "only-following", false, "Only from people you follow")
+	notificationsSettingsSetCmd.Flags().IntVar(&settingsMinLikes, "min-likes", 0, "Only once the post has this many likes (like and retweet)")
+	notificationsSettingsCmd.AddCommand(notificationsSettingsSetCmd)
+	notificationsSettingsCmd.AddCommand(notificationsSettingsQuietCmd)
+	notificationsCmd.AddCommand(notificationsSettingsCmd)
+
 	rootCmd.AddCommand(notificationsCmd)
 }
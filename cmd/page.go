@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/output"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// addPageFlags adds --limit, --before and --after to a command that lists
+// items newest first
+func addPageFlags(cmd *cobra.Command, limit int) {
+	cmd.Flags().Int("limit", limit, "Number of items to show")
+	cmd.Flags().String("before", "", "Show items older than this cursor")
+	cmd.Flags().String("after", "", "Show items newer than this cursor")
+}
+
+// pageFlags reads the flags added by addPageFlags
+func pageFlags(cmd *cobra.Command) (store.Page, error) {
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 1 {
+		return store.Page{}, fmt.Errorf("--limit must be at least 1")
+	}
+	p := store.Page{Limit: limit}
+
+	var err error
+	if v, _ := cmd.Flags().GetString("before"); v != "" {
+		if p.Before, err = models.ParseCursor(v); err != nil {
+			return p, fmt.Errorf("--before: %w", err)
+		}
+	}
+	if v, _ := cmd.Flags().GetString("after"); v != "" {
+		if p.After, err = models.ParseCursor(v); err != nil {
+			return p, fmt.Errorf("--after: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// paging reports whether the user asked for a page other than the first
+func paging(p store.Page) bool {
+	return !p.First()
+}
+
+// renderPage writes a page of items in the --output format. json and yaml
+// documents wrap them in an output.Page with the next page's cursor; jsonl
+// and csv are just the items, so the cursor goes to stderr as with
+// printNextPage.
+func renderPage[T any](items []T, p store.Page, next *models.Cursor) error {
+	if outputFormat == output.JSON || outputFormat == output.YAML {
+		doc := output.Page{Data: items, Limit: p.Limit}
+		if next != nil {
+			token := next.String()
+			doc.NextCursor = &token
+		}
+		return render(doc)
+	}
+
+	if err := render(items); err != nil {
+		return err
+	}
+	printNextPage(p, next)
+	return nil
+}
+
+// printNextPage says how to get the page after p, if there is one. For
+// --output formats it goes to stderr as "next_cursor: <cursor>" so stdout
+// stays parseable.
+func printNextPage(p store.Page, next *models.Cursor) {
+	if next == nil {
+		return
+	}
+
+	if machineReadable() {
+		fmt.Fprintf(os.Stderr, "next_cursor: %s\n", next)
+		return
+	}
+
+	if p.After != nil && p.Before == nil {
+		fmt.Printf("\nUse --after %s to see newer items.\n", next)
+	} else {
+		fmt.Printf("\nUse --before %s to see older items.\n", next)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/display"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var voteCmd = &cobra.Command{
+	Use:   "vote [post_id] [option]",
+	Short: "Vote in a poll",
+	Long: `Votes in the poll attached to a post, choosing an option by its number or
+its text. You can vote once, while the poll is open. Voting shows you the
+results so far.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		poll, err := services.Polls.Vote(user.ID, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		if machineReadable() {
+			return render(poll)
+		}
+
+		fmt.Printf("Voted for %q\n\n", poll.Options[*poll.Voted-1].Text)
+		fmt.Println(display.FormatPoll(poll))
+		return nil
+	},
+}
+
+// pollFlags reads the --poll and --poll-duration flags into the poll to
+// attach to a post, or nil if there's none
+func pollFlags(cmd *cobra.Command) (*service.NewPoll, error) {
+	if postPoll == "" {
+		if cmd.Flags().Changed("poll-duration") {
+			return nil, errors.New("--poll-duration needs --poll")
+		}
+		return nil, nil
+	}
+
+	duration, err := parser.ParseDuration(postPollDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.NewPoll{Options: strings.Split(postPoll, ","), Duration: duration}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(voteCmd)
+}
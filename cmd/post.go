@@ -1,29 +1,77 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/display"
-	"github.com/RazinShafayet2007/twitter-cli/internal/media"
-	"github.com/RazinShafayet2007/twitter-cli/internal/models"
 	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
-	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
 	"github.com/spf13/cobra"
 )
 
 var (
 	postImages []string
+	postThread string
+	postAt     string
+
+	postPoll         string
+	postPollDuration string
 )
 
 var postCmd = &cobra.Command{
 	Use:   "post [text]",
 	Short: "Create a new post",
-	Args:  cobra.ExactArgs(1),
+	Long: `Creates a post. Pass - as the text to read it from stdin.
+
+With --thread, reads a thread from a file (or stdin for -) instead: put a
+line of just --- between posts, and anything over 280 characters is split
+between words too. The posts are numbered ("1/5") and published as a chain
+of replies, all at once.
+
+With --at, saves the post to be published later by twt scheduler run.
+
+With --poll, attaches a poll of 2 to 4 comma-separated options, open for a
+day or --poll-duration. Results are shown to those who have voted (twt vote)
+until it closes, and you're notified when it does.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if postThread != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return publishPost(args[0], postImages, nil)
+		if postThread != "" && postAt != "" {
+			return errors.New("--at can't be used with --thread")
+		}
+
+		poll, err := pollFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if poll != nil && (postThread != "" || postAt != "") {
+			return errors.New("--poll can't be used with --thread or --at")
+		}
+
+		if postThread != "" {
+			text, err := readText(postThread)
+			if err != nil {
+				return err
+			}
+			return publishThread(text, postImages)
+		}
+
+		text := args[0]
+		if text == "-" {
+			if text, err = readText("-"); err != nil {
+				return err
+			}
+		}
+		if postAt != "" {
+			return schedulePost(text, postImages, postAt)
+		}
+		return publishPost(service.NewPost{Text: text, Images: postImages, Poll: poll})
 	},
 }
 
@@ -34,156 +82,60 @@ var replyCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		parentID := args[0]
 		text := args[1]
-		return publishPost(text, postImages, &parentID)
+		return publishPost(service.NewPost{Text: text, ParentID: &parentID, Images: postImages})
 	},
 }
 
-func publishPost(rawText string, images []string, parentPostID *string) error {
-	text := validation.SanitizePostText(rawText)
-
-	if err := validation.ValidatePostText(text); err != nil {
-		return err
-	}
-
-	// Validate images
-	if len(images) > media.MaxImagesPerPost {
-		return fmt.Errorf("too many images (max %d)", media.MaxImagesPerPost)
-	}
-
-	for _, imgPath := range images {
-		if err := media.ValidateImage(imgPath); err != nil {
-			return fmt.Errorf("invalid image %s: %w", imgPath, err)
-		}
-	}
-
-	username, err := config.GetCurrentUser()
-	if err != nil {
-		return fmt.Errorf("not logged in. Run: twt login <username>")
-	}
+var quoteCmd = &cobra.Command{
+	Use:   "quote [post_id] [text]",
+	Short: "Quote a post with your own text",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quotedID := args[0]
+		text := args[1]
+		return publishPost(service.NewPost{Text: text, QuotedID: &quotedID, Images: postImages})
+	},
+}
 
-	userStore := store.NewUserStore(DB)
-	user, err := userStore.GetByUsername(username)
+func publishPost(post service.NewPost) error {
+	user, err := loggedInUser()
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Create post
-	postStore := store.NewPostStore(DB)
-	var post *models.Post
-
-	if parentPostID != nil {
-		post, err = postStore.CreateReply(user.ID, text, *parentPostID)
-	} else {
-		post, err = postStore.Create(user.ID, text)
+		return err
 	}
 
+	published, err := services.Posts.Publish(user, post)
 	if err != nil {
 		return err
 	}
 
-	// Process images
-	if len(images) > 0 {
-		mediaStore := store.NewMediaStore(DB)
-
-		for i, imgPath := range images {
-			// Copy image to media directory
-			destPath, fileName, err := media.CopyImageToMedia(imgPath, post.ID, i)
-			if err != nil {
-				fmt.Printf("Warning: failed to copy image %s: %v\n", imgPath, err)
-				continue
-			}
-
-			// Get image info
-			width, height, _ := media.GetImageDimensions(imgPath)
-			fileType, _ := media.GetFileType(imgPath)
-			fileInfo, _ := os.Stat(imgPath)
-
-			// Create media record
-			m := &models.Media{
-				PostID:   post.ID,
-				FilePath: destPath,
-				FileName: fileName,
-				FileType: fileType,
-				FileSize: fileInfo.Size(),
-				Width:    &width,
-				Height:   &height,
-				Position: i,
-			}
-
-			if err := mediaStore.Create(m); err != nil {
-				fmt.Printf("Warning: failed to save media record: %v\n", err)
-			}
-		}
-	}
-
-	// Extract and save hashtags
-	hashtags := parser.ExtractHashtags(text)
-	if len(hashtags) > 0 {
-		hashtagStore := store.NewHashtagStore(DB)
-		if err := hashtagStore.LinkPostToHashtags(post.ID, hashtags); err != nil {
-			fmt.Printf("Warning: failed to save hashtags: %v\n", err)
-		}
-	}
-
-	// Extract and save mentions
-	mentionUsernames := parser.ExtractMentions(text)
-	processedUserIDs := make(map[string]bool)
-
-	if len(mentionUsernames) > 0 {
-		mentionStore := store.NewMentionStore(DB)
-
-		mentionedUserIDs, err := mentionStore.GetMentionedUsers(mentionUsernames)
-		if err != nil {
-			fmt.Printf("Warning: failed to process mentions: %v\n", err)
-		} else {
-			if err := mentionStore.CreateMentions(post.ID, mentionedUserIDs); err != nil {
-				fmt.Printf("Warning: failed to save mentions: %v\n", err)
-			}
-
-			// Create notifications for mentions
-			notifStore := store.NewNotificationStore(DB)
-			for _, mentionedUserID := range mentionedUserIDs {
-				if mentionedUserID != user.ID {
-					postID := post.ID
-					if err := notifStore.Create(mentionedUserID, user.ID, "mention", &postID); err != nil {
-						fmt.Printf("Warning: failed to create mention notification: %v\n", err)
-					}
-					processedUserIDs[mentionedUserID] = true
-				}
-			}
-		}
+	// Show summary
+	// Retweets resolve to their originals, so show what was replied to or
+	// quoted rather than the ID given
+	if published.Post.ParentPostID != nil {
+		fmt.Printf("Replying to %s\n", *published.Post.ParentPostID)
 	}
-
-	// Notify parent author if this is a reply (and they haven't been mentioned already to avoid duplicate notifs)
-	if parentPostID != nil {
-		parentPost, err := postStore.GetByID(*parentPostID)
-		if err == nil && parentPost.AuthorID != user.ID {
-			if !processedUserIDs[parentPost.AuthorID] {
-				notifStore := store.NewNotificationStore(DB)
-				postID := post.ID
-				if err := notifStore.Create(parentPost.AuthorID, user.ID, "reply", &postID); err != nil {
-					fmt.Printf("Warning: failed to create reply notification: %v\n", err)
-				}
-			}
-		}
+	if published.Post.QuotedPostID != nil {
+		fmt.Printf("Quoting %s\n", *published.Post.QuotedPostID)
 	}
+	showPublished(published)
+	return nil
+}
 
-	// Show summary
-	if parentPostID != nil {
-		fmt.Printf("Replying to %s\n", *parentPostID)
+// showPublished summarizes a published post
+func showPublished(published *service.PublishedPost) {
+	fmt.Printf("Posted: %s\n", published.Post.ID)
+	if len(published.Hashtags) > 0 {
+		fmt.Printf("Hashtags: %v\n", published.Hashtags)
 	}
-	fmt.Printf("Posted: %s\n", post.ID)
-	if len(hashtags) > 0 {
-		fmt.Printf("Hashtags: %v\n", hashtags)
+	if len(published.Mentions) > 0 {
+		fmt.Printf("Mentions: %v\n", published.Mentions)
 	}
-	if len(mentionUsernames) > 0 {
-		fmt.Printf("Mentions: %v\n", mentionUsernames)
+	if len(published.Media) > 0 {
+		fmt.Printf("📷 %d image(s) attached\n", len(published.Media))
 	}
-	if len(images) > 0 {
-		fmt.Printf("📷 %d image(s) attached\n", len(images))
+	if published.Poll != nil {
+		fmt.Printf("📊 Poll open until %s\n", formatPublishAt(published.Poll.ClosesAt))
 	}
-
-	return nil
 }
 
 var profileCmd = &cobra.Command{
@@ -192,29 +144,37 @@ var profileCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
 
 		// Check if user exists
-		userStore := store.NewUserStore(DB)
-		_, err := userStore.GetByUsername(username)
+		userStore := stores.Users
+		_, err = userStore.GetByUsername(username)
 		if err != nil {
 			return fmt.Errorf("user @%s not found", username)
 		}
 
 		// Get posts
-		postStore := store.NewPostStore(DB)
-		posts, err := postStore.GetByUsername(username, 50) // Limit to 50 posts
+		postStore := stores.Posts
+		posts, err := postStore.GetByUsername(username, page.Probe())
 		if err != nil {
 			return err
 		}
+		posts, next := store.Paginate(posts, page)
+
+		if machineReadable() {
+			return renderPage(posts, page, next)
+		}
 
 		// Display posts
-		mediaStore := store.NewMediaStore(DB)
 		for _, pwa := range posts {
-			mediaCount, _ := mediaStore.GetMediaCount(pwa.Post.ID)
-			fmt.Println(display.FormatPostWithMedia(pwa, mediaCount))
+			fmt.Println(display.FormatPostWithMedia(pwa, pwa.Post.MediaCount))
 			fmt.Println()
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -226,40 +186,19 @@ var deletePostCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
-		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
-		}
-
-		// Get media before deleting post
-		mediaStore := store.NewMediaStore(DB)
-		mediaList, err := mediaStore.GetByPostID(postID)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Delete post
-		postStore := store.NewPostStore(DB)
-		if err := postStore.Delete(postID, user.ID); err != nil {
+		imageCount, err := services.Posts.Delete(user.ID, postID)
+		if err != nil {
 			return err
 		}
 
-		// Delete media files from disk
-		for _, m := range mediaList {
-			if err := media.DeleteMediaFile(m.FilePath); err != nil {
-				fmt.Printf("Warning: failed to delete media file: %v\n", err)
-			}
-		}
-
 		fmt.Println("Post deleted")
-		if len(mediaList) > 0 {
-			fmt.Printf("Deleted %d image(s)\n", len(mediaList))
+		if imageCount > 0 {
+			fmt.Printf("Deleted %d image(s)\n", imageCount)
 		}
 
 		return nil
@@ -273,58 +212,27 @@ var showCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		postStore := store.NewPostStore(DB)
-		userStore := store.NewUserStore(DB)
-		socialStore := store.NewSocialStore(DB)
-		mediaStore := store.NewMediaStore(DB)
-
-		// Get post
-		post, err := postStore.GetByID(postID)
-		if err != nil {
-			return err
-		}
-
-		// Get author
-		user, err := userStore.GetByID(post.AuthorID)
-		if err != nil {
-			return err
-		}
-
-		// Get engagement stats
-		likeCount, err := socialStore.GetLikeCount(postID)
+		details, err := services.Posts.Details(postID, viewerID())
 		if err != nil {
 			return err
 		}
 
-		retweetCount, err := postStore.GetRetweetCount(postID)
-		if err != nil {
-			return err
-		}
-
-		// Get media
-		mediaList, err := mediaStore.GetByPostID(postID)
-		if err != nil {
-			return err
-		}
-
-		// Create PostWithAuthor
-		pwa := store.PostWithAuthor{
-			Post:     *post,
-			Username: user.Username,
+		if machineReadable() {
+			return render(details)
 		}
 
 		// Display with stats
-		fmt.Println(display.FormatPostWithStats(pwa, likeCount, retweetCount))
+		fmt.Println(display.FormatPostWithStats(details.PostWithAuthor, details.LikeCount, details.RetweetCount))
 
 		// Show parent info if it's a reply
-		if post.ParentPostID != nil {
-			fmt.Printf("Replied to: %s\n", *post.ParentPostID)
+		if details.Post.ParentPostID != nil {
+			fmt.Printf("Replied to: %s\n", *details.Post.ParentPostID)
 		}
 
 		// Show media info
-		if len(mediaList) > 0 {
-			fmt.Printf("\n📷 %d image(s) attached:\n", len(mediaList))
-			for i, m := range mediaList {
+		if len(details.Media) > 0 {
+			fmt.Printf("\n📷 %d image(s) attached:\n", len(details.Media))
+			for i, m := range details.Media {
 				sizeKB := m.FileSize / 1024
 				fmt.Printf("  %d. %s (%d KB", i+1, m.FileName, sizeKB)
 				if m.Width != nil && m.Height != nil {
@@ -335,6 +243,15 @@ var showCmd = &cobra.Command{
 			fmt.Printf("\nDownload: twt image download %s\n", postID)
 		}
 
+		// Show quotes
+		if len(details.Quotes) > 0 {
+			fmt.Printf("\nQuotes (%d):\n\n", details.Post.QuoteCount)
+			for _, q := range details.Quotes {
+				fmt.Println(display.FormatPostWithMedia(q, q.Post.MediaCount))
+				fmt.Println()
+			}
+		}
+
 		return nil
 	},
 }
@@ -346,111 +263,207 @@ var retweetCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
+		retweet, err := services.Posts.Retweet(user.ID, postID)
 		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
-		// Get original post to find author
-		postStore := store.NewPostStore(DB)
-		originalPost, err := postStore.GetByID(postID)
+		fmt.Printf("Retweeted: %s\n", retweet.ID)
+		return nil
+	},
+}
+
+var unretweetCmd = &cobra.Command{
+	Use:   "unretweet [post_id]",
+	Short: "Undo your retweet of a post",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postID := args[0]
+
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Create retweet
-		retweet, err := postStore.Retweet(user.ID, postID)
+		if err := services.Posts.Unretweet(user.ID, postID); err != nil {
+			return err
+		}
+
+		fmt.Println("Unretweeted")
+		return nil
+	},
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit [post_id] [text]",
+	Short: "Edit one of your posts",
+	Long: `Replaces the text of one of your posts, within an hour of posting it (see
+edit_window in twt config). Pass - as the text to read it from stdin. The
+post is marked (edited), and earlier versions are kept: see twt history.
+Only users the new text newly mentions are notified.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Create notification for original author
-		notifStore := store.NewNotificationStore(DB)
-		if err := notifStore.Create(originalPost.AuthorID, user.ID, "retweet", &postID); err != nil {
-			fmt.Printf("Warning: failed to create notification: %v\n", err)
+		text := args[1]
+		if text == "-" {
+			if text, err = readText("-"); err != nil {
+				return err
+			}
 		}
 
-		fmt.Printf("Retweeted: %s\n", retweet.ID)
+		edited, err := services.Posts.Edit(user, args[0], text)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Edited: %s\n", edited.Post.ID)
+		if len(edited.Hashtags) > 0 {
+			fmt.Printf("Hashtags: %v\n", edited.Hashtags)
+		}
+		if len(edited.Mentions) > 0 {
+			fmt.Printf("Mentions: %v\n", edited.Mentions)
+		}
+		return nil
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history [post_id]",
+	Short: "Show the edit history of a post",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		versions, err := services.Posts.History(args[0])
+		if err != nil {
+			return err
+		}
+
+		if machineReadable() {
+			return render(versions)
+		}
+
+		if len(versions) == 1 {
+			fmt.Println("This post hasn't been edited.")
+			fmt.Println()
+		}
+		fmt.Println(display.FormatHistory(versions))
 		return nil
 	},
 }
 
+var (
+	searchLimit  int
+	searchOffset int
+)
+
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search posts by text",
-	Args:  cobra.ExactArgs(1),
+	Long: `Full-text search over posts, best matches first. Words match their other
+forms ("running" finds "run"), "quoted words" match as a phrase and a
+trailing * matches any word with that prefix (gola*).
+
+Operators narrow the results:
+
+  from:alice          posts by alice
+  to:bob              replies to bob's posts
+  #golang @carol      posts with a hashtag or mentioning a user
+  since:2026-01-01    posted on or after a date
+  until:2026-02-01    posted before a date
+  has:image           posts with images
+  is:reply            replies (or is:retweet for retweets)
+  min_likes:5         posts with at least 5 likes
+
+Put - in front of any term to exclude it, as in -rust or -from:bob.
+
+Results are ranked rather than ordered by time, so unlike other lists they
+are paged with --limit and --offset instead of --before and --after.
+
+Example: twt search 'golang -is:reply from:alice since:2026-01-01'`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := args[0]
 
-		postStore := store.NewPostStore(DB)
-		posts, err := postStore.Search(query, 50)
+		postStore := stores.Posts
+		results, err := postStore.Search(query, viewerID(), searchLimit, searchOffset)
+		var syntaxErr *parser.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("%w\n\n%s", err, syntaxErr.Pointer())
+		}
 		if err != nil {
 			return err
 		}
 
-		if len(posts) == 0 {
-			fmt.Printf("No posts found matching '%s'\n", query)
+		if machineReadable() {
+			return render(results)
+		}
+
+		if len(results) == 0 {
+			if searchOffset > 0 {
+				fmt.Println("No more results.")
+			} else {
+				fmt.Printf("No posts found matching '%s'\n", query)
+			}
 			return nil
 		}
 
-		fmt.Printf("Found %d post(s) matching '%s':\n\n", len(posts), query)
-		output := display.FormatPosts(posts)
-		fmt.Println(output)
+		fmt.Printf("Found %d post(s) matching '%s':\n\n", len(results), query)
+		for _, r := range results {
+			fmt.Println(display.FormatSearchResult(r))
+			fmt.Println()
+		}
+
+		if len(results) == searchLimit {
+			fmt.Printf("Showing %d results. Use --offset %d to see more.\n", searchLimit, searchOffset+searchLimit)
+		}
 
 		return nil
 	},
 }
 
+var (
+	threadDepth   int
+	threadSort    string
+	threadReplies int
+)
+
 var threadCmd = &cobra.Command{
 	Use:   "thread [post_id]",
 	Short: "View conversation thread",
-	Args:  cobra.ExactArgs(1),
+	Long: `Shows the posts a post replies to, then the post with the whole tree of
+replies under it. --depth limits how many levels of replies are read, and
+--replies how many are shown under each post; the rest are summed up.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		postStore := store.NewPostStore(DB)
-		mediaStore := store.NewMediaStore(DB)
+		if threadSort != store.ThreadSortTop && threadSort != store.ThreadSortNew {
+			return fmt.Errorf("unknown --sort %q: use top or new", threadSort)
+		}
 
-		thread, err := postStore.GetThread(postID)
+		thread, err := stores.Posts.GetThread(postID, viewerID(), store.ThreadOptions{
+			Depth: threadDepth,
+			Sort:  threadSort,
+		})
 		if err != nil {
 			return err
 		}
 
-		if len(thread) == 0 {
-			fmt.Println("No posts found.")
-			return nil
+		if machineReadable() {
+			return render(thread)
 		}
 
 		fmt.Println("Conversation Thread:")
 		fmt.Println("====================")
-
-		for _, pwa := range thread {
-			// Visual indicator for layout
-			prefix := ""
-			if pwa.Post.ID == postID {
-				prefix = "👉 " // Highlight requested post
-			} else if pwa.Post.ParentPostID != nil && *pwa.Post.ParentPostID == postID {
-				prefix = "  ↳ " // Indent direct children
-			} else if pwa.Post.ParentPostID != nil {
-				// Likely an ancestor or sibling
-				// Simple connection line
-				prefix = "| "
-			}
-
-			// Format post
-			mediaCount, _ := mediaStore.GetMediaCount(pwa.Post.ID)
-			content := display.FormatPostWithMedia(pwa, mediaCount)
-
-			// Print with prefix
-			fmt.Printf("%s%s\n", prefix, content)
-			fmt.Println()
-		}
+		fmt.Println(display.FormatThread(thread, threadReplies))
 
 		return nil
 	},
@@ -459,14 +472,29 @@ var threadCmd = &cobra.Command{
 func init() {
 	// Add image flag
 	postCmd.Flags().StringArrayVar(&postImages, "image", []string{}, "Attach image(s) to post (can be used multiple times)")
+	postCmd.Flags().StringVar(&postThread, "thread", "", "Publish a thread written in a file (- for stdin)")
+	postCmd.Flags().StringVar(&postAt, "at", "", `Schedule the post (e.g. "2026-11-01 09:00", 09:00 or 2h)`)
+	postCmd.Flags().StringVar(&postPoll, "poll", "", `Attach a poll (e.g. "SQLite,Postgres,DuckDB")`)
+	postCmd.Flags().StringVar(&postPollDuration, "poll-duration", "24h", "How long the poll stays open (5m to 7d)")
 	replyCmd.Flags().StringArrayVar(&postImages, "image", []string{}, "Attach image(s) to reply")
+	quoteCmd.Flags().StringArrayVar(&postImages, "image", []string{}, "Attach image(s) to quote")
+	addPageFlags(profileCmd, 50)
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Number of results to show")
+	searchCmd.Flags().IntVar(&searchOffset, "offset", 0, "Number of results to skip")
+	threadCmd.Flags().IntVar(&threadDepth, "depth", 0, "Levels of replies to show (0 for all)")
+	threadCmd.Flags().StringVar(&threadSort, "sort", store.ThreadSortTop, "Reply order: top or new")
+	threadCmd.Flags().IntVar(&threadReplies, "replies", 5, "Replies to show under each post before collapsing the rest (0 for all)")
 
 	rootCmd.AddCommand(postCmd)
 	rootCmd.AddCommand(replyCmd)
+	rootCmd.AddCommand(quoteCmd)
 	rootCmd.AddCommand(profileCmd)
 	rootCmd.AddCommand(deletePostCmd)
 	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(retweetCmd)
+	rootCmd.AddCommand(unretweetCmd)
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(threadCmd)
 }
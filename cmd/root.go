@@ -2,15 +2,30 @@ package cmd
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"os"
 
 	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/output"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	dbPath string
 	DB     *sql.DB
+
+	// stores and services are wired to DB before each command runs
+	stores   *store.Stores
+	services *service.Services
+)
+
+var (
+	outputFlag   string
+	outputFormat = output.Text
 )
 
 var (
@@ -29,12 +44,26 @@ var rootCmd = &cobra.Command{
 	Long:    `Twitter CLI - A command-line Twitter clone for learning system design`,
 	Version: version,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Initialize database for all commands
+		// Validate output format before touching the database
 		var err error
+		outputFormat, err = output.ParseFormat(outputFlag)
+		if err != nil {
+			return err
+		}
+
+		// Initialize database for all commands
 		DB, err = db.InitDB(dbPath)
 		if err != nil {
 			return fmt.Errorf("failed to initialize database: %w", err)
 		}
+
+		opts, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		stores = store.NewStores(DB)
+		services = service.New(stores, warningLogger(), opts)
 		return nil
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
@@ -55,6 +84,30 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+// explainUserNotFound names the missing user in store.ErrUserNotFound
+func explainUserNotFound(err error, username string) error {
+	if errors.Is(err, store.ErrUserNotFound) {
+		return fmt.Errorf("user @%s not found", username)
+	}
+	return err
+}
+
+// warningLogger is where services report best-effort side effects that
+// failed. It writes to stderr, keeping stdout for --output documents.
+func warningLogger() *log.Logger {
+	return log.New(os.Stderr, "Warning: ", 0)
+}
+
+// machineReadable reports whether --output asks for structured output
+func machineReadable() bool {
+	return outputFormat != output.Text
+}
+
+// render writes v to stdout in the --output format
+func render(v interface{}) error {
+	return output.Render(os.Stdout, outputFormat, v)
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
@@ -66,4 +119,5 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db", defaultPath, "database file path")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(output.Text), "output format: text, json, jsonl, csv, yaml")
 }
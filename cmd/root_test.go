@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/output"
+)
+
+func TestRender_WarningsKeepJSONParseable(t *testing.T) {
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("failed to create stdout: %v", err)
+	}
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatalf("failed to create stderr: %v", err)
+	}
+
+	oldStdout, oldStderr, oldFormat := os.Stdout, os.Stderr, outputFormat
+	os.Stdout, os.Stderr, outputFormat = stdout, stderr, output.JSON
+	t.Cleanup(func() { os.Stdout, os.Stderr, outputFormat = oldStdout, oldStderr, oldFormat })
+
+	// A warning logged while a command renders its result
+	warningLogger().Printf("failed to create notification: %v", "database is locked")
+	if err := render([]map[string]string{{"id": "1"}}); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	stdout.Close()
+	stderr.Close()
+
+	data, _ := os.ReadFile(stdout.Name())
+	var v []map[string]string
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Errorf("expected stdout to be valid JSON, got %q: %v", data, err)
+	}
+
+	warnings, _ := os.ReadFile(stderr.Name())
+	if !strings.Contains(string(warnings), "Warning: failed to create notification") {
+		t.Errorf("expected the warning on stderr, got %q", warnings)
+	}
+}
+
+func TestCheckLiveOutput(t *testing.T) {
+	oldFormat := outputFormat
+	t.Cleanup(func() { outputFormat = oldFormat })
+
+	for format, ok := range map[output.Format]bool{
+		output.Text:  true,
+		output.JSONL: true,
+		output.JSON:  false,
+		output.YAML:  false,
+		output.CSV:   false,
+	} {
+		outputFormat = format
+		if err := checkLiveOutput("--follow"); (err == nil) != ok {
+			t.Errorf("--output %s: expected ok=%v, got %v", format, ok, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/server"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local REST API server",
+	Long: `Serves the database as a JSON HTTP API under /api/v1 and uploaded images under /media.
+Log in with POST /api/v1/sessions and send the returned token as
+"Authorization: Bearer <token>".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		opts, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		// Server warnings go to the request log rather than stdout
+		api := server.New(stores, service.New(stores, log.Default(), opts))
+
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           api.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		// Shut down cleanly on Ctrl-C so the database is closed properly
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- srv.ListenAndServe()
+		}()
+
+		fmt.Printf("Serving API on http://%s/api/v1 (Ctrl-C to stop)\n", addr)
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to shut down server: %w", err)
+		}
+
+		fmt.Println("\nServer stopped")
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+
+	rootCmd.AddCommand(serveCmd)
+}
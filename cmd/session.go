@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/config"
+	clierrors "github.com/RazinShafayet2007/twitter-cli/internal/errors"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
+	"golang.org/x/term"
+)
+
+// loggedInUser validates the saved session and returns its user
+func loggedInUser() (*models.User, error) {
+	token, err := config.GetSessionToken()
+	if err != nil {
+		return nil, clierrors.NotLoggedInError()
+	}
+
+	user, err := services.Users.SessionUser(token)
+	if errors.Is(err, store.ErrSessionNotFound) || errors.Is(err, store.ErrSessionExpired) {
+		// Forget the dead token so the next command says "not logged in"
+		_ = config.ClearSessionToken()
+		return nil, clierrors.SessionExpiredError()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// viewerID returns the logged-in user's ID, or "" if nobody is logged in.
+// Commands that work without logging in use it to hide what the user has
+// blocked or muted.
+func viewerID() string {
+	user, err := loggedInUser()
+	if err != nil {
+		return ""
+	}
+	return user.ID
+}
+
+// stdinReader is shared so piped passwords can be read line by line
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// readPassword prompts for a password without echoing it. When stdin isn't
+// a terminal it reads a line instead, so scripts can pipe passwords in.
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := stdinReader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return string(password), nil
+}
+
+// readNewPassword prompts for a new password, asking twice on a terminal
+func readNewPassword(prompt string) (string, error) {
+	password, err := readPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validation.ValidatePassword(password); err != nil {
+		return "", err
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		confirm, err := readPassword("Confirm password: ")
+		if err != nil {
+			return "", err
+		}
+		if confirm != password {
+			return "", errors.New("passwords do not match")
+		}
+	}
+
+	return password, nil
+}
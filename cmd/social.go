@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/RazinShafayet2007/twitter-cli/internal/config"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -15,34 +14,13 @@ var followCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		targetUsername := args[0]
 
-		currentUsername, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		userStore := store.NewUserStore(DB)
-
-		currentUser, err := userStore.GetByUsername(currentUsername)
-		if err != nil {
-			return fmt.Errorf("failed to get current user: %w", err)
-		}
-
-		targetUser, err := userStore.GetByUsername(targetUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", targetUsername)
-		}
-
-		// Follow
-		socialStore := store.NewSocialStore(DB)
-		err = socialStore.Follow(currentUser.ID, targetUser.ID)
+		currentUser, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Create notification
-		notifStore := store.NewNotificationStore(DB)
-		if err := notifStore.Create(targetUser.ID, currentUser.ID, "follow", nil); err != nil {
-			fmt.Printf("Warning: failed to create notification: %v\n", err)
+		if _, err := services.Social.Follow(currentUser.ID, targetUsername); err != nil {
+			return explainUserNotFound(err, targetUsername)
 		}
 
 		fmt.Printf("Now following @%s\n", targetUsername)
@@ -58,30 +36,13 @@ var unfollowCmd = &cobra.Command{
 		targetUsername := args[0]
 
 		// Check if logged in
-		currentUsername, err := config.GetCurrentUser()
+		currentUser, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
+			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-
-		// Get current user ID
-		currentUser, err := userStore.GetByUsername(currentUsername)
-		if err != nil {
-			return fmt.Errorf("failed to get current user: %w", err)
-		}
-
-		// Get target user ID
-		targetUser, err := userStore.GetByUsername(targetUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", targetUsername)
-		}
-
-		// Unfollow
-		socialStore := store.NewSocialStore(DB)
-		err = socialStore.Unfollow(currentUser.ID, targetUser.ID)
-		if err != nil {
-			return err
+		if _, err := services.Social.Unfollow(currentUser.ID, targetUsername); err != nil {
+			return explainUserNotFound(err, targetUsername)
 		}
 
 		fmt.Printf("Unfollowed @%s\n", targetUsername)
@@ -98,22 +59,36 @@ var followingCmd = &cobra.Command{
 
 		if len(args) == 0 {
 			// Show current user's following
-			username, err := config.GetCurrentUser()
+			user, err := loggedInUser()
 			if err != nil {
-				return fmt.Errorf("not logged in. Run: twt login <username>")
+				return err
 			}
-			targetUsername = username
+			targetUsername = user.Username
 		} else {
 			// Show specified user's following
 			targetUsername = args[0]
 		}
 
-		socialStore := store.NewSocialStore(DB)
-		users, err := socialStore.GetFollowingByUsername(targetUsername)
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		socialStore := stores.Social
+		users, err := socialStore.GetFollowingByUsername(targetUsername, page.Probe())
 		if err != nil {
 			return err
 		}
+		users, next := store.Paginate(users, page)
 
+		if machineReadable() {
+			return renderPage(users, page, next)
+		}
+
+		if len(users) == 0 && paging(page) {
+			fmt.Println("No more users.")
+			return nil
+		}
 		if len(users) == 0 {
 			fmt.Printf("@%s is not following anyone\n", targetUsername)
 			return nil
@@ -124,6 +99,7 @@ var followingCmd = &cobra.Command{
 			fmt.Printf("  @%s\n", user.Username)
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -137,22 +113,36 @@ var followersCmd = &cobra.Command{
 
 		if len(args) == 0 {
 			// Show current user's followers
-			username, err := config.GetCurrentUser()
+			user, err := loggedInUser()
 			if err != nil {
-				return fmt.Errorf("not logged in. Run: twt login <username>")
+				return err
 			}
-			targetUsername = username
+			targetUsername = user.Username
 		} else {
 			// Show specified user's followers
 			targetUsername = args[0]
 		}
 
-		socialStore := store.NewSocialStore(DB)
-		users, err := socialStore.GetFollowersByUsername(targetUsername)
+		page, err := pageFlags(cmd)
 		if err != nil {
 			return err
 		}
 
+		socialStore := stores.Social
+		users, err := socialStore.GetFollowersByUsername(targetUsername, page.Probe())
+		if err != nil {
+			return err
+		}
+		users, next := store.Paginate(users, page)
+
+		if machineReadable() {
+			return renderPage(users, page, next)
+		}
+
+		if len(users) == 0 && paging(page) {
+			fmt.Println("No more users.")
+			return nil
+		}
 		if len(users) == 0 {
 			fmt.Printf("@%s has no followers\n", targetUsername)
 			return nil
@@ -163,6 +153,7 @@ var followersCmd = &cobra.Command{
 			fmt.Printf("  @%s\n", user.Username)
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -174,37 +165,15 @@ var likeCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		username, err := config.GetCurrentUser()
-		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
-		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
-		}
-
-		// Get post to find author
-		postStore := store.NewPostStore(DB)
-		post, err := postStore.GetByID(postID)
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		// Like the post
-		socialStore := store.NewSocialStore(DB)
-		if err := socialStore.Like(user.ID, postID); err != nil {
+		if err := services.Social.Like(user.ID, postID); err != nil {
 			return err
 		}
 
-		// Create notification for post author
-		notifStore := store.NewNotificationStore(DB)
-		if err := notifStore.Create(post.AuthorID, user.ID, "like", &postID); err != nil {
-			// Don't fail if notification fails
-			fmt.Printf("Warning: failed to create notification: %v\n", err)
-		}
-
 		fmt.Println("Liked")
 		return nil
 	},
@@ -217,22 +186,12 @@ var unlikeCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
 
-		// Check if logged in
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
-			return fmt.Errorf("not logged in. Run: twt login <username>")
-		}
-
-		// Get user ID
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
-		if err != nil {
-			return fmt.Errorf("failed to get user: %w", err)
+			return err
 		}
 
-		// Unlike the post
-		socialStore := store.NewSocialStore(DB)
-		if err := socialStore.Unlike(user.ID, postID); err != nil {
+		if err := services.Social.Unlike(user.ID, postID); err != nil {
 			return err
 		}
 
@@ -247,23 +206,43 @@ var likesCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		postID := args[0]
+		page, err := pageFlags(cmd)
+		if err != nil {
+			return err
+		}
 
-		socialStore := store.NewSocialStore(DB)
-		users, err := socialStore.GetLikes(postID)
+		socialStore := stores.Social
+		users, err := socialStore.GetLikes(postID, page.Probe())
 		if err != nil {
 			return err
 		}
+		users, next := store.Paginate(users, page)
+
+		if machineReadable() {
+			return renderPage(users, page, next)
+		}
 
 		if len(users) == 0 {
-			fmt.Println("No likes yet")
+			if paging(page) {
+				fmt.Println("No more likes")
+			} else {
+				fmt.Println("No likes yet")
+			}
 			return nil
 		}
 
-		fmt.Printf("Liked by %d user(s):\n", len(users))
+		// The page may hold only some of them
+		total, err := socialStore.GetLikeCount(postID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Liked by %d user(s):\n", total)
 		for _, user := range users {
 			fmt.Printf("  @%s\n", user.Username)
 		}
 
+		printNextPage(page, next)
 		return nil
 	},
 }
@@ -277,65 +256,42 @@ var statsCmd = &cobra.Command{
 
 		if len(args) == 0 {
 			// Show current user's stats
-			username, err := config.GetCurrentUser()
+			user, err := loggedInUser()
 			if err != nil {
-				return fmt.Errorf("not logged in. Run: twt login <username>")
+				return err
 			}
-			targetUsername = username
+			targetUsername = user.Username
 		} else {
 			targetUsername = args[0]
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(targetUsername)
-		if err != nil {
-			return fmt.Errorf("user @%s not found", targetUsername)
-		}
-
-		// Get post count
-		postStore := store.NewPostStore(DB)
-		posts, err := postStore.GetByUsername(targetUsername, 10000)
-		if err != nil {
-			return err
-		}
-
-		// Get follow counts
-		socialStore := store.NewSocialStore(DB)
-		following, followers, err := socialStore.GetFollowCounts(user.ID)
+		stats, err := services.Users.Stats(targetUsername)
 		if err != nil {
-			return err
+			return explainUserNotFound(err, targetUsername)
 		}
 
-		// Get message stats
-		sentQuery := `SELECT COUNT(*) FROM messages WHERE sender_id = ?`
-		receivedQuery := `SELECT COUNT(*) FROM messages WHERE receiver_id = ?`
-
-		var sentCount, receivedCount int
-
-		err = DB.QueryRow(sentQuery, user.ID).Scan(&sentCount)
-		if err != nil {
-			return fmt.Errorf("failed to get sent message count: %w", err)
-		}
-
-		err = DB.QueryRow(receivedQuery, user.ID).Scan(&receivedCount)
-		if err != nil {
-			return fmt.Errorf("failed to get received message count: %w", err)
+		if machineReadable() {
+			return render(stats)
 		}
 
 		// Display stats
-		fmt.Printf("@%s\n", targetUsername)
+		fmt.Printf("@%s\n", stats.Username)
 		fmt.Println("─────────────────────────")
-		fmt.Printf("Posts:             %d\n", len(posts))
-		fmt.Printf("Following:         %d\n", following)
-		fmt.Printf("Followers:         %d\n", followers)
-		fmt.Printf("Messages sent:     %d\n", sentCount)
-		fmt.Printf("Messages received: %d\n", receivedCount)
+		fmt.Printf("Posts:             %d\n", stats.Posts)
+		fmt.Printf("Following:         %d\n", stats.Following)
+		fmt.Printf("Followers:         %d\n", stats.Followers)
+		fmt.Printf("Messages sent:     %d\n", stats.MessagesSent)
+		fmt.Printf("Messages received: %d\n", stats.MessagesReceived)
 
 		return nil
 	},
 }
 
 func init() {
+	addPageFlags(followingCmd, 50)
+	addPageFlags(followersCmd, 50)
+	addPageFlags(likesCmd, 50)
+
 	rootCmd.AddCommand(followCmd)
 	rootCmd.AddCommand(unfollowCmd)
 	rootCmd.AddCommand(followingCmd)
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/RazinShafayet2007/twitter-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the full-screen terminal client",
+	Long: `Opens a keyboard-driven client with your feed, notifications, messages and
+threads in tabs. Like, retweet, reply, post and message without leaving it.
+
+Keys: tab or 1-4 switch tabs, ↑/↓ (or j/k) move, enter opens a thread or
+conversation, esc goes back, l likes, t retweets, r replies, n posts,
+ctrl+r refreshes and q quits.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		opts, err := serviceOptions()
+		if err != nil {
+			return err
+		}
+
+		return tui.Run(stores, user, opts)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/RazinShafayet2007/twitter-cli/internal/auth"
 	"github.com/RazinShafayet2007/twitter-cli/internal/config"
-	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
 	"github.com/spf13/cobra"
 )
@@ -21,8 +23,12 @@ var userCreateCmd = &cobra.Command{
 			return err
 		}
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.Create(username)
+		password, err := readNewPassword("Password: ")
+		if err != nil {
+			return err
+		}
+
+		user, err := services.Users.Register(username, password)
 		if err != nil {
 			return err
 		}
@@ -39,37 +45,149 @@ var loginCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := validation.SanitizeUsername(args[0])
 
-		// Check if user exists
-		userStore := store.NewUserStore(DB)
-		_, err := userStore.GetByUsername(username)
+		// Always ask for the password first, so unknown usernames look the
+		// same as wrong passwords
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		user, err := services.Users.Authenticate(username, password)
+		if errors.Is(err, auth.ErrWrongPassword) {
+			if legacy, _ := config.GetLegacyUser(); legacy == username {
+				return fmt.Errorf("%w; if @%s was created before passwords existed, set one with `twt user claim %s`",
+					err, username, username)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		token, session, err := services.Users.StartSession(user.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := config.SetSessionToken(token); err != nil {
+			return fmt.Errorf("failed to save login state: %w", err)
+		}
+
+		fmt.Printf("Logged in as @%s (session expires %s)\n", username,
+			time.Unix(session.ExpiresAt, 0).Format("Jan 2, 2006"))
+		return nil
+	},
+}
+
+var userClaimCmd = &cobra.Command{
+	Use:   "claim [username]",
+	Short: "Set the first password of an account created before passwords",
+	Long: `Set the first password of an account created before passwords existed.
+Nobody can log in as such an account until its owner claims it here. Only the
+account that was last logged in on this machine before passwords existed (the
+current_user saved in config.json) can be claimed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := validation.SanitizeUsername(args[0])
+
+		password, err := readNewPassword("New password: ")
 		if err != nil {
-			return fmt.Errorf("user @%s not found", username)
+			return err
 		}
 
-		// Save to config
-		if err := config.SetCurrentUser(username); err != nil {
+		legacyUser, err := config.GetLegacyUser()
+		if err != nil {
+			return fmt.Errorf("failed to read login state: %w", err)
+		}
+
+		if _, err := services.Users.ClaimAccount(username, legacyUser, password); err != nil {
+			return err
+		}
+
+		if err := config.ClearLegacyUser(); err != nil {
 			return fmt.Errorf("failed to save login state: %w", err)
 		}
 
-		fmt.Printf("Logged in as @%s\n", username)
+		fmt.Printf("Claimed @%s, log in with `twt login %s`\n", username, username)
 		return nil
 	},
 }
 
+var logoutAll bool
+
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Logout current user",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		currentUser, err := config.GetCurrentUser()
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		token, err := config.GetSessionToken()
 		if err != nil {
 			return err
 		}
 
-		if err := config.ClearCurrentUser(); err != nil {
+		if logoutAll {
+			count, err := services.Users.EndAllSessions(user.ID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Ended %d session(s) for @%s\n", count, user.Username)
+		} else if err := services.Users.EndSession(token); err != nil {
+			return err
+		}
+
+		if err := config.ClearSessionToken(); err != nil {
 			return fmt.Errorf("failed to logout: %w", err)
 		}
 
-		fmt.Printf("Logged out @%s\n", currentUser)
+		fmt.Printf("Logged out @%s\n", user.Username)
+		return nil
+	},
+}
+
+var passwdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change your password",
+	Long:  "Change your password. Every other session of your account is logged out.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, err := loggedInUser()
+		if err != nil {
+			return err
+		}
+
+		hasPassword, err := services.Users.HasPassword(user.Username)
+		if err != nil {
+			return err
+		}
+
+		var current string
+		if hasPassword {
+			if current, err = readPassword("Current password: "); err != nil {
+				return err
+			}
+		}
+
+		password, err := readNewPassword("New password: ")
+		if err != nil {
+			return err
+		}
+
+		token, err := config.GetSessionToken()
+		if err != nil {
+			return err
+		}
+
+		count, err := services.Users.ChangePassword(user.ID, token, current, password)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Password changed")
+		if count > 0 {
+			fmt.Printf("Logged out %d other session(s)\n", count)
+		}
 		return nil
 	},
 }
@@ -78,29 +196,23 @@ var whoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show current logged-in user",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		username, err := config.GetCurrentUser()
+		user, err := loggedInUser()
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("@%s\n", username)
+		fmt.Printf("@%s\n", user.Username)
 
-		userStore := store.NewUserStore(DB)
-		user, err := userStore.GetByUsername(username)
-		if err == nil {
-			// Show unread messages
-			messageStore := store.NewMessageStore(DB)
-			unreadMessages, err := messageStore.GetUnreadCount(user.ID)
-			if err == nil && unreadMessages > 0 {
-				fmt.Printf("💬 %d unread message(s)\n", unreadMessages)
-			}
+		// Show unread messages
+		unreadMessages, err := stores.Messages.GetUnreadCount(user.ID)
+		if err == nil && unreadMessages > 0 {
+			fmt.Printf("💬 %d unread message(s)\n", unreadMessages)
+		}
 
-			// Show unread notifications
-			notifStore := store.NewNotificationStore(DB)
-			unreadNotifs, err := notifStore.GetUnreadCount(user.ID)
-			if err == nil && unreadNotifs > 0 {
-				fmt.Printf("🔔 %d unread notification(s)\n", unreadNotifs)
-			}
+		// Show unread notifications
+		unreadNotifs, err := stores.Notifications.GetUnreadCount(user.ID)
+		if err == nil && unreadNotifs > 0 {
+			fmt.Printf("🔔 %d unread notification(s)\n", unreadNotifs)
 		}
 
 		return nil
@@ -116,10 +228,14 @@ func init() {
 
 	// Add subcommands
 	userCmd.AddCommand(userCreateCmd)
+	userCmd.AddCommand(userClaimCmd)
+
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Log out every session of this account, on all devices")
 
 	// Add to root
 	rootCmd.AddCommand(userCmd)
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(passwdCmd)
 	rootCmd.AddCommand(whoamiCmd)
 }
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/output"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/oklog/ulid/v2"
+)
+
+// watchBatch caps how many rows a live mode fetches per query, so a burst
+// of activity is paged through rather than loaded all at once
+const watchBatch = 100
+
+// watchCursor returns a cursor for rows created from now on. IDs are ULIDs,
+// so anything created later sorts after it.
+func watchCursor() models.Cursor {
+	return models.Cursor{CreatedAt: time.Now().Unix(), ID: ulid.Make().String()}
+}
+
+// laterCursor returns whichever of two cursors is newer
+func laterCursor(a, b models.Cursor) models.Cursor {
+	if b.CreatedAt > a.CreatedAt || (b.CreatedAt == a.CreatedAt && b.ID > a.ID) {
+		return b
+	}
+	return a
+}
+
+// pollAfter pages through the items fetch returns after cursor, oldest
+// first, in batches of watchBatch, calling show for each batch. It returns
+// the cursor of the newest item shown.
+func pollAfter[T store.Paged](cursor models.Cursor, fetch func(store.Page) ([]T, error), show func([]T) error) (models.Cursor, error) {
+	for {
+		items, err := fetch(store.Page{After: &cursor, Limit: watchBatch})
+		if err != nil || len(items) == 0 {
+			return cursor, err
+		}
+		cursor = items[0].Cursor()
+
+		// Pages come newest first
+		slices.Reverse(items)
+		if err := show(items); err != nil {
+			return cursor, err
+		}
+
+		if len(items) < watchBatch {
+			return cursor, nil
+		}
+	}
+}
+
+// checkLiveOutput rejects a live mode's flag with --output formats that
+// are one document, since printing each batch as it arrives would write
+// several. jsonl is one object per line, so it can be appended to.
+func checkLiveOutput(flag string) error {
+	if machineReadable() && outputFormat != output.JSONL {
+		return fmt.Errorf("%s only works with --output text or jsonl", flag)
+	}
+	return nil
+}
+
+// watch calls poll every interval until the user presses Ctrl-C, which ends
+// it cleanly, or poll fails
+func watch(interval time.Duration, poll func() error) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if !machineReadable() {
+				fmt.Println()
+			}
+			return nil
+		case <-ticker.C:
+			if err := poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// mentions reports whether text mentions username
+func mentions(text, username string) bool {
+	for _, m := range parser.ExtractMentions(text) {
+		if strings.EqualFold(m, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// ringBell sounds the terminal bell. It goes to stderr so it never mixes
+// into --output data.
+func ringBell() {
+	fmt.Fprint(os.Stderr, "\a")
+}
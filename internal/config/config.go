@@ -8,7 +8,27 @@ import (
 )
 
 type Config struct {
-	CurrentUser string `json:"current_user,omitempty"`
+	// SessionToken identifies the login; the user it belongs to is looked
+	// up in the database so an expired or revoked session stops working
+	SessionToken string `json:"session_token,omitempty"`
+
+	// LegacyUser is the username saved by logins from before passwords
+	// existed. It is the proof of ownership `twt user claim` asks for, and
+	// is cleared once that account is claimed.
+	LegacyUser string `json:"current_user,omitempty"`
+
+	// FeedFanout chooses how home feeds are built: "read" (the default)
+	// queries them when read, "write" copies each post into its readers'
+	// timelines when it's posted
+	FeedFanout string `json:"feed_fanout,omitempty"`
+
+	// FanoutLimit is the follower count above which posts are pulled when
+	// feeds are read rather than copied on write. Zero uses the default.
+	FanoutLimit int `json:"fanout_limit,omitempty"`
+
+	// EditWindow is how long after posting a post can be edited, as a
+	// duration like 30m or 1d. Empty uses the default.
+	EditWindow string `json:"edit_window,omitempty"`
 }
 
 // GetConfigPath returns the path to the config file
@@ -56,41 +76,68 @@ func SaveConfig(config *Config) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	// The session token is a credential, keep it private (WriteFile leaves
+	// the mode of an existing file alone)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
 }
 
-// GetCurrentUser returns the currently logged-in user
-func GetCurrentUser() (string, error) {
+// GetSessionToken returns the token of the current login
+func GetSessionToken() (string, error) {
 	config, err := LoadConfig()
 	if err != nil {
 		return "", err
 	}
 
-	if config.CurrentUser == "" {
+	if config.SessionToken == "" {
 		return "", errors.New("not logged in")
 	}
 
-	return config.CurrentUser, nil
+	return config.SessionToken, nil
 }
 
-// SetCurrentUser sets the currently logged-in user
-func SetCurrentUser(username string) error {
+// SetSessionToken saves the token of a new login
+func SetSessionToken(token string) error {
 	config, err := LoadConfig()
 	if err != nil {
 		return err
 	}
 
-	config.CurrentUser = username
+	config.SessionToken = token
 	return SaveConfig(config)
 }
 
-// ClearCurrentUser logs out the current user
-func ClearCurrentUser() error {
+// ClearSessionToken forgets the current login
+func ClearSessionToken() error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	config.SessionToken = ""
+	return SaveConfig(config)
+}
+
+// GetLegacyUser returns the username saved by a login from before
+// passwords existed, or "" if there is none
+func GetLegacyUser() (string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return config.LegacyUser, nil
+}
+
+// ClearLegacyUser forgets the pre-password login once it has been claimed
+func ClearLegacyUser() error {
 	config, err := LoadConfig()
 	if err != nil {
 		return err
 	}
 
-	config.CurrentUser = ""
+	config.LegacyUser = ""
 	return SaveConfig(config)
 }
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// counters lists each denormalized counter column with the query that
+// counts what it should hold, correlated on the row's id
+var counters = []struct {
+	table, column, count string
+}{
+	{"posts", "like_count", `SELECT COUNT(*) FROM likes WHERE post_id = t.id`},
+	{"posts", "retweet_count", `SELECT COUNT(*) FROM posts r WHERE r.original_post_id = t.id AND r.is_retweet = 1`},
+	{"posts", "reply_count", `SELECT COUNT(*) FROM posts r WHERE r.parent_post_id = t.id`},
+	{"posts", "media_count", `SELECT COUNT(*) FROM media WHERE post_id = t.id`},
+	{"posts", "quote_count", `SELECT COUNT(*) FROM posts q WHERE q.quoted_post_id = t.id AND q.is_retweet = 0`},
+	{"users", "follower_count", `SELECT COUNT(*) FROM follows WHERE followee_id = t.id`},
+	{"users", "following_count", `SELECT COUNT(*) FROM follows WHERE follower_id = t.id`},
+	{"users", "post_count", `SELECT COUNT(*) FROM posts p WHERE p.author_id = t.id`},
+}
+
+// CounterDrift is a counter that doesn't match what it counts
+type CounterDrift struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	ID     string `json:"id"`
+	Stored int    `json:"stored"`
+	Actual int    `json:"actual"`
+}
+
+// ReconcileCounters recounts every denormalized counter and returns the
+// ones that have drifted. With fix set, they're also corrected, all in one
+// transaction.
+func ReconcileCounters(db *sql.DB, fix bool) ([]CounterDrift, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('posts') WHERE name = 'like_count'`,
+	).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up counters: %w", err)
+	}
+	if count == 0 {
+		return nil, errors.New("counters not found: run twt db migrate first")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var drift []CounterDrift
+	for _, c := range counters {
+		found, err := findDrift(tx, c.table, c.column, c.count)
+		if err != nil {
+			return nil, err
+		}
+
+		if fix {
+			for _, d := range found {
+				query := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE id = ?`, c.table, c.column)
+				if _, err := tx.Exec(query, d.Actual, d.ID); err != nil {
+					return nil, fmt.Errorf("failed to fix %s.%s: %w", c.table, c.column, err)
+				}
+			}
+		}
+
+		drift = append(drift, found...)
+	}
+
+	if fix {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to fix counters: %w", err)
+		}
+	}
+
+	return drift, nil
+}
+
+// findDrift returns the rows of table whose column differs from count
+func findDrift(tx *sql.Tx, table, column, count string) ([]CounterDrift, error) {
+	query := fmt.Sprintf(`
+		SELECT id, stored, actual FROM (
+			SELECT t.id, t.%s AS stored, (%s) AS actual
+			FROM %s t
+		)
+		WHERE stored != actual
+		ORDER BY id
+	`, column, count, table)
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s.%s: %w", table, column, err)
+	}
+	defer rows.Close()
+
+	var drift []CounterDrift
+	for rows.Next() {
+		d := CounterDrift{Table: table, Column: column}
+		if err := rows.Scan(&d.ID, &d.Stored, &d.Actual); err != nil {
+			return nil, fmt.Errorf("failed to scan %s.%s: %w", table, column, err)
+		}
+		drift = append(drift, d)
+	}
+
+	return drift, rows.Err()
+}
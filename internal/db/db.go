@@ -18,87 +18,75 @@ func GetDefaultDBPath() string {
 	return filepath.Join(home, ".twitter-cli", "data.db")
 }
 
-// InitDB initializes the database and creates tables
+// InitDB opens the database, setting up the schema on first use. A database
+// that is already managed by migrations but behind the binary (after an
+// upgrade or a twt db rollback) is refused rather than silently migrated.
 func InitDB(dbPath string) (*sql.DB, error) {
-	// Ensure directory exists
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create db directory: %w", err)
-	}
-
-	// Open database connection
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := Open(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// Set busy timeout
-	if _, err := db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
-		return nil, fmt.Errorf("failed to set busy timeout: %w", err)
-	}
-
-	// Enable WAL mode for better concurrency
-	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	statuses, err := Status(db)
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	applied, pending := 0, 0
+	for _, s := range statuses {
+		if s.Applied {
+			applied++
+		} else {
+			pending++
+		}
 	}
 
-	// Execute schema
-	if err := executeSchema(db); err != nil {
-		return nil, fmt.Errorf("failed to execute schema: %w", err)
+	switch {
+	case applied == 0:
+		// Fresh database, or one created before schema_migrations existed
+		if _, err := Migrate(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	case pending > 0:
+		db.Close()
+		return nil, fmt.Errorf("database schema is behind by %d migration(s). Run: twt db migrate", pending)
 	}
 
-	// Run migrations
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	if err := checkSearchIndex(db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return db, nil
 }
 
-// runMigrations handles database migrations
-func runMigrations(db *sql.DB) error {
-	// Check if parent_post_id column exists in posts table
-	var count int
-	query := `SELECT count(*) FROM pragma_table_info('posts') WHERE name = 'parent_post_id'`
-	if err := db.QueryRow(query).Scan(&count); err != nil {
-		return fmt.Errorf("failed to check for parent_post_id column: %w", err)
+// Open opens the database connection without touching the schema
+func Open(dbPath string) (*sql.DB, error) {
+	// Ensure directory exists
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create db directory: %w", err)
 	}
 
-	if count == 0 {
-		// Add parent_post_id column
-		alterQuery := `ALTER TABLE posts ADD COLUMN parent_post_id TEXT REFERENCES posts(id) ON DELETE SET NULL`
-		if _, err := db.Exec(alterQuery); err != nil {
-			return fmt.Errorf("failed to add parent_post_id column: %w", err)
-		}
-		fmt.Println("Migrated database: added parent_post_id to posts table")
+	// Open database connection. Busy timeout and foreign keys are
+	// per-connection settings, so pass them in the DSN to cover every
+	// connection in the pool rather than just the first one.
+	db, err := sql.Open("sqlite3", dbPath+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return nil
-}
-
-// executeSchema reads and executes the schema.sql file
-func executeSchema(db *sql.DB) error {
-	// Read schema file
-	schemaPath := filepath.Join("internal", "db", "schema.sql")
-	schema, err := os.ReadFile(schemaPath)
-	if err != nil {
-		return fmt.Errorf("failed to read schema.sql: %w", err)
+	// Test connection
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Execute schema
-	if _, err := db.Exec(string(schema)); err != nil {
-		return fmt.Errorf("failed to execute schema: %w", err)
+	// Enable WAL mode for better concurrency
+	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
 	}
 
-	return nil
+	return db, nil
 }
@@ -0,0 +1,8 @@
+//go:build sqlite_fts5 || fts5
+
+package db
+
+// FullTextSearch reports whether this build's SQLite includes FTS5, which
+// the search index needs. go-sqlite3 only compiles it in with the
+// sqlite_fts5 build tag.
+const FullTextSearch = true
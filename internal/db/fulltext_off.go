@@ -0,0 +1,7 @@
+//go:build !(sqlite_fts5 || fts5)
+
+package db
+
+// FullTextSearch reports whether this build's SQLite includes FTS5. Without
+// it there is no search index and search matches text directly.
+const FullTextSearch = false
@@ -0,0 +1,334 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt int64
+}
+
+// alreadyApplied detects migrations whose changes exist in databases created
+// before schema_migrations was introduced (by the old schema.sql or the
+// scripts/migrate-*.sh helpers). Those are recorded without being run.
+var alreadyApplied = map[int]func(conn *sql.Conn) (bool, error){
+	2: func(conn *sql.Conn) (bool, error) {
+		return columnExists(conn, "posts", "parent_post_id")
+	},
+}
+
+// searchIndexMigrations build the full-text search index. Builds without
+// FTS5 record them without running them, and search matches text directly.
+var searchIndexMigrations = map[int]bool{
+	9:                  true,
+	searchIndexVersion: true,
+}
+
+// LoadMigrations returns all embedded migrations ordered by version
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		// File names look like 0001_create_core_tables.up.sql
+		name := entry.Name()
+		base := strings.TrimSuffix(name, ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		versionStr, migrationName, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+
+		body, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: migrationName}
+			byVersion[version] = m
+		}
+
+		switch direction {
+		case ".up":
+			m.Up = string(body)
+		case ".down":
+			m.Down = string(body)
+		default:
+			return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", name)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrate applies all pending migrations and returns the ones it applied
+func Migrate(db *sql.DB) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := migrationConn(db)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseMigrationConn(conn)
+
+	applied, err := appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		skip := false
+		if detect, ok := alreadyApplied[m.Version]; ok {
+			skip, err = detect(conn)
+			if err != nil {
+				return ran, fmt.Errorf("failed to inspect migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+
+		script := m.Up
+		if skip || (searchIndexMigrations[m.Version] && !FullTextSearch) {
+			script = ""
+		}
+
+		if err := runMigration(conn, script, func(tx *sql.Tx) error {
+			_, err := tx.Exec(
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.Version, m.Name, time.Now().Unix(),
+			)
+			return err
+		}); err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				// A build with FTS5 gave this database a search index, whose
+				// triggers can't run without it
+				err = fmt.Errorf("%w (the database has a search index; build with -tags sqlite_fts5)", err)
+			}
+			return ran, fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+
+		ran = append(ran, m)
+	}
+
+	return ran, nil
+}
+
+// Rollback reverts the most recently applied migrations, newest first
+func Rollback(db *sql.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := migrationConn(db)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseMigrationConn(conn)
+
+	applied, err := appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		if m.Down == "" {
+			return reverted, fmt.Errorf("migration %04d_%s cannot be rolled back", m.Version, m.Name)
+		}
+
+		script := m.Down
+		if searchIndexMigrations[m.Version] && !FullTextSearch {
+			script = ""
+		}
+
+		if err := runMigration(conn, script, func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+			return err
+		}); err != nil {
+			return reverted, fmt.Errorf("rollback of %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+
+		reverted = append(reverted, m)
+	}
+
+	return reverted, nil
+}
+
+// Status lists every known migration and whether it has been applied
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: m,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// migrationConn pins a single connection with foreign keys disabled, so
+// migrations can rebuild tables without cascading deletes
+func migrationConn(db *sql.DB) (*sql.Conn, error) {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	// PRAGMA foreign_keys is a no-op inside a transaction, so set it first
+	if _, err := conn.ExecContext(context.Background(), "PRAGMA foreign_keys = OFF"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+
+	return conn, nil
+}
+
+// releaseMigrationConn re-enables foreign keys before returning the connection to the pool
+func releaseMigrationConn(conn *sql.Conn) {
+	conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+	conn.Close()
+}
+
+// runMigration executes a script and records it in one transaction
+func runMigration(conn *sql.Conn, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.Exec(script); err != nil {
+			return err
+		}
+	}
+
+	if err := record(tx); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// appliedVersions returns applied migration versions mapped to their apply time
+func appliedVersions(conn *sql.Conn) (map[int]int64, error) {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`
+	if _, err := conn.ExecContext(context.Background(), query); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(context.Background(), `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]int64)
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// columnExists checks whether a table already has a column
+func columnExists(conn *sql.Conn, table, column string) (bool, error) {
+	var count int
+	query := `SELECT count(*) FROM pragma_table_info(?) WHERE name = ?`
+	if err := conn.QueryRowContext(context.Background(), query, table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check for %s column: %w", column, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openTestDB opens a fresh database file without running migrations
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if err := db.QueryRow(query, name).Scan(&count); err != nil {
+		t.Fatalf("failed to check table %s: %v", name, err)
+	}
+	return count > 0
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	applied, err := Migrate(db)
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("expected %d migrations applied, got %d", len(migrations), len(applied))
+	}
+
+	for _, table := range []string{"users", "posts", "messages", "blocks", "notifications", "hashtags", "mentions", "media"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("expected table %s to exist", table)
+		}
+	}
+
+	// Running again is a no-op
+	applied, err = Migrate(db)
+	if err != nil {
+		t.Fatalf("failed to re-run migrations: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected no migrations on second run, got %d", len(applied))
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	// Shape of a database created by the old schema.sql
+	legacy := `
+		CREATE TABLE users (id TEXT PRIMARY KEY, username TEXT UNIQUE NOT NULL, created_at INTEGER NOT NULL);
+		CREATE TABLE posts (
+			id TEXT PRIMARY KEY,
+			author_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			is_retweet INTEGER DEFAULT 0,
+			original_post_id TEXT,
+			parent_post_id TEXT
+		);
+		INSERT INTO users (id, username, created_at) VALUES ('u1', 'alice', 1);
+		INSERT INTO posts (id, author_id, text, created_at) VALUES ('p1', 'u1', 'hello', 1);
+	`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatalf("failed to migrate legacy database: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&count); err != nil {
+		t.Fatalf("failed to count posts: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected existing post to survive migration, got %d posts", count)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO users (id, username, created_at) VALUES ('u1', 'alice', 1)`); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO posts (id, author_id, text, created_at) VALUES ('p1', 'u1', 'hello', 1)`); err != nil {
+		t.Fatalf("failed to insert post: %v", err)
+	}
+
+	migrations, _ := LoadMigrations()
+
+	// Roll back everything above the replies migration
+	reverted, err := Rollback(db, len(migrations)-2)
+	if err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	if len(reverted) != len(migrations)-2 {
+		t.Errorf("expected %d migrations reverted, got %d", len(migrations)-2, len(reverted))
+	}
+	if tableExists(t, db, "media") {
+		t.Error("expected media table to be dropped")
+	}
+
+	// Rolling back the replies migration rebuilds posts without losing rows
+	if _, err := Rollback(db, 1); err != nil {
+		t.Fatalf("failed to roll back replies: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('posts') WHERE name = 'parent_post_id'`).Scan(&count); err != nil {
+		t.Fatalf("failed to inspect posts: %v", err)
+	}
+	if count != 0 {
+		t.Error("expected parent_post_id to be removed")
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM posts`).Scan(&count); err != nil {
+		t.Fatalf("failed to count posts: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected post to survive rollback, got %d", count)
+	}
+
+	// And everything can be re-applied
+	if _, err := Migrate(db); err != nil {
+		t.Fatalf("failed to re-apply migrations: %v", err)
+	}
+}
+
+func TestInitDB_RefusesPendingMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// A fresh path is migrated automatically
+	db, err := InitDB(path)
+	if err != nil {
+		t.Fatalf("failed to init fresh db: %v", err)
+	}
+	if _, err := Rollback(db, 1); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	db.Close()
+
+	// After a rollback ordinary opens must not undo it
+	if _, err := InitDB(path); err == nil || !strings.Contains(err.Error(), "twt db migrate") {
+		t.Fatalf("expected init to refuse a schema that is behind, got %v", err)
+	}
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if _, err := Migrate(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	db.Close()
+
+	db, err = InitDB(path)
+	if err != nil {
+		t.Fatalf("expected init to succeed once migrated, got %v", err)
+	}
+	db.Close()
+}
+
+func TestInitDB_SearchIndexFollowsBuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := InitDB(path)
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	if got := tableExists(t, db, "posts_fts"); got != FullTextSearch {
+		t.Fatalf("expected search index to exist: %v, got %v", FullTextSearch, got)
+	}
+	if !FullTextSearch {
+		db.Close()
+		return
+	}
+
+	// A database migrated without FTS5 gets its index on first use
+	drop := `
+		DROP TRIGGER posts_fts_insert; DROP TRIGGER posts_fts_update; DROP TRIGGER posts_fts_delete;
+		DROP TRIGGER messages_fts_insert; DROP TRIGGER messages_fts_update; DROP TRIGGER messages_fts_delete;
+		DROP TABLE posts_fts; DROP TABLE messages_fts;
+	`
+	if _, err := db.Exec(drop); err != nil {
+		t.Fatalf("failed to drop search index: %v", err)
+	}
+	db.Close()
+
+	db, err = InitDB(path)
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer db.Close()
+	if !tableExists(t, db, "posts_fts") || !tableExists(t, db, "messages_fts") {
+		t.Error("expected the search index to be rebuilt")
+	}
+}
@@ -13,10 +13,8 @@ CREATE TABLE IF NOT EXISTS posts (
     created_at INTEGER NOT NULL,
     is_retweet INTEGER DEFAULT 0,
     original_post_id TEXT,
-    parent_post_id TEXT,
     FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
-    FOREIGN KEY (original_post_id) REFERENCES posts(id) ON DELETE SET NULL,
-    FOREIGN KEY (parent_post_id) REFERENCES posts(id) ON DELETE SET NULL
+    FOREIGN KEY (original_post_id) REFERENCES posts(id) ON DELETE SET NULL
 );
 
 -- Follows table
@@ -45,21 +43,3 @@ CREATE INDEX IF NOT EXISTS idx_posts_created ON posts(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_follows_follower ON follows(follower_id);
 CREATE INDEX IF NOT EXISTS idx_follows_followee ON follows(followee_id);
 CREATE INDEX IF NOT EXISTS idx_likes_post ON likes(post_id);
-
--- Messages table
-CREATE TABLE IF NOT EXISTS messages (
-    id TEXT PRIMARY KEY,
-    sender_id TEXT NOT NULL,
-    receiver_id TEXT NOT NULL,
-    text TEXT NOT NULL,
-    created_at INTEGER NOT NULL,
-    read INTEGER DEFAULT 0,  -- 0 = unread, 1 = read
-    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
-    FOREIGN KEY (receiver_id) REFERENCES users(id) ON DELETE CASCADE
-);
-
--- Indexes for queries
-CREATE INDEX IF NOT EXISTS idx_messages_receiver ON messages(receiver_id, created_at DESC);
-CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages(sender_id, created_at DESC);
-CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(sender_id, receiver_id, created_at DESC);
-CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(receiver_id, read);
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- SQLite cannot drop a column used in a foreign key, so rebuild the table
+CREATE TABLE posts_old (
+    id TEXT PRIMARY KEY,
+    author_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    is_retweet INTEGER DEFAULT 0,
+    original_post_id TEXT,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (original_post_id) REFERENCES posts(id) ON DELETE SET NULL
+);
+
+INSERT INTO posts_old (id, author_id, text, created_at, is_retweet, original_post_id)
+SELECT id, author_id, text, created_at, is_retweet, original_post_id FROM posts;
+
+DROP TABLE posts;
+ALTER TABLE posts_old RENAME TO posts;
+
+CREATE INDEX IF NOT EXISTS idx_posts_author ON posts(author_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_created ON posts(created_at DESC);
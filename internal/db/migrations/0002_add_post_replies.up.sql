@@ -0,0 +1,2 @@
+-- Replies point at the post they answer
+ALTER TABLE posts ADD COLUMN parent_post_id TEXT REFERENCES posts(id) ON DELETE SET NULL;
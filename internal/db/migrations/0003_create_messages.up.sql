@@ -1,28 +1,17 @@
-#!/bin/bash
-# scripts/migrate-messages.sh
-
-DB_PATH="$HOME/.twitter-cli/data.db"
-
-echo "Adding messages table..."
-
-sqlite3 "$DB_PATH" << 'EOF'
+-- Messages table
 CREATE TABLE IF NOT EXISTS messages (
     id TEXT PRIMARY KEY,
     sender_id TEXT NOT NULL,
     receiver_id TEXT NOT NULL,
     text TEXT NOT NULL,
     created_at INTEGER NOT NULL,
-    read INTEGER DEFAULT 0,
+    read INTEGER DEFAULT 0,  -- 0 = unread, 1 = read
     FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
     FOREIGN KEY (receiver_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+-- Indexes for queries
 CREATE INDEX IF NOT EXISTS idx_messages_receiver ON messages(receiver_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages(sender_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(sender_id, receiver_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_messages_unread ON messages(receiver_id, read);
-
-SELECT 'Messages table created!';
-EOF
-
-echo "✓ Migration complete"
\ No newline at end of file
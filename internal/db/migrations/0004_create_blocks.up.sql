@@ -1,10 +1,4 @@
-#!/bin/bash
-
-DB_PATH="$HOME/.twitter-cli/data.db"
-
-echo "Adding blocks table..."
-
-sqlite3 "$DB_PATH" << 'EOF'
+-- Blocks table
 CREATE TABLE IF NOT EXISTS blocks (
     blocker_id TEXT NOT NULL,
     blocked_id TEXT NOT NULL,
@@ -16,8 +10,3 @@ CREATE TABLE IF NOT EXISTS blocks (
 
 CREATE INDEX IF NOT EXISTS idx_blocks_blocker ON blocks(blocker_id);
 CREATE INDEX IF NOT EXISTS idx_blocks_blocked ON blocks(blocked_id);
-
-SELECT 'Blocks table created!';
-EOF
-
-echo "✓ Migration complete"
\ No newline at end of file
@@ -1,10 +1,4 @@
-#!/bin/bash
-
-DB_PATH="$HOME/.twitter-cli/data.db"
-
-echo "Adding notifications table..."
-
-sqlite3 "$DB_PATH" << 'EOF'
+-- Notifications table
 CREATE TABLE IF NOT EXISTS notifications (
     id TEXT PRIMARY KEY,
     user_id TEXT NOT NULL,
@@ -19,8 +13,3 @@ CREATE TABLE IF NOT EXISTS notifications (
 
 CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_notifications_read ON notifications(user_id, read);
-
-SELECT 'Notifications table created!';
-EOF
-
-echo "✓ Migration complete"
\ No newline at end of file
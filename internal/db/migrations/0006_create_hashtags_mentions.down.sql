@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS mentions;
+DROP TABLE IF EXISTS post_hashtags;
+DROP TABLE IF EXISTS hashtags;
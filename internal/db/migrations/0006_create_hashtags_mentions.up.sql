@@ -1,10 +1,3 @@
-#!/bin/bash
-
-DB_PATH="$HOME/.twitter-cli/data.db"
-
-echo "Adding hashtags and mentions tables..."
-
-sqlite3 "$DB_PATH" << 'EOF'
 -- Hashtags table
 CREATE TABLE IF NOT EXISTS hashtags (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -36,8 +29,3 @@ CREATE INDEX IF NOT EXISTS idx_hashtags_tag ON hashtags(tag);
 CREATE INDEX IF NOT EXISTS idx_post_hashtags_hashtag ON post_hashtags(hashtag_id);
 CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(mentioned_user_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_mentions_post ON mentions(post_id);
-
-SELECT 'Hashtags and mentions tables created!';
-EOF
-
-echo "✓ Migration complete"
\ No newline at end of file
@@ -1,10 +1,3 @@
-#!/bin/bash
-
-DB_PATH="$HOME/.twitter-cli/data.db"
-
-echo "Adding media tables..."
-
-sqlite3 "$DB_PATH" << 'EOF'
 -- Media table
 CREATE TABLE IF NOT EXISTS media (
     id TEXT PRIMARY KEY,
@@ -21,8 +14,3 @@ CREATE TABLE IF NOT EXISTS media (
 );
 
 CREATE INDEX IF NOT EXISTS idx_media_post ON media(post_id, position);
-
-SELECT 'Media table created!';
-EOF
-
-echo "✓ Migration complete"
\ No newline at end of file
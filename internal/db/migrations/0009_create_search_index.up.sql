@@ -0,0 +1,57 @@
+-- Full-text indexes over post and message text. They keep their own copy of
+-- the text and are kept in sync by the triggers below; `twt db reindex`
+-- rebuilds them from scratch.
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+    post_id UNINDEXED,
+    text,
+    tokenize='porter unicode61',
+    prefix='2 3'
+);
+
+CREATE VIRTUAL TABLE messages_fts USING fts5(
+    message_id UNINDEXED,
+    text,
+    tokenize='porter unicode61',
+    prefix='2 3'
+);
+
+-- Retweets have no text of their own, so only original posts are indexed
+INSERT INTO posts_fts (post_id, text)
+SELECT id, text FROM posts WHERE is_retweet = 0;
+
+INSERT INTO messages_fts (message_id, text)
+SELECT id, text FROM messages;
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts
+WHEN new.is_retweet = 0
+BEGIN
+    INSERT INTO posts_fts (post_id, text) VALUES (new.id, new.text);
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF text ON posts
+BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+    INSERT INTO posts_fts (post_id, text)
+    SELECT new.id, new.text WHERE new.is_retweet = 0;
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts
+BEGIN
+    DELETE FROM posts_fts WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER messages_fts_insert AFTER INSERT ON messages
+BEGIN
+    INSERT INTO messages_fts (message_id, text) VALUES (new.id, new.text);
+END;
+
+CREATE TRIGGER messages_fts_update AFTER UPDATE OF text ON messages
+BEGIN
+    DELETE FROM messages_fts WHERE message_id = old.id;
+    INSERT INTO messages_fts (message_id, text) VALUES (new.id, new.text);
+END;
+
+CREATE TRIGGER messages_fts_delete AFTER DELETE ON messages
+BEGIN
+    DELETE FROM messages_fts WHERE message_id = old.id;
+END;
@@ -0,0 +1,2 @@
+-- Removed follows can't be restored; nothing to undo
+SELECT 1;
@@ -0,0 +1,8 @@
+-- Blocking now removes follows in both directions; clean up follows left
+-- over from blocks made before that
+DELETE FROM follows
+WHERE EXISTS (
+    SELECT 1 FROM blocks b
+    WHERE (b.blocker_id = follows.follower_id AND b.blocked_id = follows.followee_id)
+       OR (b.blocker_id = follows.followee_id AND b.blocked_id = follows.follower_id)
+);
@@ -0,0 +1,11 @@
+-- Users, words and hashtags a user has muted. value is the muted user's
+-- ID, the lowercased word or phrase, or the tag without its #.
+CREATE TABLE IF NOT EXISTS mutes (
+    user_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('user', 'word', 'hashtag')),
+    value TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    expires_at INTEGER, -- NULL mutes until unmuted
+    PRIMARY KEY (user_id, kind, value),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
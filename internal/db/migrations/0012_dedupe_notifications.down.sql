@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notifications_target;
+DROP INDEX IF EXISTS idx_notifications_unique;
@@ -0,0 +1,17 @@
+-- Keep only the newest of any duplicate notifications
+DELETE FROM notifications
+WHERE EXISTS (
+    SELECT 1 FROM notifications newer
+    WHERE newer.user_id = notifications.user_id
+      AND newer.actor_id = notifications.actor_id
+      AND newer.type = notifications.type
+      AND IFNULL(newer.target_id, '') = IFNULL(notifications.target_id, '')
+      AND (newer.created_at > notifications.created_at
+           OR (newer.created_at = notifications.created_at AND newer.id > notifications.id))
+);
+
+-- One notification per user, actor, type and target
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unique
+    ON notifications(user_id, actor_id, type, IFNULL(target_id, ''));
+
+CREATE INDEX IF NOT EXISTS idx_notifications_target ON notifications(target_id);
@@ -0,0 +1,20 @@
+-- Which notifications of each type a user wants. Types without a row use
+-- the defaults: enabled, from anyone, at any like count.
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    only_following INTEGER NOT NULL DEFAULT 0, -- Only from users they follow
+    min_likes INTEGER NOT NULL DEFAULT 0,      -- Only once the post has this many likes
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Daily window, in minutes after local midnight, when no notifications are
+-- created. start_minute > end_minute wraps past midnight.
+CREATE TABLE IF NOT EXISTS notification_quiet_hours (
+    user_id TEXT PRIMARY KEY,
+    start_minute INTEGER NOT NULL,
+    end_minute INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_timelines_user_author;
+DROP INDEX IF EXISTS idx_timelines_user_created;
+DROP TABLE IF EXISTS timelines;
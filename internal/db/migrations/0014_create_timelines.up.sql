@@ -0,0 +1,15 @@
+-- Materialized home timelines for fan-out-on-write: a row for each post in
+-- each timeline it belongs to. Only kept up to date while feed_fanout is
+-- "write"; posts by accounts with many followers are read from posts instead.
+CREATE TABLE IF NOT EXISTS timelines (
+    user_id TEXT NOT NULL,   -- Whose timeline
+    post_id TEXT NOT NULL,
+    author_id TEXT NOT NULL, -- So unfollowing can remove an author's posts
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_timelines_user_created ON timelines(user_id, created_at DESC, post_id DESC);
+CREATE INDEX IF NOT EXISTS idx_timelines_user_author ON timelines(user_id, author_id);
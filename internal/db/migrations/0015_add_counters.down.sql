@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_posts_original;
+DROP INDEX IF EXISTS idx_posts_parent;
+
+ALTER TABLE users DROP COLUMN post_count;
+ALTER TABLE users DROP COLUMN following_count;
+ALTER TABLE users DROP COLUMN follower_count;
+
+ALTER TABLE posts DROP COLUMN media_count;
+ALTER TABLE posts DROP COLUMN reply_count;
+ALTER TABLE posts DROP COLUMN retweet_count;
+ALTER TABLE posts DROP COLUMN like_count;
@@ -0,0 +1,25 @@
+-- Denormalized counters, kept in step by the stores in the same transaction
+-- as the rows they count. twt db reconcile repairs any drift.
+ALTER TABLE posts ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN retweet_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN media_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE users ADD COLUMN follower_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN following_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN post_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE posts SET
+    like_count = (SELECT COUNT(*) FROM likes WHERE post_id = posts.id),
+    retweet_count = (SELECT COUNT(*) FROM posts r WHERE r.original_post_id = posts.id AND r.is_retweet = 1),
+    reply_count = (SELECT COUNT(*) FROM posts r WHERE r.parent_post_id = posts.id),
+    media_count = (SELECT COUNT(*) FROM media WHERE post_id = posts.id);
+
+UPDATE users SET
+    follower_count = (SELECT COUNT(*) FROM follows WHERE followee_id = users.id),
+    following_count = (SELECT COUNT(*) FROM follows WHERE follower_id = users.id),
+    post_count = (SELECT COUNT(*) FROM posts WHERE author_id = users.id);
+
+-- So replies and retweets can be counted by what they point at
+CREATE INDEX IF NOT EXISTS idx_posts_parent ON posts(parent_post_id);
+CREATE INDEX IF NOT EXISTS idx_posts_original ON posts(original_post_id);
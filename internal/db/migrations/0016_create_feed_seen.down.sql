@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feed_seen_user_seen;
+DROP TABLE IF EXISTS feed_seen;
@@ -0,0 +1,12 @@
+-- Posts shown to a user in their ranked feed, so they aren't shown again.
+-- Rows older than the ranked feed's candidate window are pruned.
+CREATE TABLE IF NOT EXISTS feed_seen (
+    user_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    seen_at INTEGER NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_seen_user_seen ON feed_seen(user_id, seen_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_quoted;
+ALTER TABLE posts DROP COLUMN quote_count;
+ALTER TABLE posts DROP COLUMN quoted_post_id;
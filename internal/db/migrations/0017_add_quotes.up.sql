@@ -0,0 +1,7 @@
+-- Quote posts: a post with its own text that points at the post it quotes.
+-- There's no foreign key, so the reference outlives the quoted post and a
+-- quote of a deleted post can say so.
+ALTER TABLE posts ADD COLUMN quoted_post_id TEXT;
+ALTER TABLE posts ADD COLUMN quote_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_quoted ON posts(quoted_post_id);
@@ -0,0 +1,7 @@
+-- Retweets removed on the way up aren't restored
+DROP INDEX IF EXISTS idx_posts_retweets;
+
+UPDATE posts SET
+    text = COALESCE((SELECT o.text FROM posts o WHERE o.id = posts.original_post_id), ''),
+    quoted_post_id = (SELECT o.quoted_post_id FROM posts o WHERE o.id = posts.original_post_id)
+WHERE is_retweet = 1;
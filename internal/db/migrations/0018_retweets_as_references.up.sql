@@ -0,0 +1,44 @@
+-- Retweets become references: they keep no text of their own and always
+-- point at an original post, which is read through when they're shown.
+
+-- Retweets of retweets point at the original at the end of the chain
+UPDATE posts SET original_post_id = (
+    WITH RECURSIVE chain(id, depth) AS (
+        SELECT posts.original_post_id, 0
+        UNION ALL
+        SELECT p.original_post_id, c.depth + 1
+        FROM chain c
+        JOIN posts p ON p.id = c.id
+        WHERE p.is_retweet = 1
+    )
+    SELECT id FROM chain ORDER BY depth DESC LIMIT 1
+)
+WHERE is_retweet = 1
+AND original_post_id IN (SELECT id FROM posts WHERE is_retweet = 1);
+
+-- Retweets of deleted posts have nothing left to show, and following chains
+-- can leave a user retweeting their own post or the same post twice
+DELETE FROM posts
+WHERE is_retweet = 1
+AND (
+    original_post_id IS NULL
+    OR author_id = (SELECT o.author_id FROM posts o WHERE o.id = posts.original_post_id)
+    OR EXISTS (
+        SELECT 1 FROM posts r
+        WHERE r.is_retweet = 1
+        AND r.author_id = posts.author_id
+        AND r.original_post_id = posts.original_post_id
+        AND (r.created_at, r.id) < (posts.created_at, posts.id)
+    )
+);
+
+UPDATE posts SET text = '', quoted_post_id = NULL WHERE is_retweet = 1;
+
+UPDATE posts SET
+    retweet_count = (SELECT COUNT(*) FROM posts r WHERE r.original_post_id = posts.id AND r.is_retweet = 1);
+
+UPDATE users SET
+    post_count = (SELECT COUNT(*) FROM posts WHERE author_id = users.id);
+
+-- A user retweets a post at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_retweets ON posts(author_id, original_post_id) WHERE is_retweet = 1;
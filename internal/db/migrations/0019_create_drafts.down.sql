@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS draft_media;
+DROP INDEX IF EXISTS idx_drafts_due;
+DROP INDEX IF EXISTS idx_drafts_user;
+DROP TABLE IF EXISTS drafts;
@@ -0,0 +1,27 @@
+-- Posts saved to publish later. A draft with no publish_at waits for its
+-- author; one with publish_at is published by twt scheduler run once that
+-- time passes. error says why the scheduler last failed to publish it, and
+-- keeps it from being retried until it's edited.
+CREATE TABLE IF NOT EXISTS drafts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    text TEXT NOT NULL,
+    publish_at INTEGER,
+    error TEXT,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_drafts_user ON drafts(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_drafts_due ON drafts(publish_at) WHERE publish_at IS NOT NULL;
+
+-- Images attached to a draft, copied into the media directory when it's
+-- saved so they're still there when it's published
+CREATE TABLE IF NOT EXISTS draft_media (
+    draft_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    file_path TEXT NOT NULL,
+    PRIMARY KEY (draft_id, position),
+    FOREIGN KEY (draft_id) REFERENCES drafts(id) ON DELETE CASCADE
+);
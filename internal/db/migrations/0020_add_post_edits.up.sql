@@ -0,0 +1,13 @@
+-- Post editing. A post holds its current text and when it was last edited;
+-- post_edits keeps every earlier version, numbered from 1 for the text it
+-- was posted with, and when each was written.
+ALTER TABLE posts ADD COLUMN edited_at INTEGER;
+
+CREATE TABLE IF NOT EXISTS post_edits (
+    post_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (post_id, version),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
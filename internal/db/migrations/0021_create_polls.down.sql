@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_poll_votes_option;
+DROP TABLE IF EXISTS poll_votes;
+DROP TABLE IF EXISTS poll_options;
+DROP INDEX IF EXISTS idx_polls_closing;
+DROP TABLE IF EXISTS polls;
@@ -0,0 +1,31 @@
+-- Polls attached to posts. Options are numbered from 1 in the order they
+-- were given, and each user votes once. notified is set once the author
+-- has been told the poll closed.
+CREATE TABLE IF NOT EXISTS polls (
+    post_id TEXT PRIMARY KEY,
+    closes_at INTEGER NOT NULL,
+    notified INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_closing ON polls(closes_at) WHERE notified = 0;
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    post_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    PRIMARY KEY (post_id, position),
+    FOREIGN KEY (post_id) REFERENCES polls(post_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    post_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (post_id, user_id),
+    FOREIGN KEY (post_id, position) REFERENCES poll_options(post_id, position) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_option ON poll_votes(post_id, position);
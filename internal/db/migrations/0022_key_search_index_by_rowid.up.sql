@@ -0,0 +1,107 @@
+-- Key search index rows by a rowid of their own, so the triggers update and
+-- delete them by rowid instead of scanning the whole index for a post_id or
+-- message_id. posts and messages have TEXT primary keys, so their rowids
+-- are implicit and VACUUM may renumber them; the index can't use those.
+-- Instead posts_fts_keys and messages_fts_keys give each indexed post and
+-- message an INTEGER PRIMARY KEY, which VACUUM keeps, to use as its rowid
+-- in the index. post_id and message_id stay in the index for joining
+-- results back to their rows.
+--
+-- This drops and rebuilds the index from scratch; `twt db reindex` runs it
+-- again to repair the index.
+DROP TRIGGER IF EXISTS messages_fts_delete;
+DROP TRIGGER IF EXISTS messages_fts_update;
+DROP TRIGGER IF EXISTS messages_fts_insert;
+DROP TRIGGER IF EXISTS posts_fts_delete;
+DROP TRIGGER IF EXISTS posts_fts_update;
+DROP TRIGGER IF EXISTS posts_fts_insert;
+DROP TABLE IF EXISTS messages_fts;
+DROP TABLE IF EXISTS posts_fts;
+DROP TABLE IF EXISTS messages_fts_keys;
+DROP TABLE IF EXISTS posts_fts_keys;
+
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+    post_id UNINDEXED,
+    text,
+    tokenize='porter unicode61',
+    prefix='2 3'
+);
+
+CREATE VIRTUAL TABLE messages_fts USING fts5(
+    message_id UNINDEXED,
+    text,
+    tokenize='porter unicode61',
+    prefix='2 3'
+);
+
+CREATE TABLE posts_fts_keys (
+    fts_rowid INTEGER PRIMARY KEY,
+    post_id TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE messages_fts_keys (
+    fts_rowid INTEGER PRIMARY KEY,
+    message_id TEXT NOT NULL UNIQUE
+);
+
+-- Retweets have no text of their own, so only original posts are indexed
+INSERT INTO posts_fts_keys (post_id)
+SELECT id FROM posts WHERE is_retweet = 0 ORDER BY created_at, id;
+
+INSERT INTO posts_fts (rowid, post_id, text)
+SELECT k.fts_rowid, p.id, p.text
+FROM posts_fts_keys k
+JOIN posts p ON p.id = k.post_id;
+
+INSERT INTO messages_fts_keys (message_id)
+SELECT id FROM messages ORDER BY created_at, id;
+
+INSERT INTO messages_fts (rowid, message_id, text)
+SELECT k.fts_rowid, m.id, m.text
+FROM messages_fts_keys k
+JOIN messages m ON m.id = k.message_id;
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts
+WHEN new.is_retweet = 0
+BEGIN
+    INSERT INTO posts_fts_keys (post_id) VALUES (new.id);
+    INSERT INTO posts_fts (rowid, post_id, text)
+    SELECT fts_rowid, new.id, new.text FROM posts_fts_keys WHERE post_id = new.id;
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF text ON posts
+BEGIN
+    DELETE FROM posts_fts
+    WHERE rowid = (SELECT fts_rowid FROM posts_fts_keys WHERE post_id = old.id);
+    INSERT INTO posts_fts (rowid, post_id, text)
+    SELECT fts_rowid, new.id, new.text FROM posts_fts_keys WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts
+BEGIN
+    DELETE FROM posts_fts
+    WHERE rowid = (SELECT fts_rowid FROM posts_fts_keys WHERE post_id = old.id);
+    DELETE FROM posts_fts_keys WHERE post_id = old.id;
+END;
+
+CREATE TRIGGER messages_fts_insert AFTER INSERT ON messages
+BEGIN
+    INSERT INTO messages_fts_keys (message_id) VALUES (new.id);
+    INSERT INTO messages_fts (rowid, message_id, text)
+    SELECT fts_rowid, new.id, new.text FROM messages_fts_keys WHERE message_id = new.id;
+END;
+
+CREATE TRIGGER messages_fts_update AFTER UPDATE OF text ON messages
+BEGIN
+    DELETE FROM messages_fts
+    WHERE rowid = (SELECT fts_rowid FROM messages_fts_keys WHERE message_id = old.id);
+    INSERT INTO messages_fts (rowid, message_id, text)
+    SELECT fts_rowid, new.id, new.text FROM messages_fts_keys WHERE message_id = old.id;
+END;
+
+CREATE TRIGGER messages_fts_delete AFTER DELETE ON messages
+BEGIN
+    DELETE FROM messages_fts
+    WHERE rowid = (SELECT fts_rowid FROM messages_fts_keys WHERE message_id = old.id);
+    DELETE FROM messages_fts_keys WHERE message_id = old.id;
+END;
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// searchIndexVersion is the migration that creates the current layout of
+// the search index. Rebuilding the index runs it again.
+const searchIndexVersion = 22
+
+// ErrNoFullTextSearch is returned when rebuilding the search index in a
+// build without FTS5, which has no index
+var ErrNoFullTextSearch = errors.New("built without FTS5, so there is no search index (build with -tags sqlite_fts5)")
+
+// RebuildSearchIndex re-indexes every post and message from scratch. The
+// triggers keep the index current, so this is only needed to repair it.
+// It returns how many posts and messages were indexed.
+func RebuildSearchIndex(db *sql.DB) (posts, messages int64, err error) {
+	if !FullTextSearch {
+		return 0, 0, ErrNoFullTextSearch
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var script string
+	for _, m := range migrations {
+		if m.Version == searchIndexVersion {
+			script = m.Up
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The migration drops the index and builds it again from every row
+	if _, err := tx.Exec(script); err != nil {
+		return 0, 0, fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+
+	steps := []struct {
+		query string
+		count *int64
+	}{
+		{`SELECT COUNT(*) FROM posts_fts`, &posts},
+		{`SELECT COUNT(*) FROM messages_fts`, &messages},
+	}
+	for _, step := range steps {
+		if err := tx.QueryRow(step.query).Scan(step.count); err != nil {
+			return 0, 0, fmt.Errorf("failed to count indexed rows: %w", err)
+		}
+	}
+
+	for _, query := range []string{
+		`INSERT INTO posts_fts (posts_fts) VALUES ('optimize')`,
+		`INSERT INTO messages_fts (messages_fts) VALUES ('optimize')`,
+	} {
+		if _, err := tx.Exec(query); err != nil {
+			return 0, 0, fmt.Errorf("failed to optimize search index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+
+	return posts, messages, nil
+}
+
+// checkSearchIndex makes sure the search index fits this build. A build
+// with FTS5 builds the index if it's missing, e.g. because a build without
+// FTS5 migrated the database. A build without FTS5 can't write to a
+// database that has one, since the index's triggers need FTS5.
+func checkSearchIndex(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE name IN ('posts_fts', 'messages_fts')`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to look up search index: %w", err)
+	}
+
+	switch {
+	case FullTextSearch && count != 2:
+		_, _, err := RebuildSearchIndex(db)
+		return err
+	case !FullTextSearch && count > 0:
+		return errors.New("database has a full-text search index, which needs a twt built with -tags sqlite_fts5")
+	}
+
+	return nil
+}
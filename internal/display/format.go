@@ -5,16 +5,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
 	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
 	"github.com/RazinShafayet2007/twitter-cli/internal/store"
 	"github.com/fatih/color"
 )
 
 var (
+	bold   = color.New(color.Bold, color.FgYellow).SprintFunc()
 	cyan   = color.New(color.FgCyan).SprintFunc()
 	green  = color.New(color.FgGreen).SprintFunc()
 	yellow = color.New(color.FgYellow).SprintFunc()
 	gray   = color.New(color.FgHiBlack).SprintFunc()
+	red    = color.New(color.FgRed).SprintFunc()
 )
 
 // FormatTimeAgo formats a Unix timestamp as "2m ago", "1h ago", etc.
@@ -47,17 +50,21 @@ func FormatPost(pwa store.PostWithAuthor) string {
 	var lines []string
 
 	// First line: ID, username, time
-	header := fmt.Sprintf("%s  @%s  %s", pwa.Post.ID, pwa.Username, timeAgo)
+	header := fmt.Sprintf("%s  @%s  %s%s", pwa.Post.ID, pwa.Username, timeAgo, Edited(pwa.Post))
 
 	// If it's a retweet, show that
 	if pwa.Post.IsRetweet {
 		lines = append(lines, header)
-		lines = append(lines, "↻ Retweeted")
+		lines = append(lines, retweeted(pwa))
 		lines = append(lines, parser.HighlightText(pwa.Post.Text)) // Highlight hashtags/mentions
 	} else {
 		lines = append(lines, header)
 		lines = append(lines, parser.HighlightText(pwa.Post.Text)) // Highlight hashtags/mentions
 	}
+	lines = append(lines, formatQuoted(pwa.Quoted)...)
+	if pwa.Poll != nil {
+		lines = append(lines, pollSummary(pwa.Poll))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -85,23 +92,27 @@ func FormatPostWithStats(pwa store.PostWithAuthor, likeCount, retweetCount int)
 	var lines []string
 
 	// Header with colors
-	lines = append(lines, fmt.Sprintf("%s  %s  %s",
+	lines = append(lines, fmt.Sprintf("%s  %s  %s%s",
 		gray(pwa.Post.ID),
 		cyan("@"+pwa.Username),
-		yellow(timeAgo)))
+		yellow(timeAgo),
+		gray(Edited(pwa.Post))))
 
 	// Retweet indicator
 	if pwa.Post.IsRetweet {
-		lines = append(lines, gray("↻ Retweeted"))
+		lines = append(lines, gray(retweeted(pwa)))
 	}
 
 	// Post text
 	lines = append(lines, pwa.Post.Text)
+	lines = append(lines, formatQuoted(pwa.Quoted)...)
+	lines = append(lines, pollLines(pwa.Poll)...)
 
 	// Engagement stats with colors
-	stats := fmt.Sprintf("%s %d  %s %d",
+	stats := fmt.Sprintf("%s %d  %s %d  %s %d",
 		green("❤"), likeCount,
-		cyan("↻"), retweetCount)
+		cyan("↻"), retweetCount,
+		yellow("↩"), pwa.Post.ReplyCount)
 	lines = append(lines, stats)
 
 	return strings.Join(lines, "\n")
@@ -113,16 +124,20 @@ func FormatPostWithMedia(pwa store.PostWithAuthor, mediaCount int) string {
 
 	var lines []string
 
-	header := fmt.Sprintf("%s  @%s  %s", pwa.Post.ID, pwa.Username, timeAgo)
+	header := fmt.Sprintf("%s  @%s  %s%s", pwa.Post.ID, pwa.Username, timeAgo, Edited(pwa.Post))
 
 	if pwa.Post.IsRetweet {
 		lines = append(lines, header)
-		lines = append(lines, "↻ Retweeted")
+		lines = append(lines, retweeted(pwa))
 		lines = append(lines, parser.HighlightText(pwa.Post.Text))
 	} else {
 		lines = append(lines, header)
 		lines = append(lines, parser.HighlightText(pwa.Post.Text))
 	}
+	lines = append(lines, formatQuoted(pwa.Quoted)...)
+	if pwa.Poll != nil {
+		lines = append(lines, pollSummary(pwa.Poll))
+	}
 
 	// Add media indicator
 	if mediaCount > 0 {
@@ -131,3 +146,350 @@ func FormatPostWithMedia(pwa store.PostWithAuthor, mediaCount int) string {
 
 	return strings.Join(lines, "\n")
 }
+
+// Edited marks a post whose text has been edited, to follow its header
+func Edited(p models.Post) string {
+	if p.EditedAt == nil {
+		return ""
+	}
+	return "  (edited)"
+}
+
+// FormatHistory lists the versions of a post's text, oldest first, each
+// after the first shown as a word diff against the one before: removed
+// words as [-word-] and added ones as {+word+}
+func FormatHistory(versions []models.PostVersion) string {
+	var lines []string
+	for i, v := range versions {
+		header := fmt.Sprintf("Version %d  %s", v.Version, time.Unix(v.CreatedAt, 0).Format("Jan 2, 2006 15:04"))
+		if i == len(versions)-1 {
+			header += "  (current)"
+		}
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, yellow(header))
+
+		if i == 0 {
+			lines = append(lines, v.Text)
+		} else {
+			lines = append(lines, diffWords(versions[i-1].Text, v.Text))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// diffWords marks the words removed from before and added to make after,
+// keeping the longest run of words they share in common
+func diffWords(before, after string) string {
+	a, b := strings.Fields(before), strings.Fields(after)
+
+	// common[i][j] is the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var words []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			words = append(words, a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			words = append(words, red("[-"+a[i]+"-]"))
+			i++
+		default:
+			words = append(words, green("{+"+b[j]+"+}"))
+			j++
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// FormatThread draws a conversation as a tree: the posts the thread's post
+// replies to, then the post itself, marked with 👉, with its replies
+// indented under it. Each post shows its like, retweet and reply counts.
+// At most maxReplies replies are drawn under each post (0 draws them all);
+// the rest, and any replies deeper than the thread was read, are summed up
+// in one line.
+func FormatThread(t *store.Thread, maxReplies int) string {
+	var lines []string
+	for _, pwa := range t.Ancestors {
+		lines = append(lines, threadPost(pwa, false)...)
+		lines = append(lines, gray("│"))
+	}
+
+	lines = append(lines, threadPost(t.Post.PostWithAuthor, true)...)
+	lines = append(lines, threadReplies(t.Post, "", maxReplies)...)
+
+	return strings.Join(lines, "\n")
+}
+
+// threadPost draws one post of a thread with its counts
+func threadPost(pwa store.PostWithAuthor, focused bool) []string {
+	lines := strings.Split(FormatPostWithMedia(pwa, pwa.Post.MediaCount), "\n")
+	if focused {
+		lines[0] = "👉 " + bold(lines[0])
+	}
+
+	stats := fmt.Sprintf("%s %d  %s %d  %s %d",
+		green("❤"), pwa.Post.LikeCount,
+		cyan("↻"), pwa.Post.RetweetCount,
+		yellow("↩"), pwa.Post.ReplyCount)
+	return append(lines, stats)
+}
+
+// threadReplies draws the replies under a post, each line starting with
+// indent
+func threadReplies(node *store.ThreadNode, indent string, maxReplies int) []string {
+	if node.MoreReplies > 0 {
+		return []string{indent + gray(fmt.Sprintf("└─ … %s (twt thread %s)", moreReplies(node.MoreReplies), node.Post.ID))}
+	}
+
+	shown, hidden := node.Replies, 0
+	if maxReplies > 0 && len(shown) > maxReplies {
+		shown, hidden = shown[:maxReplies], len(shown)-maxReplies
+	}
+
+	var lines []string
+	for i, reply := range shown {
+		branch, stem := "├─ ", "│  "
+		if i == len(shown)-1 && hidden == 0 {
+			branch, stem = "└─ ", "   "
+		}
+
+		for j, line := range threadPost(reply.PostWithAuthor, false) {
+			if j == 0 {
+				lines = append(lines, indent+gray(branch)+line)
+			} else {
+				lines = append(lines, indent+gray(stem)+line)
+			}
+		}
+		lines = append(lines, threadReplies(reply, indent+gray(stem), maxReplies)...)
+	}
+
+	if hidden > 0 {
+		lines = append(lines, indent+gray("└─ … "+moreReplies(hidden)))
+	}
+
+	return lines
+}
+
+// moreReplies counts replies left out of a thread in words
+func moreReplies(n int) string {
+	if n == 1 {
+		return "1 more reply"
+	}
+	return fmt.Sprintf("%d more replies", n)
+}
+
+// retweeted describes what a retweet retweets, as the line under its header
+func retweeted(pwa store.PostWithAuthor) string {
+	if pwa.Original == nil {
+		return "↻ Retweeted [deleted post]"
+	}
+	return fmt.Sprintf("↻ Retweeted @%s's post %s", pwa.Original.Username, pwa.Original.Post.ID)
+}
+
+// formatQuoted renders a quoted post nested under the post quoting it, or
+// nothing if it doesn't quote one
+func formatQuoted(q *store.QuotedPost) []string {
+	if q == nil {
+		return nil
+	}
+
+	bar := gray("  ┃ ")
+	if q.Deleted {
+		return []string{bar + gray("[deleted post]")}
+	}
+
+	lines := []string{bar + fmt.Sprintf("%s  %s  %s", gray(q.ID), cyan("@"+q.Username), FormatTimeAgo(q.CreatedAt))}
+	for _, line := range strings.Split(q.Text, "\n") {
+		lines = append(lines, bar+parser.HighlightText(line))
+	}
+	return lines
+}
+
+// pollBarWidth is how many cells wide a poll option's bar is at 100%
+const pollBarWidth = 20
+
+// FormatPoll draws a poll's options. Once the results are shown each
+// option gets a bar with its share of the votes and the viewer's choice is
+// ticked; until then the options are numbered for twt vote.
+func FormatPoll(poll *models.Poll) string {
+	return strings.Join(pollLines(poll), "\n")
+}
+
+// pollLines draws a poll as FormatPoll does, a line at a time, or nothing
+// if there's no poll
+func pollLines(poll *models.Poll) []string {
+	if poll == nil {
+		return nil
+	}
+
+	width := 0
+	for _, option := range poll.Options {
+		width = max(width, len([]rune(option.Text)))
+	}
+
+	var lines []string
+	for _, option := range poll.Options {
+		label := fmt.Sprintf("  %d. %s", option.Position, option.Text)
+		if option.Votes == nil {
+			lines = append(lines, label)
+			continue
+		}
+		label += strings.Repeat(" ", width-len([]rune(option.Text)))
+
+		percent := 0
+		if poll.TotalVotes > 0 {
+			percent = *option.Votes * 100 / poll.TotalVotes
+		}
+		filled := percent * pollBarWidth / 100
+		bar := cyan(strings.Repeat("█", filled)) + gray(strings.Repeat("░", pollBarWidth-filled))
+
+		line := fmt.Sprintf("%s  %s %3d%%", label, bar, percent)
+		if poll.Voted != nil && *poll.Voted == option.Position {
+			line += " " + green("✓")
+		}
+		lines = append(lines, line)
+	}
+
+	footer := pollStatus(poll)
+	if !poll.ShowsResults() {
+		footer += fmt.Sprintf(" · vote with twt vote %s <option>", poll.PostID)
+	}
+	return append(lines, gray("  "+footer))
+}
+
+// pollSummary describes a poll in one line, for lists of posts
+func pollSummary(poll *models.Poll) string {
+	return fmt.Sprintf("📊 Poll, %d options · %s", len(poll.Options), pollStatus(poll))
+}
+
+// pollStatus says how many have voted in a poll and how long it has left,
+// as in "12 votes · 3h left"
+func pollStatus(poll *models.Poll) string {
+	votes := fmt.Sprintf("%d votes", poll.TotalVotes)
+	if poll.TotalVotes == 1 {
+		votes = "1 vote"
+	}
+
+	if poll.Closed {
+		return votes + " · final results"
+	}
+
+	left := time.Until(time.Unix(poll.ClosesAt, 0))
+	switch {
+	case left < time.Minute:
+		return votes + " · closing"
+	case left < time.Hour:
+		return fmt.Sprintf("%s · %dm left", votes, int(left.Minutes()))
+	case left < 24*time.Hour:
+		return fmt.Sprintf("%s · %dh left", votes, int(left.Hours()))
+	default:
+		return fmt.Sprintf("%s · %dd left", votes, int(left.Hours()/24))
+	}
+}
+
+// FormatSnippet colors the words a search matched in a result snippet
+func FormatSnippet(snippet string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, store.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], store.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(snippet[:start])
+		b.WriteString(bold(snippet[start+len(store.HighlightStart) : end]))
+		snippet = snippet[end+len(store.HighlightEnd):]
+	}
+	b.WriteString(snippet)
+
+	return b.String()
+}
+
+// FormatSearchResult formats a matching post with its highlighted snippet
+func FormatSearchResult(r store.PostSearchResult) string {
+	header := fmt.Sprintf("%s  @%s  %s%s", r.Post.ID, r.Username, FormatTimeAgo(r.Post.CreatedAt), Edited(r.Post))
+	return header + "\n" + FormatSnippet(r.Snippet)
+}
+
+// FormatNotificationGroup describes a notification group in one line, such
+// as `@alice and 4 others liked your post: "Hello world"`
+func FormatNotificationGroup(g models.NotificationGroup) string {
+	var actors string
+	switch len(g.Actors) {
+	case 0:
+		actors = "Someone"
+	case 1:
+		actors = "@" + g.Actors[0]
+	case 2:
+		actors = fmt.Sprintf("@%s and @%s", g.Actors[0], g.Actors[1])
+	default:
+		actors = fmt.Sprintf("@%s and %d others", g.Actors[0], len(g.Actors)-1)
+	}
+
+	// Polls close by themselves, so there's no one to name
+	if g.Type == "poll" {
+		actors = "Your"
+	}
+
+	var action string
+	switch g.Type {
+	case "like":
+		action = "liked your post"
+	case "retweet":
+		action = "retweeted your post"
+	case "follow":
+		action = "followed you"
+	case "message":
+		action = "sent you a message"
+	case "mention":
+		action = "mentioned you in a post"
+	case "reply":
+		action = "replied to your post"
+	case "quote":
+		action = "quoted your post"
+	case "poll":
+		action = "poll has closed"
+	default:
+		action = "performed an action"
+	}
+
+	message := actors + " " + action
+	if g.TargetText != nil {
+		message += fmt.Sprintf(": \"%s\"", truncate(*g.TargetText, 30))
+	}
+
+	return message
+}
+
+// truncate shortens text to at most maxLen characters, ending with ...
+func truncate(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
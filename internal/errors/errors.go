@@ -37,4 +37,9 @@ func NotLikedError(postID string) error {
 	return fmt.Errorf("not liked post %s. Nothing to unlike", postID)
 }
 
+// SessionExpiredError returns an error for a saved session that is no longer valid
+func SessionExpiredError() error {
+	return fmt.Errorf("session expired. Run: twt login <username>")
+}
+
 // CannotFollowSelfError returns cannot follow yourself error
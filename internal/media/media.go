@@ -143,9 +143,10 @@ func CopyImageToMedia(sourcePath, postID string, position int) (string, string,
 	}
 	defer dest.Close()
 
-	// Copy file
-	_, err = io.Copy(dest, source)
-	if err != nil {
+	// Copy file, leaving nothing behind if it fails part way
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		os.Remove(destPath)
 		return "", "", fmt.Errorf("failed to copy file: %w", err)
 	}
 
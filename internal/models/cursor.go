@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCursor is returned for a cursor that wasn't produced by Cursor.String
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a list ordered newest first: the creation time
+// and ID of an item in it. IDs are ULIDs, so they break ties between items
+// created in the same second.
+type Cursor struct {
+	CreatedAt int64
+	ID        string
+}
+
+// String encodes the cursor as an opaque token for --before and --after
+func (c Cursor) String() string {
+	raw := strconv.FormatInt(c.CreatedAt, 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a token made by Cursor.String
+func ParseCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{ID: id}
+	if c.CreatedAt, err = strconv.ParseInt(createdAt, 10, 64); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Cursor returns the message's position in a list of messages
+func (m MessageWithUser) Cursor() Cursor {
+	return Cursor{CreatedAt: m.Message.CreatedAt, ID: m.Message.ID}
+}
+
+// Cursor returns the notification's position in a list of notifications
+func (n NotificationWithDetails) Cursor() Cursor {
+	return Cursor{CreatedAt: n.Notification.CreatedAt, ID: n.Notification.ID}
+}
+
+// Cursor returns the group's position in a list of groups, which is that
+// of its newest notification
+func (g NotificationGroup) Cursor() Cursor {
+	return Cursor{CreatedAt: g.CreatedAt, ID: g.LatestID}
+}
+
+// Cursor returns the conversation's position in a list of conversations,
+// which is that of its latest message
+func (c Conversation) Cursor() Cursor {
+	return Cursor{CreatedAt: c.LastMessageAt, ID: c.LastMessageID}
+}
+
+// Cursor returns the mute's position in a list of mutes. Mutes have no ID,
+// so what's muted stands in for one.
+func (m Mute) Cursor() Cursor {
+	return Cursor{CreatedAt: m.CreatedAt, ID: m.Kind + ":" + m.Value}
+}
+
+// Cursor returns the draft's position in a list of drafts
+func (d Draft) Cursor() Cursor {
+	return Cursor{CreatedAt: d.CreatedAt, ID: d.ID}
+}
@@ -0,0 +1,14 @@
+package models
+
+// Draft is a post saved to publish later, by its author or, once PublishAt
+// passes, by the scheduler
+type Draft struct {
+	ID        string   `json:"id"`
+	UserID    string   `json:"user_id"`
+	Text      string   `json:"text"`
+	Images    []string `json:"images"`     // Copies in the media directory, in order
+	PublishAt *int64   `json:"publish_at"` // NULL for a draft that isn't scheduled
+	Error     *string  `json:"error"`      // Why the scheduler last failed to publish it
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+}
@@ -1,14 +1,14 @@
 package models
 
 type Media struct {
-	ID        string
-	PostID    string
-	FilePath  string
-	FileName  string
-	FileType  string // "image/jpeg", "image/png", "image/gif"
-	FileSize  int64
-	Width     *int
-	Height    *int
-	Position  int // 0, 1, 2, 3 for multiple images
-	CreatedAt int64
-}
\ No newline at end of file
+	ID        string `json:"id"`
+	PostID    string `json:"post_id"`
+	FilePath  string `json:"file_path"`
+	FileName  string `json:"file_name"`
+	FileType  string `json:"file_type"` // "image/jpeg", "image/png", "image/gif"
+	FileSize  int64  `json:"file_size"`
+	Width     *int   `json:"width"`
+	Height    *int   `json:"height"`
+	Position  int    `json:"position"` // 0, 1, 2, 3 for multiple images
+	CreatedAt int64  `json:"created_at"`
+}
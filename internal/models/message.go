@@ -1,26 +1,27 @@
 package models
 
 type Message struct {
-	ID         string
-	SenderID   string
-	ReceiverID string
-	Text       string
-	CreatedAt  int64
-	Read       bool
+	ID         string `json:"id"`
+	SenderID   string `json:"sender_id"`
+	ReceiverID string `json:"receiver_id"`
+	Text       string `json:"text"`
+	CreatedAt  int64  `json:"created_at"`
+	Read       bool   `json:"read"`
 }
 
 // MessageWithUser represents a message with sender/receiver info
 type MessageWithUser struct {
-	Message      Message
-	SenderName   string
-	ReceiverName string
+	Message      Message `json:"message"`
+	SenderName   string  `json:"sender_name"`
+	ReceiverName string  `json:"receiver_name,omitempty"`
 }
 
 // Conversation represents a message thread between two users
 type Conversation struct {
-	OtherUserID   string
-	OtherUsername string
-	LastMessage   string
-	LastMessageAt int64
-	UnreadCount   int
+	OtherUserID   string `json:"other_user_id"`
+	OtherUsername string `json:"other_username"`
+	LastMessage   string `json:"last_message"`
+	LastMessageAt int64  `json:"last_message_at"`
+	LastMessageID string `json:"last_message_id"`
+	UnreadCount   int    `json:"unread_count"`
 }
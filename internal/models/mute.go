@@ -0,0 +1,18 @@
+package models
+
+// Kinds of mute
+const (
+	MuteUser    = "user"
+	MuteWord    = "word"
+	MuteHashtag = "hashtag"
+)
+
+// Mute hides a user, word or hashtag from one user's feed, hashtag pages,
+// search results and notifications
+type Mute struct {
+	UserID    string `json:"user_id"` // Who muted
+	Kind      string `json:"kind"`    // "user", "word" or "hashtag"
+	Value     string `json:"value"`   // Muted username, word or tag
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt *int64 `json:"expires_at"` // NULL until unmuted
+}
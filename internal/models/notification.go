@@ -1,18 +1,76 @@
 package models
 
+import (
+	"fmt"
+	"time"
+)
+
 type Notification struct {
-	ID        string
-	UserID    string  // Who receives the notification
-	ActorID   string  // Who performed the action
-	Type      string  // "like", "retweet", "follow", "message"
-	TargetID  *string // Post ID, message ID, etc. (can be NULL)
-	CreatedAt int64
-	Read      bool
+	ID        string  `json:"id"`
+	UserID    string  `json:"user_id"`   // Who receives the notification
+	ActorID   string  `json:"actor_id"`  // Who performed the action
+	Type      string  `json:"type"`      // "like", "retweet", "follow", "message", "mention", "reply", "quote", "poll"
+	TargetID  *string `json:"target_id"` // Post ID, message ID, etc. (can be NULL)
+	CreatedAt int64   `json:"created_at"`
+	Read      bool    `json:"read"`
 }
 
 // NotificationWithDetails includes actor username and target details
 type NotificationWithDetails struct {
-	Notification Notification
-	ActorName    string
-	TargetText   *string // Text of the post/message if applicable
+	Notification Notification `json:"notification"`
+	ActorName    string       `json:"actor_name"`
+	TargetText   *string      `json:"target_text"` // Text of the post/message if applicable
+}
+
+// NotificationGroup is one entry in the notification list: a single
+// notification, or likes, retweets or follows grouped together, such as
+// "@alice and 4 others liked your post"
+type NotificationGroup struct {
+	Type       string   `json:"type"`
+	TargetID   *string  `json:"target_id"`
+	TargetText *string  `json:"target_text"`
+	Actors     []string `json:"actors"` // Usernames, most recent first
+	Count      int      `json:"count"`  // Number of notifications in the group
+	Read       bool     `json:"read"`
+	CreatedAt  int64    `json:"created_at"` // When the newest one arrived
+	LatestID   string   `json:"latest_id"`  // ID of the newest one
+}
+
+// NotificationTypes lists every type of notification
+var NotificationTypes = []string{"like", "retweet", "follow", "message", "mention", "reply", "quote", "poll"}
+
+// NotificationRule says which notifications of one type a user wants
+type NotificationRule struct {
+	Type          string `json:"type"`
+	Enabled       bool   `json:"enabled"`
+	OnlyFollowing bool   `json:"only_following"` // Only from users they follow
+	MinLikes      int    `json:"min_likes"`      // Only once the post has this many likes
+}
+
+// QuietHours is a daily window when no notifications are created. Start
+// and End are minutes after local midnight; a window with Start after End
+// wraps past midnight, such as 22:00-07:00.
+type QuietHours struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Contains reports whether t falls inside the window
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// NotificationSettings are a user's rule for every notification type and
+// their quiet hours, if any
+type NotificationSettings struct {
+	Rules      []NotificationRule `json:"rules"`
+	QuietHours *QuietHours        `json:"quiet_hours"`
 }
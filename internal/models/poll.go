@@ -0,0 +1,25 @@
+package models
+
+// Poll is a poll attached to a post. Until it closes, how many votes each
+// option has is only shown to users who have voted.
+type Poll struct {
+	PostID     string       `json:"post_id"`
+	Options    []PollOption `json:"options"`
+	ClosesAt   int64        `json:"closes_at"`
+	Closed     bool         `json:"closed"`
+	TotalVotes int          `json:"total_votes"`
+	Voted      *int         `json:"voted"` // Option the viewer voted for, if they have
+}
+
+// PollOption is one of a poll's choices
+type PollOption struct {
+	Position int    `json:"position"` // From 1, in the order the options were given
+	Text     string `json:"text"`
+	Votes    *int   `json:"votes"` // NULL while the results are hidden
+}
+
+// ShowsResults reports whether the poll's results are shown: once it has
+// closed, or to a viewer who has voted
+func (p Poll) ShowsResults() bool {
+	return p.Closed || p.Voted != nil
+}
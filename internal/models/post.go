@@ -1,11 +1,29 @@
 package models
 
 type Post struct {
-	ID             string
-	AuthorID       string
-	Text           string
-	CreatedAt      int64
-	IsRetweet      bool
-	OriginalPostID *string // pointer because it can be NULL
-	ParentPostID   *string // pointer because it can be NULL
+	ID             string  `json:"id"`
+	AuthorID       string  `json:"author_id"`
+	Text           string  `json:"text"`
+	CreatedAt      int64   `json:"created_at"`
+	IsRetweet      bool    `json:"is_retweet"`
+	OriginalPostID *string `json:"original_post_id"` // pointer because it can be NULL
+	ParentPostID   *string `json:"parent_post_id"`   // pointer because it can be NULL
+	QuotedPostID   *string `json:"quoted_post_id"`   // Kept after the quoted post is deleted
+	EditedAt       *int64  `json:"edited_at"`        // NULL unless the text has been edited
+
+	// Counters kept in step with the likes, retweets, replies, media and
+	// quotes they count
+	LikeCount    int `json:"like_count"`
+	RetweetCount int `json:"retweet_count"`
+	ReplyCount   int `json:"reply_count"`
+	MediaCount   int `json:"media_count"`
+	QuoteCount   int `json:"quote_count"`
+}
+
+// PostVersion is one version of a post's text. Version 1 is the text it
+// was posted with.
+type PostVersion struct {
+	Version   int    `json:"version"`
+	Text      string `json:"text"`
+	CreatedAt int64  `json:"created_at"` // When this version was written
 }
@@ -0,0 +1,8 @@
+package models
+
+// Session is a login; the token is only known to the client
+type Session struct {
+	UserID    string `json:"user_id"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+}
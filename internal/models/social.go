@@ -1,13 +1,23 @@
 package models
 
 type Follow struct {
-	FollowerID string
-	FolloweeID string
-	CreatedAt  int64
+	FollowerID string `json:"follower_id"`
+	FolloweeID string `json:"followee_id"`
+	CreatedAt  int64  `json:"created_at"`
 }
 
 type Like struct {
-	UserID    string
-	PostID    string
-	CreatedAt int64
+	UserID    string `json:"user_id"`
+	PostID    string `json:"post_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// UserStats summarizes a user's activity
+type UserStats struct {
+	Username         string `json:"username"`
+	Posts            int    `json:"posts"`
+	Following        int    `json:"following"`
+	Followers        int    `json:"followers"`
+	MessagesSent     int    `json:"messages_sent"`
+	MessagesReceived int    `json:"messages_received"`
 }
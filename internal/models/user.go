@@ -1,7 +1,7 @@
 package models
 
 type User struct {
-	ID        string
-	Username  string
-	CreatedAt int64
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	CreatedAt int64  `json:"created_at"`
 }
@@ -0,0 +1,357 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format is a machine-readable output format
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	JSONL Format = "jsonl"
+	CSV   Format = "csv"
+	YAML  Format = "yaml"
+)
+
+// Formats lists every supported format
+var Formats = []Format{Text, JSON, JSONL, CSV, YAML}
+
+// ParseFormat validates a --output flag value
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, known := range Formats {
+		names[i] = string(known)
+	}
+	return "", fmt.Errorf("unknown output format %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// Page is one page of a list paged by cursor, shaped like the REST API's
+// paged responses. NextCursor is nil on the last page.
+type Page struct {
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// Render writes v in the given format. Field names come from the json tags
+// of the value, so every format uses the same stable names.
+func Render(w io.Writer, format Format, v interface{}) error {
+	v = normalize(v)
+
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case JSONL:
+		return renderJSONL(w, v)
+	case CSV:
+		return renderCSV(w, v)
+	case YAML:
+		return renderYAML(w, v)
+	default:
+		return fmt.Errorf("format %q is not machine-readable", format)
+	}
+}
+
+// normalize turns nil slices into empty ones so lists render as [] not null
+func normalize(v interface{}) interface{} {
+	if p, ok := v.(Page); ok {
+		p.Data = normalize(p.Data)
+		return p
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+	return v
+}
+
+func renderJSONL(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return enc.Encode(v)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field is one key/value pair of a JSON object, kept in declaration order
+type field struct {
+	key   string
+	value interface{}
+}
+
+// object preserves the field order produced by encoding/json
+type object []field
+
+// decodeOrdered round-trips v through JSON, keeping object key order
+func decodeOrdered(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := object{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, field{key: keyTok.(string), value: val})
+			}
+			_, err := dec.Token() // closing }
+			return obj, err
+		case '[':
+			list := []interface{}{}
+			for dec.More() {
+				val, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, val)
+			}
+			_, err := dec.Token() // closing ]
+			return list, err
+		}
+	}
+
+	return tok, nil
+}
+
+func renderCSV(w io.Writer, v interface{}) error {
+	decoded, err := decodeOrdered(v)
+	if err != nil {
+		return err
+	}
+
+	records, ok := decoded.([]interface{})
+	if !ok {
+		records = []interface{}{decoded}
+	}
+
+	// Flatten nested objects into dotted column names
+	var header []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]string, 0, len(records))
+
+	for _, record := range records {
+		row := make(map[string]string)
+		flatten("", record, row, func(key string) {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		})
+		rows = append(rows, row)
+	}
+
+	cw := csv.NewWriter(w)
+	if len(header) > 0 {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		line := make([]string, len(header))
+		for i, key := range header {
+			line[i] = row[key]
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func flatten(prefix string, v interface{}, row map[string]string, addKey func(string)) {
+	if obj, ok := v.(object); ok {
+		for _, f := range obj {
+			key := f.key
+			if prefix != "" {
+				key = prefix + "." + f.key
+			}
+			flatten(key, f.value, row, addKey)
+		}
+		return
+	}
+
+	if prefix == "" {
+		prefix = "value"
+	}
+	addKey(prefix)
+	row[prefix] = scalarString(v)
+}
+
+// scalarString renders a leaf value for CSV; nested lists are kept as JSON
+func scalarString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		data, _ := json.Marshal(toPlain(v))
+		return string(data)
+	}
+}
+
+// toPlain converts ordered objects back into maps for json.Marshal
+func toPlain(v interface{}) interface{} {
+	switch t := v.(type) {
+	case object:
+		m := make(map[string]interface{}, len(t))
+		for _, f := range t {
+			m[f.key] = toPlain(f.value)
+		}
+		return m
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = toPlain(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func renderYAML(w io.Writer, v interface{}) error {
+	decoded, err := decodeOrdered(v)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, decoded, 0)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch t := v.(type) {
+	case object:
+		if len(t) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		for _, f := range t {
+			writeYAMLEntry(buf, pad+yamlKey(f.key)+":", f.value, indent)
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range t {
+			writeYAMLEntry(buf, pad+"-", item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLEntry writes "prefix value", nesting collections on the next lines
+func writeYAMLEntry(buf *bytes.Buffer, prefix string, v interface{}, indent int) {
+	switch t := v.(type) {
+	case object:
+		if len(t) == 0 {
+			buf.WriteString(prefix + " {}\n")
+			return
+		}
+		buf.WriteString(prefix + "\n")
+		writeYAML(buf, t, indent+1)
+	case []interface{}:
+		if len(t) == 0 {
+			buf.WriteString(prefix + " []\n")
+			return
+		}
+		buf.WriteString(prefix + "\n")
+		writeYAML(buf, t, indent+1)
+	default:
+		buf.WriteString(prefix + " " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlKey(key string) string {
+	for _, r := range key {
+		if !(r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return strconv.Quote(key)
+		}
+	}
+	return key
+}
+
+// yamlScalar quotes every string so values like "yes" or "0123" stay strings.
+// JSON string escapes are valid in YAML double-quoted scalars.
+func yamlScalar(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		var quoted bytes.Buffer
+		enc := json.NewEncoder(&quoted)
+		enc.SetEscapeHTML(false)
+		enc.Encode(t)
+		return strings.TrimSuffix(quoted.String(), "\n")
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
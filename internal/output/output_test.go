@@ -0,0 +1,97 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testAuthor struct {
+	Name string `json:"name"`
+}
+
+type testPost struct {
+	ID     string     `json:"id"`
+	Text   string     `json:"text"`
+	Likes  int        `json:"likes"`
+	Author testAuthor `json:"author"`
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"json", "JSON", " yaml ", "csv", "jsonl", "text"} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("expected %q to parse, got %v", s, err)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRender(t *testing.T) {
+	posts := []testPost{
+		{ID: "p1", Text: `say "hi", <b>`, Likes: 2, Author: testAuthor{Name: "alice"}},
+		{ID: "p2", Text: "yes", Likes: 0, Author: testAuthor{Name: "bob"}},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{JSONL, `{"id":"p1","text":"say \"hi\", <b>","likes":2,"author":{"name":"alice"}}` + "\n" +
+			`{"id":"p2","text":"yes","likes":0,"author":{"name":"bob"}}` + "\n"},
+		{CSV, "id,text,likes,author.name\n" +
+			`p1,"say ""hi"", <b>",2,alice` + "\n" +
+			"p2,yes,0,bob\n"},
+		{YAML, "-\n" +
+			"  id: \"p1\"\n  text: \"say \\\"hi\\\", <b>\"\n  likes: 2\n  author:\n    name: \"alice\"\n" +
+			"-\n" +
+			"  id: \"p2\"\n  text: \"yes\"\n  likes: 0\n  author:\n    name: \"bob\"\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, tt.format, posts); err != nil {
+			t.Fatalf("%s: failed to render: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.format, tt.want, buf.String())
+		}
+	}
+}
+
+func TestRender_NilSlice(t *testing.T) {
+	var posts []testPost
+
+	var buf bytes.Buffer
+	if err := Render(&buf, JSON, posts); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("expected empty list, got %q", buf.String())
+	}
+}
+
+func TestRender_Page(t *testing.T) {
+	next := "c1"
+	tests := []struct {
+		format Format
+		page   Page
+		want   string
+	}{
+		{JSON, Page{Data: []testPost(nil), Limit: 20},
+			"{\n  \"data\": [],\n  \"limit\": 20,\n  \"next_cursor\": null\n}\n"},
+		{YAML, Page{Data: []testPost{{ID: "p1", Author: testAuthor{Name: "alice"}}}, Limit: 1, NextCursor: &next},
+			"data:\n  -\n    id: \"p1\"\n    text: \"\"\n    likes: 0\n    author:\n      name: \"alice\"\nlimit: 1\nnext_cursor: \"c1\"\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Render(&buf, tt.format, tt.page); err != nil {
+			t.Fatalf("%s: failed to render: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.format, tt.want, buf.String())
+		}
+	}
+}
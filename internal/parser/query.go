@@ -0,0 +1,288 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ClauseKind says what a search clause matches
+type ClauseKind int
+
+const (
+	ClauseWord     ClauseKind = iota // golang, or gola* for a prefix
+	ClausePhrase                     // "exact phrase"
+	ClauseFrom                       // from:alice, posted by alice
+	ClauseTo                         // to:bob, replies to bob
+	ClauseHashtag                    // #golang
+	ClauseMention                    // @carol
+	ClauseSince                      // since:2026-01-01, on or after the date
+	ClauseUntil                      // until:2026-02-01, before the date
+	ClauseHas                        // has:image
+	ClauseIs                         // is:reply, is:retweet
+	ClauseMinLikes                   // min_likes:5
+)
+
+// Clause is one term of a search query. A post must match every clause,
+// or none of the negated ones.
+type Clause struct {
+	Kind   ClauseKind
+	Negate bool      // Written with a leading -
+	Value  string    // Lowercased word, phrase, username, tag, or has:/is: value
+	Time   time.Time // since: and until:
+	Count  int       // min_likes:
+	Pos    int       // Byte offset of the clause in the query
+	End    int       // Byte offset just past the clause
+}
+
+// Query is a parsed search query
+type Query struct {
+	Clauses []Clause
+}
+
+// HasText reports whether the query has any words or phrases to match
+func (q *Query) HasText() bool {
+	for _, c := range q.Clauses {
+		if c.Kind == ClauseWord || c.Kind == ClausePhrase {
+			return true
+		}
+	}
+	return false
+}
+
+// SyntaxError reports a malformed search query and where the problem is
+type SyntaxError struct {
+	Query string
+	Pos   int // Byte offset of the offending token
+	End   int
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	column := utf8.RuneCountInString(e.Query[:e.Pos]) + 1
+	return fmt.Sprintf("invalid search query at column %d: %s", column, e.Msg)
+}
+
+// Pointer shows the query with the offending token underlined
+func (e *SyntaxError) Pointer() string {
+	indent := strings.Repeat(" ", utf8.RuneCountInString(e.Query[:e.Pos]))
+	width := utf8.RuneCountInString(e.Query[e.Pos:e.End])
+	if width < 1 {
+		width = 1
+	}
+	return "  " + e.Query + "\n  " + indent + strings.Repeat("^", width)
+}
+
+var (
+	nameRe     = regexp.MustCompile(`^\w+$`)
+	operatorRe = regexp.MustCompile(`^[A-Za-z_]+$`)
+)
+
+// ParseQuery parses the search syntax:
+//
+//	golang gola* "exact phrase"   words, prefixes and phrases
+//	from:alice to:bob             author, and replies to a user
+//	#golang @carol                hashtags and mentions
+//	since:2026-01-01 until:...    dates, in local time
+//	has:image is:reply is:retweet min_likes:5
+//
+// Any term can be negated with a leading -, such as -word or -from:bob.
+// is:retweet can't be combined with words, hashtags or mentions.
+func ParseQuery(input string) (*Query, error) {
+	q := &Query{}
+	i := 0
+
+	for {
+		// Skip whitespace
+		for i < len(input) {
+			r, size := utf8.DecodeRuneInString(input[i:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		if i >= len(input) {
+			break
+		}
+
+		start := i
+		negate := false
+		if input[i] == '-' {
+			negate = true
+			i++
+			if i >= len(input) || unicode.IsSpace(rune(input[i])) {
+				return nil, &SyntaxError{Query: input, Pos: start, End: start + 1, Msg: "expected a term after -"}
+			}
+		}
+
+		var token string
+		if input[i] == '"' {
+			end := strings.IndexByte(input[i+1:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{Query: input, Pos: i, End: len(input), Msg: "unterminated quote"}
+			}
+			token = input[i : i+end+2]
+		} else {
+			end := strings.IndexFunc(input[i:], unicode.IsSpace)
+			if end < 0 {
+				end = len(input) - i
+			}
+			token = input[i : i+end]
+		}
+
+		clause, err := parseClause(input, token, i)
+		if err != nil {
+			return nil, err
+		}
+		clause.Negate = negate
+		clause.Pos = start
+		q.Clauses = append(q.Clauses, clause)
+
+		i += len(token)
+	}
+
+	if err := checkRetweetClauses(input, q); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// checkRetweetClauses rejects is:retweet alongside clauses that look at a
+// post's text. Retweets have no text, hashtags or mentions of their own, so
+// such a query could never match.
+func checkRetweetClauses(input string, q *Query) error {
+	var retweet *Clause
+	textual := false
+	for i, c := range q.Clauses {
+		switch c.Kind {
+		case ClauseIs:
+			if c.Value == "retweet" && !c.Negate {
+				retweet = &q.Clauses[i]
+			}
+		case ClauseWord, ClausePhrase, ClauseHashtag, ClauseMention:
+			textual = true
+		}
+	}
+
+	if retweet == nil || !textual {
+		return nil
+	}
+	return &SyntaxError{
+		Query: input,
+		Pos:   retweet.Pos,
+		End:   retweet.End,
+		Msg:   "is:retweet can't be combined with words, hashtags or mentions, since retweets have no text of their own",
+	}
+}
+
+// parseClause parses a single token that starts at pos in input
+func parseClause(input, token string, pos int) (Clause, error) {
+	end := pos + len(token)
+	c := Clause{End: end}
+
+	fail := func(from int, format string, args ...interface{}) (Clause, error) {
+		return Clause{}, &SyntaxError{Query: input, Pos: from, End: end, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case strings.HasPrefix(token, `"`):
+		c.Kind = ClausePhrase
+		c.Value = strings.ToLower(strings.TrimSpace(token[1 : len(token)-1]))
+		if c.Value == "" {
+			return fail(pos, "empty phrase")
+		}
+		return c, nil
+
+	case strings.HasPrefix(token, "#"):
+		c.Kind = ClauseHashtag
+		c.Value = strings.ToLower(token[1:])
+		if !nameRe.MatchString(c.Value) {
+			return fail(pos, "%q is not a valid hashtag", token)
+		}
+		return c, nil
+
+	case strings.HasPrefix(token, "@"):
+		c.Kind = ClauseMention
+		c.Value = strings.ToLower(token[1:])
+		if !nameRe.MatchString(c.Value) {
+			return fail(pos, "%q is not a valid username", token)
+		}
+		return c, nil
+	}
+
+	key, value, found := strings.Cut(token, ":")
+	if !found || !operatorRe.MatchString(key) {
+		c.Kind = ClauseWord
+		c.Value = strings.ToLower(token)
+		if strings.Contains(token, `"`) {
+			return fail(pos, "unexpected quote in %q", token)
+		}
+		if strings.IndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			return fail(pos, "%q has no letters or numbers to search for", token)
+		}
+		return c, nil
+	}
+
+	valuePos := pos + len(key) + 1
+	if value == "" {
+		return fail(pos, "%s: needs a value", key)
+	}
+
+	switch strings.ToLower(key) {
+	case "from", "to":
+		c.Kind = ClauseFrom
+		if strings.EqualFold(key, "to") {
+			c.Kind = ClauseTo
+		}
+		c.Value = strings.ToLower(strings.TrimPrefix(value, "@"))
+		if !nameRe.MatchString(c.Value) {
+			return fail(valuePos, "%q is not a valid username", value)
+		}
+
+	case "since", "until":
+		c.Kind = ClauseSince
+		if strings.EqualFold(key, "until") {
+			c.Kind = ClauseUntil
+		}
+		t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return fail(valuePos, "%q is not a date like 2026-01-31", value)
+		}
+		c.Time = t
+
+	case "has":
+		c.Kind = ClauseHas
+		c.Value = strings.ToLower(value)
+		if c.Value == "images" || c.Value == "media" {
+			c.Value = "image"
+		}
+		if c.Value != "image" {
+			return fail(valuePos, "unknown has: value %q (expected image)", value)
+		}
+
+	case "is":
+		c.Kind = ClauseIs
+		c.Value = strings.ToLower(value)
+		if c.Value != "reply" && c.Value != "retweet" {
+			return fail(valuePos, "unknown is: value %q (expected reply or retweet)", value)
+		}
+
+	case "min_likes":
+		c.Kind = ClauseMinLikes
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fail(valuePos, "%q is not a number of likes", value)
+		}
+		c.Count = n
+
+	default:
+		return fail(pos, "unknown operator %q; put it in quotes to search for it", key+":")
+	}
+
+	return c, nil
+}
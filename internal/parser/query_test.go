@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`from:Alice -#Go "Exact  phrase" gola* -is:reply min_likes:5 since:2026-01-02`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	want := []struct {
+		kind   ClauseKind
+		value  string
+		negate bool
+	}{
+		{ClauseFrom, "alice", false},
+		{ClauseHashtag, "go", true},
+		{ClausePhrase, "exact  phrase", false},
+		{ClauseWord, "gola*", false},
+		{ClauseIs, "reply", true},
+		{ClauseMinLikes, "", false},
+		{ClauseSince, "", false},
+	}
+
+	if len(q.Clauses) != len(want) {
+		t.Fatalf("expected %d clauses, got %+v", len(want), q.Clauses)
+	}
+	for i, w := range want {
+		c := q.Clauses[i]
+		if c.Kind != w.kind || c.Value != w.value || c.Negate != w.negate {
+			t.Errorf("clause %d: expected %+v, got %+v", i, w, c)
+		}
+	}
+
+	if q.Clauses[5].Count != 5 {
+		t.Errorf("expected min_likes 5, got %d", q.Clauses[5].Count)
+	}
+	if got := q.Clauses[6].Time.Format("2006-01-02"); got != "2026-01-02" {
+		t.Errorf("expected since 2026-01-02, got %s", got)
+	}
+	if !q.HasText() {
+		t.Error("expected query to have text")
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	cases := []struct {
+		query   string
+		pointer string
+	}{
+		{`golang form:alice`, "  golang form:alice\n         ^^^^^^^^^^"},
+		{`since:yesterday`, "  since:yesterday\n        ^^^^^^^^^"},
+		{`is:quote`, "  is:quote\n     ^^^^^"},
+		{`min_likes:-1`, "  min_likes:-1\n            ^^"},
+		{`go "unclosed`, "  go \"unclosed\n     ^^^^^^^^^"},
+		{`go -`, "  go -\n     ^"},
+		{`café #`, "  café #\n       ^"},
+		{`golang is:retweet`, "  golang is:retweet\n         ^^^^^^^^^^"},
+		{`is:retweet -#go`, "  is:retweet -#go\n  ^^^^^^^^^^"},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseQuery(tc.query)
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a syntax error, got %v", tc.query, err)
+			continue
+		}
+		if got := syntaxErr.Pointer(); got != tc.pointer {
+			t.Errorf("%q: expected pointer\n%s\ngot\n%s", tc.query, tc.pointer, got)
+		}
+	}
+}
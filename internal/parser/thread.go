@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ThreadBreak is a line on its own that ends one post of a thread and
+// starts the next
+const ThreadBreak = "---"
+
+// SplitThread splits text written as a thread into posts of at most limit
+// bytes. Lines of just ThreadBreak separate posts, and any longer than
+// limit are split again between words. When there's more than one post,
+// each is numbered, as in "1/5", and the numbers fit within limit.
+func SplitThread(text string, limit int) []string {
+	var sections []string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == ThreadBreak {
+			sections = appendSection(sections, current)
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	sections = appendSection(sections, current)
+
+	if len(sections) == 1 && len(sections[0]) <= limit {
+		return sections
+	}
+
+	// Numbers take " 1/5", so the room left depends on how many digits
+	// the count has. Widen it until the count fits.
+	for digits := 1; ; digits++ {
+		room := limit - (2 + 2*digits)
+		var parts []string
+		for _, section := range sections {
+			parts = append(parts, splitWords(section, room)...)
+		}
+
+		if len(parts) == 1 {
+			return parts
+		}
+		if len(fmt.Sprint(len(parts))) > digits {
+			continue
+		}
+
+		for i := range parts {
+			parts[i] += fmt.Sprintf(" %d/%d", i+1, len(parts))
+		}
+		return parts
+	}
+}
+
+// appendSection adds the lines of one section of a thread to sections,
+// unless they're blank
+func appendSection(sections []string, lines []string) []string {
+	if section := strings.TrimSpace(strings.Join(lines, "\n")); section != "" {
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// splitWords splits text into chunks of at most limit bytes, breaking at
+// the last space or newline that fits, or mid-word if a word alone is too
+// long
+func splitWords(text string, limit int) []string {
+	var chunks []string
+	for len(text) > limit {
+		cut := strings.LastIndexFunc(text[:limit+1], unicode.IsSpace)
+		if cut <= 0 {
+			// No space to break at: cut at the last whole rune that fits
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+
+		chunks = append(chunks, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitThread(t *testing.T) {
+	if got := SplitThread("  just one post \n", 280); len(got) != 1 || got[0] != "just one post" {
+		t.Errorf("expected one untouched post, got %q", got)
+	}
+
+	got := SplitThread("first\n---\n\n---\nsecond\nline\n  ---  \nthird", 280)
+	want := []string{"first 1/3", "second\nline 2/3", "third 3/3"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// Long text breaks between words, leaving room for the numbers
+	long := strings.Repeat("word ", 100)
+	got = SplitThread(long, 50)
+	for i, part := range got {
+		if len(part) > 50 {
+			t.Errorf("part %d is %d bytes: %q", i, len(part), part)
+		}
+		words := strings.Fields(part)
+		for _, w := range words[:len(words)-1] {
+			if w != "word" {
+				t.Errorf("part %d breaks a word: %q", i, part)
+			}
+		}
+	}
+	if len(got) != 12 || !strings.HasSuffix(got[11], " 12/12") {
+		t.Errorf("expected 12 numbered parts, got %d: %q", len(got), got)
+	}
+
+	// A word longer than a post is cut between runes
+	got = SplitThread(strings.Repeat("é", 30), 20)
+	for _, part := range got {
+		if len(part) > 20 || !strings.HasPrefix(part, "é") {
+			t.Errorf("expected whole runes within the limit, got %q", part)
+		}
+	}
+}
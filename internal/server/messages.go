@@ -0,0 +1,143 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+type sendMessageRequest struct {
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req sendMessageRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	message, err := s.services.Messages.Send(user.ID, req.To, req.Text)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, message)
+}
+
+func (s *Server) handleInbox(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	messages, err := s.stores.Messages.GetInbox(user.ID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(messages, p))
+}
+
+func (s *Server) handleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parsePage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, newHTTPError(http.StatusBadRequest, "missing q parameter"))
+		return
+	}
+
+	results, err := s.stores.Messages.SearchMessages(user.ID, query, p.Limit+1, p.Offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pageAt(results, p))
+}
+
+func (s *Server) handleDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.services.Messages.Delete(user.ID, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleConversations(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	conversations, err := s.stores.Messages.GetConversations(user.ID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(conversations, p))
+}
+
+func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Reading the first page marks the conversation read, like the CLI does
+	messages, err := s.services.Messages.Conversation(user.ID, r.PathValue("username"), p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(messages, p))
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	unreadOnly := false
+	if v := r.URL.Query().Get("unread"); v != "" {
+		unreadOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, newHTTPError(http.StatusBadRequest, "unread must be true or false"))
+			return
+		}
+	}
+
+	notifications, err := s.stores.Notifications.GetNotifications(user.ID, unreadOnly, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(notifications, p))
+}
+
+func (s *Server) handleNotificationCount(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	count, err := s.stores.Notifications.GetUnreadCount(user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, countResponse{Count: count})
+}
+
+func (s *Server) handleMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.stores.Notifications.MarkAsRead(user.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteNotification(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.stores.Notifications.DeleteNotification(r.PathValue("id"), user.ID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,492 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// mediaResponse adds a download URL to a media record
+type mediaResponse struct {
+	models.Media
+	URL string `json:"url"`
+}
+
+func withURLs(mediaList []models.Media) []mediaResponse {
+	out := make([]mediaResponse, 0, len(mediaList))
+	for _, m := range mediaList {
+		out = append(out, mediaResponse{Media: m, URL: "/media/" + m.FileName})
+	}
+	return out
+}
+
+// postDetailsResponse mirrors store.PostDetails with media URLs
+type postDetailsResponse struct {
+	store.PostWithAuthor
+	LikeCount    int                    `json:"like_count"`
+	RetweetCount int                    `json:"retweet_count"`
+	Media        []mediaResponse        `json:"media"`
+	Quotes       []store.PostWithAuthor `json:"quotes"`
+}
+
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("algo") {
+	case "", "chrono":
+	case "ranked":
+		s.rankedFeed(w, user.ID, p)
+		return
+	default:
+		writeError(w, newHTTPError(http.StatusBadRequest, "algo must be chrono or ranked"))
+		return
+	}
+
+	posts, err := s.services.Feed.Feed(user.ID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(posts, p))
+}
+
+// rankedFeed serves the next posts of a user's ranked feed. It has no
+// cursors: posts are shown once, so the next request returns the next ones.
+func (s *Server) rankedFeed(w http.ResponseWriter, userID string, p store.Page) {
+	if p.Before != nil || p.After != nil {
+		writeError(w, newHTTPError(http.StatusBadRequest, "the ranked feed isn't paged with cursors"))
+		return
+	}
+
+	ranked, err := s.services.Feed.Ranked(userID, p.Limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := cursorPageResponse{Data: ranked, Limit: p.Limit}
+	if ranked == nil {
+		resp.Data = []service.Candidate{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type createPostRequest struct {
+	Text         string       `json:"text"`
+	ParentPostID *string      `json:"parent_post_id"`
+	QuotedPostID *string      `json:"quoted_post_id"`
+	Poll         *pollRequest `json:"poll"`
+}
+
+type pollRequest struct {
+	Options  []string `json:"options"`
+	Duration string   `json:"duration"` // Such as "30m" or "7d"; a day if empty
+}
+
+func (s *Server) handleCreatePost(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req createPostRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	in := service.NewPost{Text: req.Text, ParentID: req.ParentPostID, QuotedID: req.QuotedPostID}
+	if req.Poll != nil {
+		in.Poll = &service.NewPoll{Options: req.Poll.Options}
+		if req.Poll.Duration != "" {
+			if in.Poll.Duration, err = parser.ParseDuration(req.Poll.Duration); err != nil {
+				writeError(w, newHTTPError(http.StatusBadRequest, "%v", err))
+				return
+			}
+		}
+	}
+
+	published, err := s.services.Posts.Publish(user, in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, store.PostWithAuthor{Post: *published.Post, Username: user.Username, Poll: published.Poll})
+}
+
+func (s *Server) handleGetPost(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := s.viewerID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	details, err := s.services.Posts.Details(r.PathValue("id"), viewerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	quotes := details.Quotes
+	if quotes == nil {
+		quotes = []store.PostWithAuthor{}
+	}
+
+	writeJSON(w, http.StatusOK, postDetailsResponse{
+		PostWithAuthor: details.PostWithAuthor,
+		LikeCount:      details.LikeCount,
+		RetweetCount:   details.RetweetCount,
+		Media:          withURLs(details.Media),
+		Quotes:         quotes,
+	})
+}
+
+type editPostRequest struct {
+	Text string `json:"text"`
+}
+
+func (s *Server) handleEditPost(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req editPostRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	edited, err := s.services.Posts.Edit(user, r.PathValue("id"), req.Text)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, store.PostWithAuthor{Post: *edited.Post, Username: user.Username})
+}
+
+type voteRequest struct {
+	Option string `json:"option"` // The option's number, counting from 1, or its text
+}
+
+func (s *Server) handleVote(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var req voteRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	poll, err := s.services.Polls.Vote(user.ID, r.PathValue("id"), req.Option)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, poll)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.services.Posts.History(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, versions)
+}
+
+func (s *Server) handleDeletePost(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := s.services.Posts.Delete(user.ID, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleThread(w http.ResponseWriter, r *http.Request) {
+	postID := r.PathValue("id")
+
+	if _, err := s.stores.Posts.GetByID(postID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	viewerID, err := s.viewerID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	opts := store.ThreadOptions{Sort: store.ThreadSortTop}
+	if v := r.URL.Query().Get("depth"); v != "" {
+		if opts.Depth, err = strconv.Atoi(v); err != nil || opts.Depth < 0 {
+			writeError(w, newHTTPError(http.StatusBadRequest, "depth must be a non-negative integer"))
+			return
+		}
+	}
+	switch v := r.URL.Query().Get("sort"); v {
+	case "":
+	case store.ThreadSortTop, store.ThreadSortNew:
+		opts.Sort = v
+	default:
+		writeError(w, newHTTPError(http.StatusBadRequest, "sort must be top or new"))
+		return
+	}
+
+	thread, err := s.stores.Posts.GetThread(postID, viewerID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, thread)
+}
+
+func (s *Server) handlePostMedia(w http.ResponseWriter, r *http.Request) {
+	postID := r.PathValue("id")
+
+	if _, err := s.stores.Posts.GetByID(postID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	mediaList, err := s.stores.Media.GetByPostID(postID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, withURLs(mediaList))
+}
+
+func (s *Server) handleLikes(w http.ResponseWriter, r *http.Request) {
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	postID := r.PathValue("id")
+	if _, err := s.stores.Posts.GetByID(postID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	users, err := s.stores.Social.GetLikes(postID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(users, p))
+}
+
+func (s *Server) handleLike(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.services.Social.Like(user.ID, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUnlike(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.services.Social.Unlike(user.ID, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRetweet(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	retweet, err := s.services.Posts.Retweet(user.ID, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resolved := []store.PostWithAuthor{{Post: *retweet, Username: user.Username}}
+	if err := s.stores.Posts.Resolve(resolved); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resolved[0])
+}
+
+func (s *Server) handleUnretweet(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.services.Posts.Unretweet(user.ID, r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	p, err := parsePage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, newHTTPError(http.StatusBadRequest, "missing q parameter"))
+		return
+	}
+
+	viewerID, err := s.viewerID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	results, err := s.stores.Posts.Search(query, viewerID, p.Limit+1, p.Offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pageAt(results, p))
+}
+
+func (s *Server) handleHashtagPosts(w http.ResponseWriter, r *http.Request) {
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tag := strings.ToLower(strings.TrimPrefix(r.PathValue("tag"), "#"))
+
+	viewerID, err := s.viewerID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	posts, err := s.stores.Hashtags.GetPostsByHashtag(tag, viewerID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(posts, p))
+}
+
+func (s *Server) handleTrending(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	days := 7
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			writeError(w, newHTTPError(http.StatusBadRequest, "limit must be between 1 and %d", maxPageSize))
+			return
+		}
+		limit = n
+	}
+
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, newHTTPError(http.StatusBadRequest, "days must be a positive integer"))
+			return
+		}
+		days = n
+	}
+
+	since := time.Now().AddDate(0, 0, -days).Unix()
+	trending, err := s.stores.Hashtags.GetTrendingHashtags(limit, since)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if trending == nil {
+		trending = []store.TrendingHashtag{}
+	}
+	writeJSON(w, http.StatusOK, trending)
+}
+
+func (s *Server) handleMentions(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	posts, err := s.stores.Mentions.GetMentions(user.ID, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(posts, p))
+}
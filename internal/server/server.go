@@ -0,0 +1,386 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/auth"
+	"github.com/RazinShafayet2007/twitter-cli/internal/media"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxBodyBytes    = 1 << 20
+)
+
+// Server exposes the services as a versioned JSON HTTP API. Reads go
+// straight to the stores; anything with side effects goes through a service.
+type Server struct {
+	stores   *store.Stores
+	services *service.Services
+}
+
+// New creates a server backed by stores and services
+func New(stores *store.Stores, services *service.Services) *Server {
+	return &Server{stores: stores, services: services}
+}
+
+// Handler returns the HTTP handler with all routes registered
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Users and social graph
+	mux.HandleFunc("POST /api/v1/users", s.handleCreateUser)
+	mux.HandleFunc("POST /api/v1/sessions", s.handleLogin)
+	mux.HandleFunc("DELETE /api/v1/sessions", s.handleLogout)
+	mux.HandleFunc("GET /api/v1/me", s.handleMe)
+	mux.HandleFunc("GET /api/v1/users/{username}", s.handleGetUser)
+	mux.HandleFunc("GET /api/v1/users/{username}/posts", s.handleUserPosts)
+	mux.HandleFunc("GET /api/v1/users/{username}/followers", s.handleFollowers)
+	mux.HandleFunc("GET /api/v1/users/{username}/following", s.handleFollowing)
+	mux.HandleFunc("GET /api/v1/users/{username}/stats", s.handleStats)
+	mux.HandleFunc("POST /api/v1/users/{username}/follow", s.handleFollow)
+	mux.HandleFunc("DELETE /api/v1/users/{username}/follow", s.handleUnfollow)
+
+	// Posts
+	mux.HandleFunc("GET /api/v1/feed", s.handleFeed)
+	mux.HandleFunc("POST /api/v1/posts", s.handleCreatePost)
+	mux.HandleFunc("GET /api/v1/posts/{id}", s.handleGetPost)
+	mux.HandleFunc("PATCH /api/v1/posts/{id}", s.handleEditPost)
+	mux.HandleFunc("DELETE /api/v1/posts/{id}", s.handleDeletePost)
+	mux.HandleFunc("GET /api/v1/posts/{id}/history", s.handleHistory)
+	mux.HandleFunc("POST /api/v1/posts/{id}/vote", s.handleVote)
+	mux.HandleFunc("GET /api/v1/posts/{id}/thread", s.handleThread)
+	mux.HandleFunc("GET /api/v1/posts/{id}/media", s.handlePostMedia)
+	mux.HandleFunc("GET /api/v1/posts/{id}/likes", s.handleLikes)
+	mux.HandleFunc("POST /api/v1/posts/{id}/like", s.handleLike)
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/like", s.handleUnlike)
+	mux.HandleFunc("POST /api/v1/posts/{id}/retweet", s.handleRetweet)
+	mux.HandleFunc("DELETE /api/v1/posts/{id}/retweet", s.handleUnretweet)
+	mux.HandleFunc("GET /api/v1/search", s.handleSearch)
+	mux.HandleFunc("GET /api/v1/hashtags/{tag}/posts", s.handleHashtagPosts)
+	mux.HandleFunc("GET /api/v1/trending", s.handleTrending)
+	mux.HandleFunc("GET /api/v1/mentions", s.handleMentions)
+
+	// Direct messages
+	mux.HandleFunc("POST /api/v1/messages", s.handleSendMessage)
+	mux.HandleFunc("GET /api/v1/messages/inbox", s.handleInbox)
+	mux.HandleFunc("GET /api/v1/messages/search", s.handleSearchMessages)
+	mux.HandleFunc("DELETE /api/v1/messages/{id}", s.handleDeleteMessage)
+	mux.HandleFunc("GET /api/v1/conversations", s.handleConversations)
+	mux.HandleFunc("GET /api/v1/conversations/{username}", s.handleConversation)
+
+	// Notifications
+	mux.HandleFunc("GET /api/v1/notifications", s.handleNotifications)
+	mux.HandleFunc("GET /api/v1/notifications/count", s.handleNotificationCount)
+	mux.HandleFunc("POST /api/v1/notifications/read", s.handleMarkNotificationsRead)
+	mux.HandleFunc("DELETE /api/v1/notifications/{id}", s.handleDeleteNotification)
+
+	// Uploaded images
+	mux.Handle("GET /media/", http.StripPrefix("/media/", noDirListing(http.FileServer(http.Dir(media.GetMediaDir())))))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, newHTTPError(http.StatusNotFound, "no such endpoint"))
+	})
+
+	return logRequests(mux)
+}
+
+// httpError is an error with an explicit status code
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func newHTTPError(status int, format string, args ...interface{}) error {
+	return &httpError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+var errUnauthenticated = newHTTPError(http.StatusUnauthorized, "missing bearer token")
+
+// statusFor maps store and service errors to HTTP status codes
+func statusFor(err error) int {
+	var he *httpError
+	if errors.As(err, &he) {
+		return he.status
+	}
+
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		return http.StatusBadRequest
+	}
+
+	var se *parser.SyntaxError
+	if errors.As(err, &se) {
+		return http.StatusBadRequest
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrWrongPassword),
+		errors.Is(err, service.ErrIncorrectPassword),
+		errors.Is(err, store.ErrSessionNotFound),
+		errors.Is(err, store.ErrSessionExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, store.ErrEmptyQuery):
+		return http.StatusBadRequest
+	case errors.Is(err, store.ErrUserNotFound),
+		errors.Is(err, store.ErrPostNotFound),
+		errors.Is(err, store.ErrNotPostOwner),
+		errors.Is(err, store.ErrMessageNotFound),
+		errors.Is(err, store.ErrNotificationNotFound),
+		errors.Is(err, store.ErrPollNotFound),
+		errors.Is(err, store.ErrNotFollowing),
+		errors.Is(err, store.ErrNotLiked),
+		errors.Is(err, store.ErrNotRetweeted),
+		errors.Is(err, store.ErrNotBlocked),
+		errors.Is(err, store.ErrNotMuted):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrUsernameTaken),
+		errors.Is(err, store.ErrAlreadyFollowing),
+		errors.Is(err, store.ErrAlreadyLiked),
+		errors.Is(err, store.ErrAlreadyRetweeted),
+		errors.Is(err, store.ErrAlreadyVoted):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrFollowSelf),
+		errors.Is(err, store.ErrRetweetOwnPost),
+		errors.Is(err, service.ErrMessageSelf),
+		errors.Is(err, service.ErrBlockSelf),
+		errors.Is(err, service.ErrMuteSelf):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, store.ErrBlocked),
+		errors.Is(err, service.ErrEditWindow),
+		errors.Is(err, service.ErrPollClosed):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := statusFor(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		// Don't leak SQL errors to clients
+		log.Printf("internal error: %v", err)
+		message = "internal server error"
+	}
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// decodeJSON reads a JSON request body into v
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return newHTTPError(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// bearerToken extracts the session token from the Authorization header
+func bearerToken(r *http.Request) (string, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", errUnauthenticated
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// currentUser resolves the user a request acts as from its session token
+func (s *Server) currentUser(r *http.Request) (*models.User, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.services.Users.SessionUser(token)
+}
+
+// viewerID is the ID of the user a public request is made by, or "" when
+// it has no Authorization header. Public reads use it to hide what the
+// user has blocked or muted.
+func (s *Server) viewerID(r *http.Request) (string, error) {
+	if r.Header.Get("Authorization") == "" {
+		return "", nil
+	}
+
+	user, err := s.currentUser(r)
+	if err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
+
+// page holds limit/offset pagination parameters. Only search uses them:
+// results are ranked by relevance, which a (created_at, ID) cursor can't
+// hold a place in, so every other list is paged with parseCursorPage.
+type page struct {
+	Limit  int
+	Offset int
+}
+
+func parsePage(r *http.Request) (page, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return page{}, err
+	}
+	p := page{Limit: limit}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return p, newHTTPError(http.StatusBadRequest, "offset must be a non-negative integer")
+		}
+		p.Offset = offset
+	}
+
+	return p, nil
+}
+
+// parseCursorPage reads limit, before and after for a list ordered by time
+func parseCursorPage(r *http.Request) (store.Page, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return store.Page{}, err
+	}
+	p := store.Page{Limit: limit}
+
+	query := r.URL.Query()
+	if query.Get("offset") != "" {
+		return p, newHTTPError(http.StatusBadRequest, "this list is paged with before and after cursors, not offset")
+	}
+
+	if v := query.Get("before"); v != "" {
+		if p.Before, err = models.ParseCursor(v); err != nil {
+			return p, newHTTPError(http.StatusBadRequest, "before is not a valid cursor")
+		}
+	}
+	if v := query.Get("after"); v != "" {
+		if p.After, err = models.ParseCursor(v); err != nil {
+			return p, newHTTPError(http.StatusBadRequest, "after is not a valid cursor")
+		}
+	}
+
+	return p, nil
+}
+
+func parseLimit(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, newHTTPError(http.StatusBadRequest, "limit must be between 1 and %d", maxPageSize)
+	}
+	return limit, nil
+}
+
+// pageResponse wraps a list endpoint's results
+type pageResponse struct {
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextOffset *int        `json:"next_offset"`
+}
+
+// pageAt builds the page for items a store already offset, fetched with a
+// limit of p.Limit+1 so the extra item tells whether another page exists
+func pageAt[T any](items []T, p page) pageResponse {
+	resp := pageResponse{Data: []T{}, Limit: p.Limit, Offset: p.Offset}
+
+	if len(items) > p.Limit {
+		next := p.Offset + p.Limit
+		resp.NextOffset = &next
+		items = items[:p.Limit]
+	}
+
+	if len(items) > 0 {
+		resp.Data = items
+	}
+	return resp
+}
+
+// cursorPageResponse wraps the results of a list endpoint paged by cursor
+type cursorPageResponse struct {
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// paginateCursor trims items fetched with p.Probe() to the page and adds
+// the cursor that continues it
+func paginateCursor[T store.Paged](items []T, p store.Page) cursorPageResponse {
+	resp := cursorPageResponse{Data: []T{}, Limit: p.Limit}
+
+	items, next := store.Paginate(items, p)
+	if next != nil {
+		token := next.String()
+		resp.NextCursor = &token
+	}
+
+	if len(items) > 0 {
+		resp.Data = items
+	}
+	return resp
+}
+
+// noDirListing hides directory indexes from the media file server
+func noDirListing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the status code for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
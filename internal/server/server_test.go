@@ -0,0 +1,148 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+func setupTestServer(t *testing.T) http.Handler {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	stores := store.NewStores(database)
+	return New(stores, service.New(stores, log.New(io.Discard, "", 0), service.Options{})).Handler()
+}
+
+// do sends a request with a session token (if non-empty) and decodes the
+// JSON response into out
+func do(t *testing.T, h http.Handler, method, path, token, body string, out interface{}) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: failed to decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+
+	return rec.Code
+}
+
+func TestServer_PostsAndFeed(t *testing.T) {
+	h := setupTestServer(t)
+
+	tokens := map[string]string{}
+	for _, name := range []string{"alice", "bob"} {
+		creds := `{"username":"` + name + `","password":"hunter22"}`
+		if code := do(t, h, "POST", "/api/v1/users", "", creds, nil); code != http.StatusCreated {
+			t.Fatalf("expected 201 creating %s, got %d", name, code)
+		}
+
+		var session sessionResponse
+		if code := do(t, h, "POST", "/api/v1/sessions", "", creds, &session); code != http.StatusCreated {
+			t.Fatalf("expected 201 logging in %s, got %d", name, code)
+		}
+		tokens[name] = session.Token
+	}
+	alice, bob := tokens["alice"], tokens["bob"]
+
+	if code := do(t, h, "POST", "/api/v1/users", "", `{"username":"alice","password":"hunter22"}`, nil); code != http.StatusConflict {
+		t.Errorf("expected 409 for duplicate user, got %d", code)
+	}
+
+	if code := do(t, h, "POST", "/api/v1/sessions", "", `{"username":"alice","password":"wrong-password"}`, nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for wrong password, got %d", code)
+	}
+
+	if code := do(t, h, "GET", "/api/v1/feed", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+
+	if code := do(t, h, "GET", "/api/v1/feed", "not-a-token", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with unknown token, got %d", code)
+	}
+
+	for _, text := range []string{"one", "two", "three"} {
+		if code := do(t, h, "POST", "/api/v1/posts", alice, `{"text":"`+text+` @bob"}`, nil); code != http.StatusCreated {
+			t.Fatalf("expected 201 creating post, got %d", code)
+		}
+	}
+
+	if code := do(t, h, "POST", "/api/v1/users/alice/follow", bob, "", nil); code != http.StatusNoContent {
+		t.Fatalf("expected 204 following, got %d", code)
+	}
+
+	var feed struct {
+		Data []struct {
+			Post struct {
+				ID   string `json:"id"`
+				Text string `json:"text"`
+			} `json:"post"`
+			Username string `json:"username"`
+		} `json:"data"`
+		NextCursor *string `json:"next_cursor"`
+	}
+
+	if code := do(t, h, "GET", "/api/v1/feed?limit=2", bob, "", &feed); code != http.StatusOK {
+		t.Fatalf("expected 200 for feed, got %d", code)
+	}
+	if len(feed.Data) != 2 || feed.NextCursor == nil {
+		t.Fatalf("expected first page of 2 with a next_cursor, got %d posts", len(feed.Data))
+	}
+
+	if code := do(t, h, "GET", "/api/v1/feed?limit=2&before="+*feed.NextCursor, bob, "", &feed); code != http.StatusOK {
+		t.Fatalf("expected 200 for feed, got %d", code)
+	}
+	if len(feed.Data) != 1 || feed.NextCursor != nil {
+		t.Errorf("expected last page of 1 with no next_cursor, got %d posts", len(feed.Data))
+	}
+
+	if code := do(t, h, "GET", "/api/v1/feed?before=bogus", bob, "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad cursor, got %d", code)
+	}
+	if code := do(t, h, "GET", "/api/v1/feed?offset=2", bob, "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for offset on a cursor-paged list, got %d", code)
+	}
+
+	if code := do(t, h, "GET", "/api/v1/posts/missing", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing post, got %d", code)
+	}
+
+	postID := feed.Data[0].Post.ID
+	if code := do(t, h, "DELETE", "/api/v1/posts/"+postID, bob, "", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting someone else's post, got %d", code)
+	}
+
+	var count countResponse
+	if code := do(t, h, "GET", "/api/v1/notifications/count", bob, "", &count); code != http.StatusOK {
+		t.Fatalf("expected 200 for notification count, got %d", code)
+	}
+	if count.Count != 3 {
+		t.Errorf("expected 3 mention notifications, got %d", count.Count)
+	}
+
+	if code := do(t, h, "DELETE", "/api/v1/sessions", bob, "", nil); code != http.StatusNoContent {
+		t.Fatalf("expected 204 logging out, got %d", code)
+	}
+	if code := do(t, h, "GET", "/api/v1/me", bob, "", nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 after logout, got %d", code)
+	}
+}
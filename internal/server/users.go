@@ -0,0 +1,191 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type sessionResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt int64        `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, err := s.services.Users.Register(req.Username, req.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Accounts without a password must set one with `twt login` first
+	user, err := s.services.Users.Authenticate(req.Username, req.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	token, session, err := s.services.Users.StartSession(user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, sessionResponse{Token: token, ExpiresAt: session.ExpiresAt, User: user})
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.currentUser(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	token, err := bearerToken(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.services.Users.EndSession(token); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := s.stores.Users.GetByUsername(r.PathValue("username"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleUserPosts(w http.ResponseWriter, r *http.Request) {
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	username := r.PathValue("username")
+	if _, err := s.stores.Users.GetByUsername(username); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	posts, err := s.stores.Posts.GetByUsername(username, p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(posts, p))
+}
+
+func (s *Server) handleFollowers(w http.ResponseWriter, r *http.Request) {
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	users, err := s.stores.Social.GetFollowersByUsername(r.PathValue("username"), p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(users, p))
+}
+
+func (s *Server) handleFollowing(w http.ResponseWriter, r *http.Request) {
+	p, err := parseCursorPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	users, err := s.stores.Social.GetFollowingByUsername(r.PathValue("username"), p.Probe())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginateCursor(users, p))
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.services.Users.Stats(r.PathValue("username"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) handleFollow(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := s.services.Social.Follow(user.ID, r.PathValue("username")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUnfollow(w http.ResponseWriter, r *http.Request) {
+	user, err := s.currentUser(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := s.services.Social.Unfollow(user.ID, r.PathValue("username")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// BlockService owns blocking
+type BlockService struct {
+	blocks    store.Blocks
+	users     store.Users
+	social    store.Social
+	timelines store.Timelines
+	fanout    fanout
+	log       Logger
+}
+
+func NewBlockService(blocks store.Blocks, users store.Users, social store.Social, timelines store.Timelines, opts Options, logger Logger) *BlockService {
+	return &BlockService{
+		blocks:    blocks,
+		users:     users,
+		social:    social,
+		timelines: timelines,
+		fanout:    fanout{opts},
+		log:       logger,
+	}
+}
+
+// Block blocks the user named username. Any follows between the two users
+// are removed, and with them each one's posts from the other's timeline.
+func (s *BlockService) Block(blockerID, username string) (*models.User, error) {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.ID == blockerID {
+		return nil, ErrBlockSelf
+	}
+
+	if err := s.blocks.Block(blockerID, target.ID); err != nil {
+		return nil, err
+	}
+
+	for _, pair := range [][2]string{{blockerID, target.ID}, {target.ID, blockerID}} {
+		if err := s.fanout.unfollow(s.timelines, s.social, pair[0], pair[1]); err != nil {
+			s.log.Printf("failed to update timeline: %v", err)
+		}
+	}
+
+	return target, nil
+}
+
+// Unblock unblocks the user named username
+func (s *BlockService) Unblock(blockerID, username string) (*models.User, error) {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.blocks.Unblock(blockerID, target.ID); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// Blocked returns a page of the users a user has blocked, most recent first
+func (s *BlockService) Blocked(blockerID string, page store.Page) ([]store.Connection, error) {
+	return s.blocks.GetBlocked(blockerID, page)
+}
+
+// checkNotBlocked returns store.ErrBlocked if either user has blocked the
+// other
+func checkNotBlocked(blocks store.Blocks, userA, userB string) error {
+	blocked, err := blocks.EitherBlocked(userA, userB)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return store.ErrBlocked
+	}
+	return nil
+}
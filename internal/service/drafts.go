@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/media"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// dueBatch caps how many scheduled posts PublishDue loads at a time
+const dueBatch = 100
+
+// NewDraft is what a user saves as a draft, or schedules to post later
+type NewDraft struct {
+	Text      string
+	Images    []string   // Paths of images on local disk
+	PublishAt *time.Time // When the scheduler should publish it; nil to keep it
+}
+
+// DraftChange is an edit to a draft. Fields left nil aren't changed.
+type DraftChange struct {
+	Text       *string
+	Images     []string // Paths of images on local disk to replace the draft's with
+	PublishAt  *time.Time
+	Unschedule bool // Keep the draft until it's published by hand
+}
+
+// ScheduledPost is what became of a scheduled post when the scheduler ran
+type ScheduledPost struct {
+	Draft models.Draft
+	Post  *PublishedPost // nil if it couldn't be published
+	Err   error
+}
+
+// DraftService owns drafts and scheduled posts. Images attached to them are
+// copied into the media directory when they're saved, and removed again
+// once they're published or deleted.
+type DraftService struct {
+	tx     store.Transactor
+	drafts store.Drafts
+	users  store.Users
+	posts  *PostService
+	log    Logger
+}
+
+func NewDraftService(tx store.Transactor, drafts store.Drafts, users store.Users, posts *PostService, logger Logger) *DraftService {
+	return &DraftService{tx: tx, drafts: drafts, users: users, posts: posts, log: logger}
+}
+
+// Save saves a draft for author, scheduled if in.PublishAt is set. The
+// draft is checked as a post would be, so it can be published as it is.
+func (s *DraftService) Save(author *models.User, in NewDraft) (*models.Draft, error) {
+	draft := &models.Draft{UserID: author.ID, Text: in.Text}
+	if err := schedule(draft, in.PublishAt); err != nil {
+		return nil, err
+	}
+	if err := checkDraft(draft, in.Images); err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	err := s.tx.InTx(func(tx *store.Stores) error {
+		if err := tx.Drafts.Create(draft); err != nil {
+			return err
+		}
+		if len(in.Images) == 0 {
+			return nil
+		}
+
+		var err error
+		if draft.Images, err = copyDraftImages(draft.ID, in.Images, &copied); err != nil {
+			return err
+		}
+		return tx.Drafts.Update(draft)
+	})
+	if err != nil {
+		s.removeImages(copied)
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// Drafts returns a page of a user's drafts, newest first
+func (s *DraftService) Drafts(userID string, page store.Page) ([]models.Draft, error) {
+	return s.drafts.GetByUser(userID, page)
+}
+
+// Draft returns one of a user's drafts
+func (s *DraftService) Draft(userID, draftID string) (*models.Draft, error) {
+	return s.drafts.GetByID(draftID, userID)
+}
+
+// Edit changes one of a user's drafts. Editing a scheduled post that failed
+// to publish lets the scheduler try it again.
+func (s *DraftService) Edit(userID, draftID string, change DraftChange) (*models.Draft, error) {
+	draft, err := s.drafts.GetByID(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Text != nil {
+		draft.Text = *change.Text
+	}
+	if change.Unschedule {
+		draft.PublishAt = nil
+	} else if change.PublishAt != nil {
+		if err := schedule(draft, change.PublishAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkDraft(draft, change.Images); err != nil {
+		return nil, err
+	}
+
+	old := draft.Images
+	var copied []string
+	err = s.tx.InTx(func(tx *store.Stores) error {
+		if change.Images != nil {
+			var err error
+			if draft.Images, err = copyDraftImages(draft.ID, change.Images, &copied); err != nil {
+				return err
+			}
+		}
+		return tx.Drafts.Update(draft)
+	})
+	if err != nil {
+		s.removeImages(copied)
+		return nil, err
+	}
+
+	// An image attached again is copied to the same path, so keep that
+	if change.Images != nil {
+		s.removeImages(slices.DeleteFunc(old, func(path string) bool {
+			return slices.Contains(draft.Images, path)
+		}))
+	}
+	return draft, nil
+}
+
+// Delete deletes one of a user's drafts and its images
+func (s *DraftService) Delete(userID, draftID string) (*models.Draft, error) {
+	draft, err := s.drafts.GetByID(draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.drafts.Delete(draftID, userID); err != nil {
+		return nil, err
+	}
+
+	s.removeImages(draft.Images)
+	return draft, nil
+}
+
+// Publish publishes one of author's drafts now, as Publish would, and
+// deletes the draft in the same transaction
+func (s *DraftService) Publish(author *models.User, draftID string) (*PublishedPost, error) {
+	draft, err := s.drafts.GetByID(draftID, author.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publish(author, draft)
+}
+
+// PublishDue publishes every scheduled post whose time has come by now,
+// including any missed while the scheduler wasn't running, longest overdue
+// first. A post that can't be published, say because an image has gone
+// missing, is marked failed and left for its author to edit; other errors
+// stop the run.
+func (s *DraftService) PublishDue(now time.Time) ([]ScheduledPost, error) {
+	var results []ScheduledPost
+	for {
+		due, err := s.drafts.GetDue(now.Unix(), dueBatch)
+		if err != nil || len(due) == 0 {
+			return results, err
+		}
+
+		for _, draft := range due {
+			result := ScheduledPost{Draft: draft}
+
+			author, err := s.users.GetByID(draft.UserID)
+			if err == nil {
+				result.Post, err = s.publish(author, &draft)
+			}
+			if errors.Is(err, store.ErrDraftNotFound) {
+				// Deleted by its author since it was loaded
+				continue
+			}
+			if err != nil {
+				if !failed(err) {
+					return results, err
+				}
+				if err := s.drafts.SetError(draft.ID, err.Error()); err != nil {
+					return results, err
+				}
+				result.Err = err
+			}
+
+			results = append(results, result)
+		}
+	}
+}
+
+// failed reports whether err means a scheduled post can't be published
+// as it is, rather than that something went wrong trying
+func failed(err error) bool {
+	return errors.As(err, new(*ValidationError)) ||
+		errors.Is(err, store.ErrUserNotFound) ||
+		errors.Is(err, store.ErrPostNotFound) ||
+		errors.Is(err, store.ErrBlocked)
+}
+
+// publish publishes a draft and deletes it, then removes its image copies,
+// which publishing has copied again for the post
+func (s *DraftService) publish(author *models.User, draft *models.Draft) (*PublishedPost, error) {
+	in := NewPost{Text: draft.Text, Images: draft.Images}
+	if err := checkNewPost(&in); err != nil {
+		return nil, invalid(err)
+	}
+
+	published, err := s.posts.publishAll(author, []NewPost{in}, func(tx *store.Stores) error {
+		return tx.Drafts.Delete(draft.ID, draft.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeImages(draft.Images)
+	return published[0], nil
+}
+
+// schedule sets when a draft is published, which must be in the future
+func schedule(draft *models.Draft, at *time.Time) error {
+	if at == nil {
+		draft.PublishAt = nil
+		return nil
+	}
+	if !at.After(time.Now()) {
+		return invalid(fmt.Errorf("can't schedule a post in the past (%s)", at.Format("2006-01-02 15:04")))
+	}
+
+	publishAt := at.Unix()
+	draft.PublishAt = &publishAt
+	return nil
+}
+
+// checkDraft sanitizes a draft's text and checks it, and images if they're
+// to replace the draft's, as a post would be checked
+func checkDraft(draft *models.Draft, images []string) error {
+	in := NewPost{Text: draft.Text, Images: images}
+	if err := checkNewPost(&in); err != nil {
+		return invalid(err)
+	}
+
+	draft.Text = in.Text
+	return nil
+}
+
+// copyDraftImages copies images into the media directory for a draft,
+// adding the copies' paths to copied as it goes
+func copyDraftImages(draftID string, images []string, copied *[]string) ([]string, error) {
+	var paths []string
+	for i, imgPath := range images {
+		destPath, _, err := media.CopyImageToMedia(imgPath, "draft_"+draftID, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy image %s: %w", imgPath, err)
+		}
+		*copied = append(*copied, destPath)
+		paths = append(paths, destPath)
+	}
+	return paths, nil
+}
+
+// removeImages removes image copies from the media directory, logging any
+// that can't be removed
+func (s *DraftService) removeImages(paths []string) {
+	for _, path := range paths {
+		if err := media.DeleteMediaFile(path); err != nil {
+			s.log.Printf("failed to remove draft image %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// Fanout says when home feeds are assembled
+type Fanout string
+
+const (
+	// FanoutOnRead queries the posts of everyone a user follows each time
+	// their feed is read
+	FanoutOnRead Fanout = "read"
+	// FanoutOnWrite copies each post into its readers' timelines when it's
+	// written, so reading a feed only reads that user's timeline
+	FanoutOnWrite Fanout = "write"
+)
+
+// ParseFanout parses "read" or "write". An empty string means FanoutOnRead.
+func ParseFanout(s string) (Fanout, error) {
+	switch Fanout(s) {
+	case "", FanoutOnRead:
+		return FanoutOnRead, nil
+	case FanoutOnWrite:
+		return FanoutOnWrite, nil
+	}
+	return "", fmt.Errorf("unknown fan-out %q: use read or write", s)
+}
+
+// DefaultFanoutLimit is the follower count above which posts are pulled at
+// read time instead of fanned out
+const DefaultFanoutLimit = 1000
+
+// timelineBackfill is how many of an author's latest posts are copied into
+// a timeline when it starts including them
+const timelineBackfill = 200
+
+// fanout keeps materialized timelines up to date as posts and follows
+// change. Everything it does is skipped unless fan-out-on-write is on.
+type fanout struct {
+	opts Options
+}
+
+func (f fanout) enabled() bool {
+	return f.opts.Fanout == FanoutOnWrite
+}
+
+func (f fanout) limit() int {
+	if f.opts.FanoutLimit > 0 {
+		return f.opts.FanoutLimit
+	}
+	return DefaultFanoutLimit
+}
+
+// post copies a new post into its readers' timelines. Authors with more
+// than the limit of followers only get it in their own: their followers
+// pull it when they read their feed (the hybrid path).
+func (f fanout) post(timelines store.Timelines, social store.Social, post *models.Post) error {
+	if !f.enabled() {
+		return nil
+	}
+
+	_, followers, err := social.GetFollowCounts(post.AuthorID)
+	if err != nil {
+		return err
+	}
+	if followers > f.limit() {
+		return timelines.Add(post.AuthorID, post)
+	}
+
+	return timelines.FanOut(post)
+}
+
+// follow copies the followee's latest posts into the follower's timeline,
+// unless they're pulled anyway
+func (f fanout) follow(timelines store.Timelines, social store.Social, followerID, followeeID string) error {
+	if !f.enabled() {
+		return nil
+	}
+
+	_, followers, err := social.GetFollowCounts(followeeID)
+	if err != nil {
+		return err
+	}
+	if followers > f.limit() {
+		return nil
+	}
+
+	return timelines.Backfill(followerID, followeeID, timelineBackfill)
+}
+
+// unfollow takes the followee's posts out of the follower's timeline. If
+// that leaves the followee at the limit, their posts stop being pulled, so
+// they're copied into their remaining followers' timelines instead.
+func (f fanout) unfollow(timelines store.Timelines, social store.Social, followerID, followeeID string) error {
+	if !f.enabled() {
+		return nil
+	}
+
+	if err := timelines.Remove(followerID, followeeID); err != nil {
+		return err
+	}
+
+	_, followers, err := social.GetFollowCounts(followeeID)
+	if err != nil {
+		return err
+	}
+	if followers == f.limit() {
+		return timelines.BackfillFollowers(followeeID, timelineBackfill)
+	}
+
+	return nil
+}
+
+// FeedService reads home feeds, assembled on read or on write depending
+// on Options.Fanout, and ranked feeds
+type FeedService struct {
+	posts     store.Posts
+	timelines store.Timelines
+	ranking   store.Ranking
+	fanout    fanout
+
+	// Ranker builds ranked feeds. More sources, filters and scorers can be
+	// registered on it.
+	Ranker *Ranker
+}
+
+func NewFeedService(posts store.Posts, timelines store.Timelines, ranking store.Ranking, hashtags store.Hashtags, opts Options) *FeedService {
+	return &FeedService{
+		posts:     posts,
+		timelines: timelines,
+		ranking:   ranking,
+		fanout:    fanout{opts},
+		Ranker:    DefaultRanker(ranking, hashtags),
+	}
+}
+
+// Feed returns a page of a user's home feed: their own posts and those of
+// the users they follow, leaving out users blocked either way and anything
+// they have muted
+func (s *FeedService) Feed(userID string, page store.Page) ([]store.PostWithAuthor, error) {
+	if !s.fanout.enabled() {
+		return s.posts.GetFeed(userID, page)
+	}
+
+	timeline, err := s.timelines.GetTimeline(userID, page)
+	if err != nil {
+		return nil, err
+	}
+	pulled, err := s.timelines.GetPulled(userID, s.fanout.limit(), page)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Merge(page, timeline, pulled), nil
+}
+
+// Ranked returns up to limit posts for a user's ranked feed, best first,
+// and records them as seen so the next call moves on to others
+func (s *FeedService) Ranked(userID string, limit int) ([]Candidate, error) {
+	ranked, err := s.Ranker.Rank(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(ranked))
+	for i, c := range ranked {
+		ids[i] = c.Post.ID
+	}
+	if err := s.ranking.MarkSeen(userID, ids, time.Now().Add(-rankWindow).Unix()); err != nil {
+		return nil, err
+	}
+
+	return ranked, nil
+}
+
+// ResetSeen lets posts already shown in a user's ranked feed be shown again
+func (s *FeedService) ResetSeen(userID string) error {
+	return s.ranking.ForgetSeen(userID)
+}
+
+// Rebuild refills every materialized timeline from the follow graph.
+// Timelines aren't kept up to date with fan-out-on-read, so this is needed
+// after switching to fan-out-on-write or changing the fan-out limit.
+func (s *FeedService) Rebuild() error {
+	return s.timelines.Rebuild(timelineBackfill, s.fanout.limit())
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// MessageService owns direct messaging
+type MessageService struct {
+	messages      store.Messages
+	users         store.Users
+	blocks        store.Blocks
+	notifications store.Notifications
+	log           Logger
+}
+
+func NewMessageService(messages store.Messages, users store.Users, blocks store.Blocks, notifications store.Notifications, logger Logger) *MessageService {
+	return &MessageService{
+		messages:      messages,
+		users:         users,
+		blocks:        blocks,
+		notifications: notifications,
+		log:           logger,
+	}
+}
+
+// Send sends a direct message to the user named username, unless either of
+// them has blocked the other, and notifies them
+func (s *MessageService) Send(senderID, username, rawText string) (*models.Message, error) {
+	text := strings.TrimSpace(rawText)
+	if text == "" {
+		return nil, invalid(errors.New("message cannot be empty"))
+	}
+
+	receiver, err := s.users.GetByUsername(strings.TrimPrefix(username, "@"))
+	if err != nil {
+		return nil, err
+	}
+
+	if receiver.ID == senderID {
+		return nil, ErrMessageSelf
+	}
+
+	if err := checkNotBlocked(s.blocks, senderID, receiver.ID); err != nil {
+		return nil, err
+	}
+
+	message, err := s.messages.Send(senderID, receiver.ID, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.notifications.Create(receiver.ID, senderID, "message", &message.ID); err != nil {
+		s.log.Printf("failed to create notification: %v", err)
+	}
+
+	return message, nil
+}
+
+// Conversation returns a page of the messages between a user and the user
+// named username, newest first. Reading the first page marks the messages
+// they received as read; older and newer pages leave them as they are, so
+// messages newer than the page aren't marked read unseen.
+func (s *MessageService) Conversation(userID, username string, page store.Page) ([]models.MessageWithUser, error) {
+	other, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messages.GetConversation(userID, other.ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.First() {
+		if err := s.messages.MarkAsRead(userID, other.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return messages, nil
+}
+
+// Delete deletes a message the user sent and the notification about it
+func (s *MessageService) Delete(senderID, messageID string) error {
+	if err := s.messages.DeleteMessage(messageID, senderID); err != nil {
+		return err
+	}
+
+	if err := s.notifications.RetractTarget(messageID); err != nil {
+		s.log.Printf("failed to retract notification: %v", err)
+	}
+
+	return nil
+}
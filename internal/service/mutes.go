@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+var tagRe = regexp.MustCompile(`^\w+$`)
+
+// MuteService owns muting users, words and hashtags
+type MuteService struct {
+	mutes store.Mutes
+	users store.Users
+}
+
+func NewMuteService(mutes store.Mutes, users store.Users) *MuteService {
+	return &MuteService{mutes: mutes, users: users}
+}
+
+// Mute mutes target for userID: @username for a user, #tag for a hashtag,
+// and anything else as a word or phrase. A zero duration mutes until
+// unmuted. Muted users aren't told.
+func (s *MuteService) Mute(userID, target string, duration time.Duration) (*models.Mute, error) {
+	mute, err := s.resolve(userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if duration < 0 {
+		return nil, invalid(errors.New("mute duration must be positive"))
+	}
+	if duration > 0 {
+		expiresAt := time.Now().Add(duration).Unix()
+		mute.ExpiresAt = &expiresAt
+	}
+
+	if err := s.mutes.Mute(userID, mute.Kind, mute.key, mute.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &mute.Mute, nil
+}
+
+// Unmute lifts a mute; target is written as for Mute
+func (s *MuteService) Unmute(userID, target string) (*models.Mute, error) {
+	mute, err := s.resolve(userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mutes.Unmute(userID, mute.Kind, mute.key); err != nil {
+		return nil, err
+	}
+
+	return &mute.Mute, nil
+}
+
+// Muted returns a page of a user's mutes that haven't expired
+func (s *MuteService) Muted(userID string, page store.Page) ([]models.Mute, error) {
+	return s.mutes.GetMutes(userID, page)
+}
+
+// resolvedMute is a mute target with the value the store keys it by: the
+// user's ID for user mutes
+type resolvedMute struct {
+	models.Mute
+	key string
+}
+
+func (s *MuteService) resolve(userID, target string) (*resolvedMute, error) {
+	target = strings.TrimSpace(target)
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		user, err := s.users.GetByUsername(target[1:])
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == userID {
+			return nil, ErrMuteSelf
+		}
+		return &resolvedMute{
+			Mute: models.Mute{UserID: userID, Kind: models.MuteUser, Value: user.Username},
+			key:  user.ID,
+		}, nil
+
+	case strings.HasPrefix(target, "#"):
+		tag := strings.ToLower(target[1:])
+		if !tagRe.MatchString(tag) {
+			return nil, invalid(fmt.Errorf("%q is not a valid hashtag", target))
+		}
+		return &resolvedMute{
+			Mute: models.Mute{UserID: userID, Kind: models.MuteHashtag, Value: tag},
+			key:  tag,
+		}, nil
+	}
+
+	// Words are matched like search words, so quotes can't be part of them
+	word := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(target, `"`, ""))), " ")
+	if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return nil, invalid(fmt.Errorf("%q has no letters or numbers to mute", target))
+	}
+
+	return &resolvedMute{
+		Mute: models.Mute{UserID: userID, Kind: models.MuteWord, Value: word},
+		key:  word,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// minLikesTypes are the notification types about a user's own posts, the
+// only ones a like threshold makes sense for
+var minLikesTypes = []string{"like", "retweet"}
+
+// NotificationService owns users' notification settings
+type NotificationService struct {
+	notifications store.Notifications
+}
+
+func NewNotificationService(notifications store.Notifications) *NotificationService {
+	return &NotificationService{notifications: notifications}
+}
+
+// RuleChange updates part of a notification rule; nil fields are left as
+// they are
+type RuleChange struct {
+	Enabled       *bool
+	OnlyFollowing *bool
+	MinLikes      *int
+}
+
+// Settings returns a user's notification settings
+func (s *NotificationService) Settings(userID string) (*models.NotificationSettings, error) {
+	return s.notifications.GetSettings(userID)
+}
+
+// UpdateRule applies change to the user's rule for notifType, or to every
+// type if notifType is "all", and returns the updated rules
+func (s *NotificationService) UpdateRule(userID, notifType string, change RuleChange) ([]models.NotificationRule, error) {
+	notifType = strings.ToLower(notifType)
+	if notifType != "all" && !slices.Contains(models.NotificationTypes, notifType) {
+		return nil, invalid(fmt.Errorf("unknown notification type %q (expected %s or all)",
+			notifType, strings.Join(models.NotificationTypes, ", ")))
+	}
+
+	if change.MinLikes != nil {
+		if *change.MinLikes < 0 {
+			return nil, invalid(errors.New("minimum likes can't be negative"))
+		}
+		if !slices.Contains(minLikesTypes, notifType) {
+			return nil, invalid(errors.New("a minimum number of likes only applies to like and retweet notifications"))
+		}
+	}
+
+	settings, err := s.notifications.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated []models.NotificationRule
+	for _, rule := range settings.Rules {
+		if notifType != "all" && rule.Type != notifType {
+			continue
+		}
+
+		if change.Enabled != nil {
+			rule.Enabled = *change.Enabled
+		}
+		if change.OnlyFollowing != nil {
+			rule.OnlyFollowing = *change.OnlyFollowing
+		}
+		if change.MinLikes != nil {
+			rule.MinLikes = *change.MinLikes
+		}
+
+		if err := s.notifications.SetRule(userID, rule); err != nil {
+			return nil, err
+		}
+		updated = append(updated, rule)
+	}
+
+	return updated, nil
+}
+
+// SetQuietHours sets the user's quiet hours from a window such as
+// 22:00-07:00, or turns them off for "off". It returns nil when off.
+func (s *NotificationService) SetQuietHours(userID, window string) (*models.QuietHours, error) {
+	var quiet *models.QuietHours
+	if !strings.EqualFold(strings.TrimSpace(window), "off") {
+		var err error
+		quiet, err = parseQuietHours(window)
+		if err != nil {
+			return nil, invalid(err)
+		}
+	}
+
+	if err := s.notifications.SetQuietHours(userID, quiet); err != nil {
+		return nil, err
+	}
+
+	return quiet, nil
+}
+
+// parseQuietHours parses a window of 24-hour times such as 22:00-07:00
+func parseQuietHours(window string) (*models.QuietHours, error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(window), "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q should look like 22:00-07:00", window)
+	}
+
+	minutes := func(clock string) (int, error) {
+		t, err := time.Parse("15:04", strings.TrimSpace(clock))
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a time like 22:00", clock)
+		}
+		return t.Hour()*60 + t.Minute(), nil
+	}
+
+	startMinute, err := minutes(start)
+	if err != nil {
+		return nil, err
+	}
+	endMinute, err := minutes(end)
+	if err != nil {
+		return nil, err
+	}
+	if startMinute == endMinute {
+		return nil, errors.New("quiet hours must start and end at different times")
+	}
+
+	return &models.QuietHours{Start: startMinute, End: endMinute}, nil
+}
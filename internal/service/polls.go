@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// closedBatch caps how many closed polls NotifyClosed loads at a time
+const closedBatch = 100
+
+// PollService owns voting in the polls attached to posts, and telling
+// their authors when they close
+type PollService struct {
+	tx     store.Transactor
+	polls  store.Polls
+	posts  store.Posts
+	blocks store.Blocks
+}
+
+func NewPollService(tx store.Transactor, polls store.Polls, posts store.Posts, blocks store.Blocks) *PollService {
+	return &PollService{tx: tx, polls: polls, posts: posts, blocks: blocks}
+}
+
+// Vote casts userID's vote in the poll attached to a post and returns the
+// poll with its results. choice is an option's number, counting from 1, or
+// its text. Each user votes once, while the poll is open, and not in polls
+// by users blocked either way.
+func (s *PollService) Vote(userID, postID, choice string) (*models.Poll, error) {
+	post, err := s.posts.GetOriginal(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotBlocked(s.blocks, userID, post.AuthorID); err != nil {
+		return nil, err
+	}
+
+	poll, err := s.polls.Get(post.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Voted != nil {
+		return nil, store.ErrAlreadyVoted
+	}
+	if poll.Closed {
+		return nil, ErrPollClosed
+	}
+
+	position, err := chooseOption(poll, choice)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	if err := s.polls.Vote(post.ID, userID, position); err != nil {
+		return nil, err
+	}
+	return s.polls.Get(post.ID, userID)
+}
+
+// chooseOption finds the option choice names in poll, by number or text
+func chooseOption(poll *models.Poll, choice string) (int, error) {
+	choice = strings.TrimSpace(choice)
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(poll.Options) {
+			return 0, fmt.Errorf("no option %d: choose 1 to %d", n, len(poll.Options))
+		}
+		return n, nil
+	}
+
+	for _, option := range poll.Options {
+		if strings.EqualFold(option.Text, choice) {
+			return option.Position, nil
+		}
+	}
+	return 0, fmt.Errorf("no option %q in this poll", choice)
+}
+
+// NotifyClosed tells the author of every poll that had closed by now, and
+// that they haven't been told about, and returns the posts the polls are
+// attached to
+func (s *PollService) NotifyClosed(now time.Time) ([]models.Post, error) {
+	var closed []models.Post
+	for {
+		posts, err := s.polls.GetClosed(now.Unix(), closedBatch)
+		if err != nil || len(posts) == 0 {
+			return closed, err
+		}
+
+		for _, post := range posts {
+			err := s.tx.InTx(func(tx *store.Stores) error {
+				if err := tx.Notifications.Create(post.AuthorID, post.AuthorID, "poll", &post.ID); err != nil {
+					return err
+				}
+				return tx.Polls.MarkNotified(post.ID)
+			})
+			if err != nil {
+				return closed, err
+			}
+			closed = append(closed, post)
+		}
+	}
+}
@@ -0,0 +1,607 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/media"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
+)
+
+// NewPost is what a user submits when posting, replying or quoting
+type NewPost struct {
+	Text     string
+	ParentID *string  // Post to reply to; a retweet's ID means its original
+	QuotedID *string  // Post to quote; a post can't both reply and quote
+	Images   []string // Paths of images on local disk
+	Poll     *NewPoll
+}
+
+// NewPoll is a poll to attach to a new post
+type NewPoll struct {
+	Options  []string
+	Duration time.Duration // How long it stays open; zero means DefaultPollDuration
+}
+
+// DefaultPollDuration is how long a poll stays open unless told otherwise
+const DefaultPollDuration = 24 * time.Hour
+
+// PublishedPost describes a post after it was published
+type PublishedPost struct {
+	Post     *models.Post
+	Hashtags []string
+	Mentions []string // Usernames as written in the text
+	Media    []models.Media
+	Poll     *models.Poll
+}
+
+// PostService owns publishing, deleting and retweeting posts
+type PostService struct {
+	tx         store.Transactor
+	posts      store.Posts
+	users      store.Users
+	media      store.MediaFiles
+	polls      store.Polls
+	fanout     fanout
+	editWindow time.Duration
+	log        Logger
+}
+
+// DefaultEditWindow is how long after posting a post can be edited
+const DefaultEditWindow = time.Hour
+
+func NewPostService(tx store.Transactor, posts store.Posts, users store.Users, mediaFiles store.MediaFiles, polls store.Polls, opts Options, logger Logger) *PostService {
+	return &PostService{
+		tx:         tx,
+		posts:      posts,
+		users:      users,
+		media:      mediaFiles,
+		polls:      polls,
+		fanout:     fanout{opts},
+		editWindow: opts.EditWindow,
+		log:        logger,
+	}
+}
+
+// Publish creates a post, reply or quote for author, attaching images,
+// linking hashtags and mentions, notifying mentioned users and the parent's
+// or quoted post's author, and with fan-out-on-write, copying it into
+// followers' timelines. It all happens in one transaction: if any step
+// fails nothing is saved and copied images are removed again. Replying to
+// or quoting a user blocked either way fails with store.ErrBlocked, and
+// mentions of them are ignored.
+func (s *PostService) Publish(author *models.User, in NewPost) (*PublishedPost, error) {
+	published, err := s.PublishThread(author, []NewPost{in})
+	if err != nil {
+		return nil, err
+	}
+	return published[0], nil
+}
+
+// PublishThread publishes posts as a thread: the first as Publish would,
+// and each of the rest as a reply to the one before, whatever their
+// ParentID. The whole thread is published in one transaction, so if any
+// post fails none are saved.
+func (s *PostService) PublishThread(author *models.User, posts []NewPost) ([]*PublishedPost, error) {
+	if len(posts) == 0 {
+		return nil, invalid(errors.New("thread has no posts"))
+	}
+
+	posts = append([]NewPost(nil), posts...)
+	for i := range posts {
+		if err := checkNewPost(&posts[i]); err != nil {
+			if len(posts) > 1 {
+				err = fmt.Errorf("post %d of %d: %w", i+1, len(posts), err)
+			}
+			return nil, invalid(err)
+		}
+	}
+
+	return s.publishAll(author, posts, nil)
+}
+
+// publishAll publishes checked posts as a thread in one transaction. If
+// also isn't nil, it runs in the same transaction once they're published.
+func (s *PostService) publishAll(author *models.User, posts []NewPost, also func(tx *store.Stores) error) ([]*PublishedPost, error) {
+	// Files copied so far, to remove if the transaction rolls back
+	var copied []string
+
+	var published []*PublishedPost
+	err := s.tx.InTx(func(tx *store.Stores) error {
+		for i, in := range posts {
+			if i > 0 {
+				in.ParentID = &published[i-1].Post.ID
+				in.QuotedID = nil
+			}
+
+			result, err := s.publish(tx, author, in, &copied)
+			if err != nil {
+				return err
+			}
+			published = append(published, result)
+		}
+
+		if also != nil {
+			return also(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, path := range copied {
+			if err := media.DeleteMediaFile(path); err != nil {
+				s.log.Printf("failed to remove copied image %s: %v", path, err)
+			}
+		}
+		return nil, err
+	}
+
+	return published, nil
+}
+
+// checkNewPost sanitizes a new post's text and checks it's fit to publish
+func checkNewPost(in *NewPost) error {
+	in.Text = validation.SanitizePostText(in.Text)
+	if err := validation.ValidatePostText(in.Text); err != nil {
+		return err
+	}
+
+	if in.ParentID != nil && in.QuotedID != nil {
+		return errors.New("a post can't both reply to and quote a post")
+	}
+
+	// Validate images
+	if len(in.Images) > media.MaxImagesPerPost {
+		return fmt.Errorf("too many images (max %d)", media.MaxImagesPerPost)
+	}
+
+	for _, imgPath := range in.Images {
+		if err := media.ValidateImage(imgPath); err != nil {
+			return fmt.Errorf("invalid image %s: %w", imgPath, err)
+		}
+	}
+
+	if in.Poll != nil {
+		poll := NewPoll{Duration: in.Poll.Duration}
+		for _, option := range in.Poll.Options {
+			poll.Options = append(poll.Options, strings.TrimSpace(option))
+		}
+		if poll.Duration == 0 {
+			poll.Duration = DefaultPollDuration
+		}
+		if err := validation.ValidatePoll(poll.Options, poll.Duration); err != nil {
+			return err
+		}
+		in.Poll = &poll
+	}
+
+	return nil
+}
+
+// publish publishes one checked post within tx, adding the paths of images
+// it copies to copied
+func (s *PostService) publish(tx *store.Stores, author *models.User, in NewPost, copied *[]string) (*PublishedPost, error) {
+	text := in.Text
+	result := &PublishedPost{
+		Hashtags: parser.ExtractHashtags(text),
+		Mentions: parser.ExtractMentions(text),
+	}
+
+	var parent *models.Post
+	var err error
+	if in.ParentID != nil {
+		parent, err = tx.Posts.GetOriginal(*in.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNotBlocked(tx.Blocks, author.ID, parent.AuthorID); err != nil {
+			return nil, err
+		}
+	}
+
+	var quoted *models.Post
+	if in.QuotedID != nil {
+		quoted, err = tx.Posts.GetOriginal(*in.QuotedID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkNotBlocked(tx.Blocks, author.ID, quoted.AuthorID); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case parent != nil:
+		result.Post, err = tx.Posts.CreateReply(author.ID, text, parent.ID)
+	case quoted != nil:
+		result.Post, err = tx.Posts.CreateQuote(author.ID, text, quoted.ID)
+	default:
+		result.Post, err = tx.Posts.Create(author.ID, text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	postID := result.Post.ID
+
+	for i, imgPath := range in.Images {
+		destPath, fileName, err := media.CopyImageToMedia(imgPath, postID, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy image %s: %w", imgPath, err)
+		}
+		*copied = append(*copied, destPath)
+
+		m, err := mediaRecord(imgPath, postID, destPath, fileName, i)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Media.Create(m); err != nil {
+			return nil, err
+		}
+		result.Media = append(result.Media, *m)
+	}
+
+	if in.Poll != nil {
+		closesAt := time.Now().Add(in.Poll.Duration).Unix()
+		if err := tx.Polls.Create(postID, in.Poll.Options, closesAt); err != nil {
+			return nil, err
+		}
+		if result.Poll, err = tx.Polls.Get(postID, author.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Hashtags.LinkPostToHashtags(postID, result.Hashtags); err != nil {
+		return nil, err
+	}
+
+	if err := s.fanout.post(tx.Timelines, tx.Social, result.Post); err != nil {
+		return nil, err
+	}
+
+	notified, err := notifyMentions(tx, author.ID, postID, result.Mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notify the parent's author unless they were already notified of a mention
+	if parent != nil && !notified[parent.AuthorID] {
+		if err := tx.Notifications.Create(parent.AuthorID, author.ID, "reply", &postID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Likewise the quoted post's author
+	if quoted != nil && !notified[quoted.AuthorID] {
+		if err := tx.Notifications.Create(quoted.AuthorID, author.ID, "quote", &postID); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// mediaRecord describes an image copied into the media directory
+func mediaRecord(srcPath, postID, destPath, fileName string, position int) (*models.Media, error) {
+	fileInfo, err := os.Stat(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat image %s: %w", srcPath, err)
+	}
+
+	width, height, _ := media.GetImageDimensions(srcPath)
+	fileType, _ := media.GetFileType(srcPath)
+
+	return &models.Media{
+		PostID:   postID,
+		FilePath: destPath,
+		FileName: fileName,
+		FileType: fileType,
+		FileSize: fileInfo.Size(),
+		Width:    &width,
+		Height:   &height,
+		Position: position,
+	}, nil
+}
+
+// notifyMentions records mentions and notifies everyone mentioned except
+// the author. Users blocked either way are skipped. It returns the IDs of
+// the users notified.
+func notifyMentions(tx *store.Stores, authorID, postID string, usernames []string) (map[string]bool, error) {
+	mentionedIDs, err := mentionedUsers(tx, authorID, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Mentions.CreateMentions(postID, mentionedIDs); err != nil {
+		return nil, err
+	}
+
+	return notifyMentioned(tx, authorID, postID, mentionedIDs)
+}
+
+// mentionedUsers returns the IDs of the users usernames name, leaving out
+// those blocked by or blocking authorID
+func mentionedUsers(tx *store.Stores, authorID string, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	found, err := tx.Mentions.GetMentionedUsers(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	var mentionedIDs []string
+	for _, mentionedID := range found {
+		blocked, err := tx.Blocks.EitherBlocked(authorID, mentionedID)
+		if err != nil {
+			return nil, err
+		}
+		if !blocked {
+			mentionedIDs = append(mentionedIDs, mentionedID)
+		}
+	}
+
+	return mentionedIDs, nil
+}
+
+// notifyMentioned notifies users mentioned in a post, except its author,
+// and returns the IDs of the users notified
+func notifyMentioned(tx *store.Stores, authorID, postID string, mentionedIDs []string) (map[string]bool, error) {
+	notified := make(map[string]bool)
+	for _, mentionedID := range mentionedIDs {
+		if mentionedID == authorID {
+			continue
+		}
+		if err := tx.Notifications.Create(mentionedID, authorID, "mention", &postID); err != nil {
+			return nil, err
+		}
+		notified[mentionedID] = true
+	}
+
+	return notified, nil
+}
+
+// Edit replaces the text of one of author's posts, within the edit window
+// after it was posted. The text it replaces is kept in the post's history.
+// Hashtags and mentions are taken from the new text: only users it newly
+// mentions are notified, and mention notifications for users it no longer
+// mentions are retracted.
+func (s *PostService) Edit(author *models.User, postID, text string) (*PublishedPost, error) {
+	text = validation.SanitizePostText(text)
+	if err := validation.ValidatePostText(text); err != nil {
+		return nil, invalid(err)
+	}
+
+	window := s.editWindow
+	if window <= 0 {
+		window = DefaultEditWindow
+	}
+
+	result := &PublishedPost{
+		Hashtags: parser.ExtractHashtags(text),
+		Mentions: parser.ExtractMentions(text),
+	}
+
+	err := s.tx.InTx(func(tx *store.Stores) error {
+		post, err := tx.Posts.GetByID(postID)
+		if err != nil {
+			return err
+		}
+		if post.AuthorID != author.ID || post.IsRetweet {
+			return store.ErrNotPostOwner
+		}
+		if time.Since(time.Unix(post.CreatedAt, 0)) > window {
+			return ErrEditWindow
+		}
+		if post.Text == text {
+			return invalid(errors.New("the new text is the same as the old"))
+		}
+
+		if result.Post, err = tx.Posts.Edit(postID, author.ID, text); err != nil {
+			return err
+		}
+
+		if err := tx.Hashtags.UnlinkPost(postID); err != nil {
+			return err
+		}
+		if err := tx.Hashtags.LinkPostToHashtags(postID, result.Hashtags); err != nil {
+			return err
+		}
+
+		before, err := tx.Mentions.GetPostMentions(postID)
+		if err != nil {
+			return err
+		}
+		after, err := mentionedUsers(tx, author.ID, result.Mentions)
+		if err != nil {
+			return err
+		}
+		if err := tx.Mentions.DeleteMentions(postID); err != nil {
+			return err
+		}
+		if err := tx.Mentions.CreateMentions(postID, after); err != nil {
+			return err
+		}
+
+		for _, userID := range before {
+			if !slices.Contains(after, userID) {
+				if err := tx.Notifications.Retract(userID, author.ID, "mention", &postID); err != nil {
+					return err
+				}
+			}
+		}
+
+		var added []string
+		for _, userID := range after {
+			if !slices.Contains(before, userID) {
+				added = append(added, userID)
+			}
+		}
+		_, err = notifyMentioned(tx, author.ID, postID, added)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// History returns every version of a post's text, oldest first
+func (s *PostService) History(postID string) ([]models.PostVersion, error) {
+	post, err := s.posts.GetOriginal(postID)
+	if err != nil {
+		return nil, err
+	}
+	return s.posts.GetVersions(post.ID)
+}
+
+// Delete removes one of the user's posts along with its image files and
+// any notifications about it; deleting a retweet takes back the retweet
+// notification, as Unretweet does. The post and its notifications go in
+// one transaction; image files are removed once it commits. It returns the
+// number of images deleted.
+func (s *PostService) Delete(userID, postID string) (int, error) {
+	var mediaList []models.Media
+	err := s.tx.InTx(func(tx *store.Stores) error {
+		post, err := tx.Posts.GetByID(postID)
+		if err != nil {
+			return err
+		}
+
+		// Get media before deleting post
+		if mediaList, err = tx.Media.GetByPostID(postID); err != nil {
+			return err
+		}
+
+		if err := tx.Posts.Delete(postID, userID); err != nil {
+			return err
+		}
+
+		// A retweet's notification is about the original, not the retweet
+		if post.IsRetweet && post.OriginalPostID != nil {
+			original, err := tx.Posts.GetByID(*post.OriginalPostID)
+			if err != nil {
+				return err
+			}
+			return tx.Notifications.Retract(original.AuthorID, userID, "retweet", &original.ID)
+		}
+
+		return tx.Notifications.RetractTarget(postID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range mediaList {
+		if err := media.DeleteMediaFile(m.FilePath); err != nil {
+			s.log.Printf("failed to delete media file: %v", err)
+		}
+	}
+
+	return len(mediaList), nil
+}
+
+// Retweet retweets a post and notifies its author, unless either of them
+// has blocked the other. The retweet, its notification and its fan-out
+// happen in one transaction.
+func (s *PostService) Retweet(userID, postID string) (*models.Post, error) {
+	var retweet *models.Post
+	err := s.tx.InTx(func(tx *store.Stores) error {
+		original, err := tx.Posts.GetOriginal(postID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkNotBlocked(tx.Blocks, userID, original.AuthorID); err != nil {
+			return err
+		}
+
+		if retweet, err = tx.Posts.Retweet(userID, original.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Notifications.Create(original.AuthorID, userID, "retweet", &original.ID); err != nil {
+			return err
+		}
+
+		return s.fanout.post(tx.Timelines, tx.Social, retweet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retweet, nil
+}
+
+// Unretweet removes a user's retweet of a post and takes back the
+// notification it sent, in one transaction. postID may be the original or
+// any retweet of it.
+func (s *PostService) Unretweet(userID, postID string) error {
+	return s.tx.InTx(func(tx *store.Stores) error {
+		original, err := tx.Posts.GetOriginal(postID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Posts.Unretweet(userID, original.ID); err != nil {
+			return err
+		}
+
+		return tx.Notifications.Retract(original.AuthorID, userID, "retweet", &original.ID)
+	})
+}
+
+// detailQuotes is how many of the latest quotes Details lists
+const detailQuotes = 20
+
+// Details returns a post, or the original of a retweet, with its author,
+// engagement counts, media, poll and latest quotes, as viewerID sees them
+func (s *PostService) Details(postID, viewerID string) (*store.PostDetails, error) {
+	post, err := s.posts.GetOriginal(postID)
+	if err != nil {
+		return nil, err
+	}
+	postID = post.ID
+
+	author, err := s.users.GetByID(post.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaList, err := s.media.GetByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	pwa := []store.PostWithAuthor{{Post: *post, Username: author.Username}}
+	if err := s.posts.Resolve(pwa); err != nil {
+		return nil, err
+	}
+
+	// Resolve hides the results of open polls; viewerID may have voted
+	if poll := pwa[0].Poll; poll != nil {
+		if pwa[0].Poll, err = s.polls.Get(poll.PostID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+
+	quotes, err := s.posts.GetQuotes(postID, viewerID, store.Page{Limit: detailQuotes})
+	if err != nil {
+		return nil, err
+	}
+
+	return &store.PostDetails{
+		PostWithAuthor: pwa[0],
+		LikeCount:      post.LikeCount,
+		RetweetCount:   post.RetweetCount,
+		Media:          mediaList,
+		Quotes:         quotes,
+	}, nil
+}
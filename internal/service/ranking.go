@@ -0,0 +1,379 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// The ranked ("For You") feed is built in stages: sources gather candidate
+// posts from around the user's network, filters drop the ones that mustn't
+// be shown, scorers each weigh the rest, and the result is re-ranked so no
+// one author crowds the top. Each stage is an interface, so more can be
+// registered on a Ranker and tested on their own.
+
+const (
+	// rankWindow is how far back candidates are gathered from, and how long
+	// a post stays seen once shown
+	rankWindow = 7 * 24 * time.Hour
+
+	// candidatesPerSource caps how many posts each source contributes
+	candidatesPerSource = 200
+
+	// trendingTags is how many trending hashtags the trending source uses
+	trendingTags = 10
+
+	// DefaultDiversityDecay scales a post's score once for every post by the
+	// same author ranked above it
+	DefaultDiversityDecay = 0.5
+)
+
+// Candidate is a post the ranked feed might show, with the source that
+// found it and how it scored
+type Candidate struct {
+	store.PostWithAuthor
+	Source  string   `json:"source"`
+	Score   float64  `json:"score"`
+	Factors []Factor `json:"factors"`
+}
+
+// Factor is one scorer's contribution to a candidate's score
+type Factor struct {
+	Scorer string  `json:"scorer"`
+	Value  float64 `json:"value"`
+}
+
+// Signals is what the ranker knows about the user and their candidates.
+// UserID, Now, Since, Affinity and Trending are loaded before the sources
+// run; the per-candidate maps once the candidates are known.
+type Signals struct {
+	UserID   string
+	Now      time.Time
+	Since    int64           // Oldest candidate time
+	Affinity map[string]int  // Likes and replies the user has given each author
+	Trending []string        // Trending hashtags, most used first
+	Blocked  map[string]bool // Candidates by users blocked either way
+	Muted    map[string]bool // Candidates the user has muted
+	Seen     map[string]bool // Candidates already shown to the user
+}
+
+// Source gathers candidate posts for a user's ranked feed
+type Source interface {
+	Name() string
+	Candidates(sig *Signals, limit int) ([]store.PostWithAuthor, error)
+}
+
+// Scorer weighs a candidate. Scores multiply, so 1 is neutral.
+type Scorer interface {
+	Name() string
+	Score(c *Candidate, sig *Signals) float64
+}
+
+// Filter decides whether a candidate may be shown at all
+type Filter interface {
+	Name() string
+	Keep(c *Candidate, sig *Signals) bool
+}
+
+// Ranker builds ranked feeds from its registered sources, filters and
+// scorers
+type Ranker struct {
+	ranking  store.Ranking
+	hashtags store.Hashtags
+	sources  []Source
+	filters  []Filter
+	scorers  []Scorer
+
+	// DiversityDecay scales a post's score once for every post by the same
+	// author ranked above it. 1 turns diversity re-ranking off.
+	DiversityDecay float64
+}
+
+// NewRanker returns a ranker with no stages registered
+func NewRanker(ranking store.Ranking, hashtags store.Hashtags) *Ranker {
+	return &Ranker{ranking: ranking, hashtags: hashtags, DiversityDecay: DefaultDiversityDecay}
+}
+
+// DefaultRanker returns a ranker with the built-in sources, filters and
+// scorers registered
+func DefaultRanker(ranking store.Ranking, hashtags store.Hashtags) *Ranker {
+	r := NewRanker(ranking, hashtags)
+
+	r.AddSource(FollowingSource{ranking})
+	r.AddSource(EngagedSource{ranking})
+	r.AddSource(TrendingSource{ranking})
+	r.AddSource(NetworkSource{ranking})
+
+	r.AddFilter(BlockFilter{})
+	r.AddFilter(MuteFilter{})
+	r.AddFilter(SeenFilter{})
+
+	r.AddScorer(RecencyScorer{HalfLife: 6 * time.Hour})
+	r.AddScorer(EngagementScorer{})
+	r.AddScorer(AffinityScorer{})
+	r.AddScorer(MediaScorer{Boost: 1.2})
+
+	return r
+}
+
+// AddSource registers a source. Earlier sources win when two find the
+// same post.
+func (r *Ranker) AddSource(s Source) { r.sources = append(r.sources, s) }
+
+// AddFilter registers a filter
+func (r *Ranker) AddFilter(f Filter) { r.filters = append(r.filters, f) }
+
+// AddScorer registers a scorer
+func (r *Ranker) AddScorer(s Scorer) { r.scorers = append(r.scorers, s) }
+
+// Rank returns up to limit candidates for a user's ranked feed, best first
+func (r *Ranker) Rank(userID string, limit int) ([]Candidate, error) {
+	sig, err := r.signals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gather
+	var candidates []Candidate
+	found := make(map[string]bool)
+	for _, source := range r.sources {
+		posts, err := source.Candidates(sig, candidatesPerSource)
+		if err != nil {
+			return nil, err
+		}
+		for _, pwa := range posts {
+			if !found[pwa.Post.ID] {
+				found[pwa.Post.ID] = true
+				candidates = append(candidates, Candidate{PostWithAuthor: pwa, Source: source.Name()})
+			}
+		}
+	}
+
+	if err := r.candidateSignals(sig, candidates); err != nil {
+		return nil, err
+	}
+
+	// Filter and score
+	kept := candidates[:0]
+	for _, c := range candidates {
+		if r.keep(&c, sig) {
+			r.score(&c, sig)
+			kept = append(kept, c)
+		}
+	}
+
+	ranked := diversify(kept, r.DiversityDecay)
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// signals loads what the ranker knows about a user before gathering
+func (r *Ranker) signals(userID string) (*Signals, error) {
+	now := time.Now()
+	sig := &Signals{UserID: userID, Now: now, Since: now.Add(-rankWindow).Unix()}
+
+	var err error
+	if sig.Affinity, err = r.ranking.Affinity(userID); err != nil {
+		return nil, err
+	}
+
+	trending, err := r.hashtags.GetTrendingHashtags(trendingTags, sig.Since)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trending {
+		sig.Trending = append(sig.Trending, t.Tag)
+	}
+
+	return sig, nil
+}
+
+// candidateSignals loads what the ranker knows about the candidates
+func (r *Ranker) candidateSignals(sig *Signals, candidates []Candidate) error {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.Post.ID
+	}
+
+	var err error
+	if sig.Blocked, err = r.ranking.BlockedPosts(sig.UserID, ids); err != nil {
+		return err
+	}
+	if sig.Muted, err = r.ranking.MutedPosts(sig.UserID, ids); err != nil {
+		return err
+	}
+	if sig.Seen, err = r.ranking.SeenPosts(sig.UserID, ids); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Ranker) keep(c *Candidate, sig *Signals) bool {
+	for _, f := range r.filters {
+		if !f.Keep(c, sig) {
+			return false
+		}
+	}
+	return true
+}
+
+// score sets a candidate's score to the product of every scorer's
+func (r *Ranker) score(c *Candidate, sig *Signals) {
+	c.Score = 1
+	c.Factors = nil
+	for _, s := range r.scorers {
+		v := s.Score(c, sig)
+		c.Score *= v
+		c.Factors = append(c.Factors, Factor{Scorer: s.Name(), Value: v})
+	}
+}
+
+// diversify orders candidates best first, scaling each one's score by
+// decay for every post by the same author already placed above it
+func diversify(candidates []Candidate, decay float64) []Candidate {
+	placed := make(map[string]int)
+	adjusted := func(c Candidate) Candidate {
+		if n := placed[c.Post.AuthorID]; n > 0 {
+			v := math.Pow(decay, float64(n))
+			c.Score *= v
+			c.Factors = append(c.Factors[:len(c.Factors):len(c.Factors)], Factor{Scorer: "diversity", Value: v})
+		}
+		return c
+	}
+
+	rest := append([]Candidate(nil), candidates...)
+	ranked := make([]Candidate, 0, len(rest))
+	for len(rest) > 0 {
+		best := 0
+		for i := 1; i < len(rest); i++ {
+			if better(adjusted(rest[i]), adjusted(rest[best])) {
+				best = i
+			}
+		}
+
+		c := adjusted(rest[best])
+		placed[c.Post.AuthorID]++
+		ranked = append(ranked, c)
+		rest = append(rest[:best], rest[best+1:]...)
+	}
+
+	return ranked
+}
+
+// better reports whether a ranks above b: higher scores first, then newer
+func better(a, b Candidate) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.Post.CreatedAt != b.Post.CreatedAt {
+		return a.Post.CreatedAt > b.Post.CreatedAt
+	}
+	return a.Post.ID > b.Post.ID
+}
+
+// FollowingSource finds posts by the users the user follows
+type FollowingSource struct{ Ranking store.Ranking }
+
+func (FollowingSource) Name() string { return "following" }
+
+func (s FollowingSource) Candidates(sig *Signals, limit int) ([]store.PostWithAuthor, error) {
+	return s.Ranking.FollowedPosts(sig.UserID, sig.Since, limit)
+}
+
+// EngagedSource finds posts the users the user follows have liked or
+// retweeted
+type EngagedSource struct{ Ranking store.Ranking }
+
+func (EngagedSource) Name() string { return "liked_or_retweeted" }
+
+func (s EngagedSource) Candidates(sig *Signals, limit int) ([]store.PostWithAuthor, error) {
+	return s.Ranking.EngagedPosts(sig.UserID, sig.Since, limit)
+}
+
+// TrendingSource finds posts with trending hashtags
+type TrendingSource struct{ Ranking store.Ranking }
+
+func (TrendingSource) Name() string { return "trending" }
+
+func (s TrendingSource) Candidates(sig *Signals, limit int) ([]store.PostWithAuthor, error) {
+	return s.Ranking.TaggedPosts(sig.UserID, sig.Trending, sig.Since, limit)
+}
+
+// NetworkSource finds posts by users followed by the users the user
+// follows: second-degree follows
+type NetworkSource struct{ Ranking store.Ranking }
+
+func (NetworkSource) Name() string { return "network" }
+
+func (s NetworkSource) Candidates(sig *Signals, limit int) ([]store.PostWithAuthor, error) {
+	return s.Ranking.NetworkPosts(sig.UserID, sig.Since, limit)
+}
+
+// BlockFilter drops posts by users blocked either way
+type BlockFilter struct{}
+
+func (BlockFilter) Name() string { return "blocked" }
+
+func (BlockFilter) Keep(c *Candidate, sig *Signals) bool { return !sig.Blocked[c.Post.ID] }
+
+// MuteFilter drops posts the user has muted
+type MuteFilter struct{}
+
+func (MuteFilter) Name() string { return "muted" }
+
+func (MuteFilter) Keep(c *Candidate, sig *Signals) bool { return !sig.Muted[c.Post.ID] }
+
+// SeenFilter drops posts already shown in the user's ranked feed
+type SeenFilter struct{}
+
+func (SeenFilter) Name() string { return "seen" }
+
+func (SeenFilter) Keep(c *Candidate, sig *Signals) bool { return !sig.Seen[c.Post.ID] }
+
+// RecencyScorer halves a post's score every HalfLife since it was posted
+type RecencyScorer struct{ HalfLife time.Duration }
+
+func (RecencyScorer) Name() string { return "recency" }
+
+func (s RecencyScorer) Score(c *Candidate, sig *Signals) float64 {
+	age := sig.Now.Sub(time.Unix(c.Post.CreatedAt, 0))
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, age.Hours()/s.HalfLife.Hours())
+}
+
+// EngagementScorer favours posts with likes, retweets and replies, with
+// diminishing returns. Retweets and replies count double.
+type EngagementScorer struct{}
+
+func (EngagementScorer) Name() string { return "engagement" }
+
+func (EngagementScorer) Score(c *Candidate, sig *Signals) float64 {
+	engagement := c.Post.LikeCount + 2*c.Post.RetweetCount + 2*c.Post.ReplyCount
+	return 1 + math.Log1p(float64(engagement))
+}
+
+// AffinityScorer favours authors the user has liked or replied to before
+type AffinityScorer struct{}
+
+func (AffinityScorer) Name() string { return "affinity" }
+
+func (AffinityScorer) Score(c *Candidate, sig *Signals) float64 {
+	return 1 + 0.5*math.Log1p(float64(sig.Affinity[c.Post.AuthorID]))
+}
+
+// MediaScorer multiplies the score of posts with images by Boost
+type MediaScorer struct{ Boost float64 }
+
+func (MediaScorer) Name() string { return "media" }
+
+func (s MediaScorer) Score(c *Candidate, sig *Signals) float64 {
+	if c.Post.MediaCount > 0 {
+		return s.Boost
+	}
+	return 1
+}
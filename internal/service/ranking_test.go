@@ -0,0 +1,172 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+func candidate(id, authorID string, createdAt time.Time) Candidate {
+	return Candidate{PostWithAuthor: store.PostWithAuthor{
+		Post: models.Post{ID: id, AuthorID: authorID, CreatedAt: createdAt.Unix()},
+	}}
+}
+
+func TestScorers(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	sig := &Signals{Now: now, Affinity: map[string]int{"fan": 3}}
+
+	recency := RecencyScorer{HalfLife: 6 * time.Hour}
+	fresh, old := candidate("a", "x", now), candidate("b", "x", now.Add(-12*time.Hour))
+	if got := recency.Score(&fresh, sig); got != 1 {
+		t.Errorf("expected a new post to keep its score, got %v", got)
+	}
+	if got := recency.Score(&old, sig); got < 0.249 || got > 0.251 {
+		t.Errorf("expected two half-lives to quarter the score, got %v", got)
+	}
+
+	engagement := EngagementScorer{}
+	quiet, busy := candidate("a", "x", now), candidate("b", "x", now)
+	busy.Post.LikeCount, busy.Post.ReplyCount = 5, 1
+	if engagement.Score(&quiet, sig) != 1 || engagement.Score(&busy, sig) <= 1 {
+		t.Errorf("expected engagement to favour the busy post")
+	}
+
+	affinity := AffinityScorer{}
+	stranger, friend := candidate("a", "x", now), candidate("b", "fan", now)
+	if affinity.Score(&stranger, sig) != 1 || affinity.Score(&friend, sig) <= 1 {
+		t.Errorf("expected affinity to favour an author the user has engaged with")
+	}
+
+	media := MediaScorer{Boost: 1.2}
+	plain, pictured := candidate("a", "x", now), candidate("b", "x", now)
+	pictured.Post.MediaCount = 2
+	if media.Score(&plain, sig) != 1 || media.Score(&pictured, sig) != 1.2 {
+		t.Errorf("expected only the post with images to be boosted")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	c := candidate("a", "x", time.Now())
+	sig := &Signals{}
+	for _, f := range []Filter{BlockFilter{}, MuteFilter{}, SeenFilter{}} {
+		if !f.Keep(&c, sig) {
+			t.Errorf("expected %s to keep an unmarked post", f.Name())
+		}
+	}
+
+	for _, tc := range []struct {
+		filter Filter
+		sig    *Signals
+	}{
+		{BlockFilter{}, &Signals{Blocked: map[string]bool{"a": true}}},
+		{MuteFilter{}, &Signals{Muted: map[string]bool{"a": true}}},
+		{SeenFilter{}, &Signals{Seen: map[string]bool{"a": true}}},
+	} {
+		if tc.filter.Keep(&c, tc.sig) {
+			t.Errorf("expected %s to drop the post", tc.filter.Name())
+		}
+	}
+}
+
+func TestDiversify(t *testing.T) {
+	now := time.Now()
+	var candidates []Candidate
+	for _, c := range []struct {
+		id, author string
+		score      float64
+	}{{"a1", "a", 10}, {"a2", "a", 9}, {"a3", "a", 8}, {"b1", "b", 6}} {
+		cand := candidate(c.id, c.author, now)
+		cand.Score = c.score
+		candidates = append(candidates, cand)
+	}
+
+	var got []string
+	for _, c := range diversify(candidates, 0.5) {
+		got = append(got, c.Post.ID)
+	}
+
+	// a2 drops to 4.5 behind b1; a3 to 2
+	want := []string{"a1", "b1", "a2", "a3"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// Without decay it's plain score order
+	got = got[:0]
+	for _, c := range diversify(candidates, 1) {
+		got = append(got, c.Post.ID)
+	}
+	if got[3] != "b1" {
+		t.Errorf("expected b1 last without decay, got %v", got)
+	}
+}
+
+// constScorer scores every post the same, to check registration
+type constScorer float64
+
+func (constScorer) Name() string                               { return "const" }
+func (s constScorer) Score(c *Candidate, sig *Signals) float64 { return float64(s) }
+
+func TestRanked(t *testing.T) {
+	_, _, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+	carol := register(t, services, "carol")
+	dave := register(t, services, "dave")
+
+	services.Social.Follow(alice.ID, "bob")
+	services.Social.Follow(bob.ID, "carol")
+
+	fromBob, _ := services.Posts.Publish(bob, NewPost{Text: "from bob"})
+	fromCarol, _ := services.Posts.Publish(carol, NewPost{Text: "from carol"})
+	liked, _ := services.Posts.Publish(dave, NewPost{Text: "liked by bob"})
+	services.Posts.Publish(dave, NewPost{Text: "nobody saw this"})
+	services.Social.Like(bob.ID, liked.Post.ID)
+	services.Posts.Publish(alice, NewPost{Text: "my own"})
+
+	services.Feed.Ranker.AddScorer(constScorer(2))
+	ranked, err := services.Feed.Ranked(alice.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to rank: %v", err)
+	}
+
+	sources := make(map[string]string)
+	for _, c := range ranked {
+		sources[c.Post.ID] = c.Source
+		if f := c.Factors[len(c.Factors)-1]; f.Scorer != "const" || f.Value != 2 {
+			t.Errorf("expected the registered scorer last, got %+v", c.Factors)
+		}
+	}
+	want := map[string]string{
+		fromBob.Post.ID:   "following",
+		liked.Post.ID:     "liked_or_retweeted",
+		fromCarol.Post.ID: "network",
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("expected %d candidates, got %v", len(want), sources)
+	}
+	for id, source := range want {
+		if sources[id] != source {
+			t.Errorf("expected %s from %s, got %q", id, source, sources[id])
+		}
+	}
+
+	// Shown posts aren't shown again, until reset
+	if again, _ := services.Feed.Ranked(alice.ID, 10); len(again) != 0 {
+		t.Errorf("expected seen posts to be filtered, got %d", len(again))
+	}
+	services.Feed.ResetSeen(alice.ID)
+
+	// Blocked and muted authors are filtered
+	services.Blocks.Block(alice.ID, "carol")
+	services.Mutes.Mute(alice.ID, "@dave", 0)
+	ranked, _ = services.Feed.Ranked(alice.ID, 10)
+	if len(ranked) != 1 || ranked[0].Post.ID != fromBob.Post.ID {
+		t.Errorf("expected only bob's post, got %+v", ranked)
+	}
+}
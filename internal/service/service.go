@@ -0,0 +1,92 @@
+// Package service holds the application's business rules: who gets notified
+// when something is posted, who may message whom, what following and
+// blocking mean. The CLI and the API server both call into it instead of
+// composing stores themselves.
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// Logger receives warnings about best-effort side effects, such as a
+// notification that couldn't be created. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Rule violations the services report. Store errors such as
+// store.ErrUserNotFound are passed through unchanged.
+var (
+	ErrMessageSelf = errors.New("you cannot message yourself")
+	ErrBlockSelf   = errors.New("you cannot block yourself")
+	ErrMuteSelf    = errors.New("you cannot mute yourself")
+	ErrEditWindow  = errors.New("this post can no longer be edited")
+	ErrPollClosed  = errors.New("this poll has closed")
+)
+
+// ValidationError reports input that was rejected before anything was
+// written, such as an empty post or an unreadable image
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func invalid(err error) error {
+	return &ValidationError{Err: err}
+}
+
+// Options configures the services. The zero value builds feeds on read.
+type Options struct {
+	Fanout Fanout
+
+	// FanoutLimit is, with FanoutOnWrite, the follower count above which a
+	// user's posts aren't copied into timelines but pulled when feeds are
+	// read. Zero means DefaultFanoutLimit.
+	FanoutLimit int
+
+	// EditWindow is how long after posting a post can be edited. Zero
+	// means DefaultEditWindow.
+	EditWindow time.Duration
+}
+
+// Services groups every service, wired to the same stores
+type Services struct {
+	Users         *UserService
+	Posts         *PostService
+	Drafts        *DraftService
+	Polls         *PollService
+	Feed          *FeedService
+	Social        *SocialService
+	Messages      *MessageService
+	Blocks        *BlockService
+	Mutes         *MuteService
+	Notifications *NotificationService
+}
+
+// New wires the services to stores, sending warnings to logger
+func New(stores *store.Stores, logger Logger, opts Options) *Services {
+	posts := NewPostService(stores, stores.Posts, stores.Users, stores.Media, stores.Polls, opts, logger)
+
+	return &Services{
+		Users:         NewUserService(stores.Users, stores.Sessions, stores.Posts, stores.Social, stores.Messages),
+		Posts:         posts,
+		Drafts:        NewDraftService(stores, stores.Drafts, stores.Users, posts, logger),
+		Polls:         NewPollService(stores, stores.Polls, stores.Posts, stores.Blocks),
+		Feed:          NewFeedService(stores.Posts, stores.Timelines, stores.Ranking, stores.Hashtags, opts),
+		Social:        NewSocialService(stores.Social, stores.Users, stores.Posts, stores.Notifications, stores.Timelines, opts, logger),
+		Messages:      NewMessageService(stores.Messages, stores.Users, stores.Blocks, stores.Notifications, logger),
+		Blocks:        NewBlockService(stores.Blocks, stores.Users, stores.Social, stores.Timelines, opts, logger),
+		Mutes:         NewMuteService(stores.Mutes, stores.Users),
+		Notifications: NewNotificationService(stores.Notifications),
+	}
+}
@@ -0,0 +1,744 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/auth"
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/media"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+func setupServices(t testing.TB) (*sql.DB, *store.Stores, *Services) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	stores := store.NewStores(database)
+	return database, stores, New(stores, log.New(io.Discard, "", 0), Options{})
+}
+
+func register(t testing.TB, services *Services, username string) *models.User {
+	user, err := services.Users.Register(username, "hunter22")
+	if err != nil {
+		t.Fatalf("failed to register %s: %v", username, err)
+	}
+	return user
+}
+
+func TestClaimAccount(t *testing.T) {
+	_, stores, services := setupServices(t)
+
+	// Accounts from before passwords existed have no hash
+	if _, err := stores.Users.Create("legacy"); err != nil {
+		t.Fatalf("failed to create legacy user: %v", err)
+	}
+
+	for _, password := range []string{"", "anything1"} {
+		if user, err := services.Users.Authenticate("legacy", password); !errors.Is(err, auth.ErrWrongPassword) || user != nil {
+			t.Errorf("expected ErrWrongPassword and no user before claiming, got %v, %v", user, err)
+		}
+	}
+
+	// Without the old login saved on this machine nobody can claim it, and
+	// unknown usernames look the same
+	for _, tc := range []struct{ username, legacyUser string }{
+		{"legacy", ""},
+		{"legacy", "mallory"},
+		{"nobody", "nobody"},
+	} {
+		if _, err := services.Users.ClaimAccount(tc.username, tc.legacyUser, "takeover1"); !errors.Is(err, ErrClaimDenied) {
+			t.Errorf("expected ErrClaimDenied claiming %s as %q, got %v", tc.username, tc.legacyUser, err)
+		}
+	}
+
+	if _, err := services.Users.ClaimAccount("legacy", "legacy", "hunter22"); err != nil {
+		t.Fatalf("failed to claim: %v", err)
+	}
+	if _, err := services.Users.ClaimAccount("legacy", "legacy", "takeover1"); !errors.Is(err, ErrHasPassword) {
+		t.Errorf("expected ErrHasPassword claiming twice, got %v", err)
+	}
+	if _, err := services.Users.Authenticate("legacy", "hunter22"); err != nil {
+		t.Errorf("expected to log in after claiming, got %v", err)
+	}
+}
+
+func TestPublish_NotifiesOncePerUser(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+
+	parent, err := services.Posts.Publish(alice, NewPost{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	// Bob replies to and mentions alice, and mentions himself
+	reply, err := services.Posts.Publish(bob, NewPost{Text: "hi @alice, @bob here", ParentID: &parent.Post.ID})
+	if err != nil {
+		t.Fatalf("failed to reply: %v", err)
+	}
+	if len(reply.Mentions) != 2 {
+		t.Errorf("expected 2 mentions, got %v", reply.Mentions)
+	}
+
+	notifications, err := stores.Notifications.GetNotifications(alice.ID, false, store.Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get notifications: %v", err)
+	}
+	if len(notifications) != 1 || notifications[0].Notification.Type != "mention" {
+		t.Errorf("expected one mention notification for alice, got %+v", notifications)
+	}
+
+	count, err := stores.Notifications.GetUnreadCount(bob.ID)
+	if err != nil {
+		t.Fatalf("failed to count notifications: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no notifications for bob, got %d", count)
+	}
+
+	if _, err := services.Posts.Publish(alice, NewPost{Text: "   "}); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error for an empty post, got %v", err)
+	}
+}
+
+func TestSendMessage_Blocked(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+
+	if _, err := services.Blocks.Block(alice.ID, "bob"); err != nil {
+		t.Fatalf("failed to block: %v", err)
+	}
+
+	if _, err := services.Messages.Send(bob.ID, "alice", "hello?"); !errors.Is(err, store.ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+
+	// Nothing should have been stored for the blocked message
+	count, err := stores.Messages.GetUnreadCount(alice.ID)
+	if err != nil {
+		t.Fatalf("failed to count messages: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no messages for alice, got %d", count)
+	}
+
+	if _, err := services.Messages.Send(alice.ID, "alice", "me"); !errors.Is(err, ErrMessageSelf) {
+		t.Errorf("expected ErrMessageSelf, got %v", err)
+	}
+}
+
+func TestConversation_MarksReadOnFirstPage(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+
+	for _, text := range []string{"one", "two", "three"} {
+		if _, err := services.Messages.Send(bob.ID, "alice", text); err != nil {
+			t.Fatalf("failed to send message: %v", err)
+		}
+	}
+
+	unread := func() int {
+		t.Helper()
+		count, err := stores.Messages.GetUnreadCount(alice.ID)
+		if err != nil {
+			t.Fatalf("failed to count messages: %v", err)
+		}
+		return count
+	}
+
+	// An older page leaves the newer messages alice hasn't seen unread
+	oldest, _ := stores.Messages.GetConversation(alice.ID, bob.ID, store.Page{Limit: 1})
+	cursor := oldest[0].Cursor()
+	if _, err := services.Messages.Conversation(alice.ID, "bob", store.Page{Before: &cursor, Limit: 1}); err != nil {
+		t.Fatalf("failed to read conversation: %v", err)
+	}
+	if count := unread(); count != 3 {
+		t.Errorf("expected an older page to leave 3 unread, got %d", count)
+	}
+
+	if _, err := services.Messages.Conversation(alice.ID, "bob", store.Page{Limit: 1}); err != nil {
+		t.Fatalf("failed to read conversation: %v", err)
+	}
+	if count := unread(); count != 0 {
+		t.Errorf("expected the first page to mark the conversation read, got %d unread", count)
+	}
+}
+
+func TestPublish_Blocked(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+	register(t, services, "carol")
+
+	parent, err := services.Posts.Publish(alice, NewPost{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	if _, err := services.Blocks.Block(alice.ID, "bob"); err != nil {
+		t.Fatalf("failed to block: %v", err)
+	}
+
+	if _, err := services.Posts.Publish(bob, NewPost{Text: "hi", ParentID: &parent.Post.ID}); !errors.Is(err, store.ErrBlocked) {
+		t.Errorf("expected ErrBlocked replying to the blocker, got %v", err)
+	}
+	if _, err := services.Posts.Retweet(bob.ID, parent.Post.ID); !errors.Is(err, store.ErrBlocked) {
+		t.Errorf("expected ErrBlocked retweeting the blocker, got %v", err)
+	}
+
+	// Mentioning the blocker still posts, but doesn't reach them
+	if _, err := services.Posts.Publish(bob, NewPost{Text: "@alice @carol look"}); err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	mentions, err := stores.Mentions.GetMentions(alice.ID, store.Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get mentions: %v", err)
+	}
+	if len(mentions) != 0 {
+		t.Errorf("expected no mentions for alice, got %+v", mentions)
+	}
+
+	count, err := stores.Notifications.GetUnreadCount(alice.ID)
+	if err != nil {
+		t.Fatalf("failed to count notifications: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no notifications for alice, got %d", count)
+	}
+}
+
+func TestPublish_RollsBackOnFailure(t *testing.T) {
+	// Keep copied images out of the real media directory
+	t.Setenv("HOME", t.TempDir())
+
+	database, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	register(t, services, "bob")
+
+	imgPath := filepath.Join(t.TempDir(), "pixel.png")
+	f, err := os.Create(imgPath)
+	if err != nil {
+		t.Fatalf("failed to create image: %v", err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	f.Close()
+
+	// Make the last step, notifying @bob, fail
+	if _, err := database.Exec(`DROP TABLE notifications`); err != nil {
+		t.Fatalf("failed to drop notifications: %v", err)
+	}
+
+	_, err = services.Posts.Publish(alice, NewPost{Text: "hi @bob #rollback", Images: []string{imgPath}})
+	if err == nil {
+		t.Fatal("expected publish to fail")
+	}
+
+	count, err := stores.Posts.CountByAuthor(alice.ID)
+	if err != nil {
+		t.Fatalf("failed to count posts: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected the post to be rolled back, found %d", count)
+	}
+
+	var links int
+	if err := database.QueryRow(`SELECT COUNT(*) FROM post_hashtags`).Scan(&links); err != nil {
+		t.Fatalf("failed to count hashtag links: %v", err)
+	}
+	if links != 0 {
+		t.Errorf("expected hashtag links to be rolled back, found %d", links)
+	}
+
+	files, _ := os.ReadDir(media.GetMediaDir())
+	if len(files) != 0 {
+		t.Errorf("expected copied images to be removed, found %d", len(files))
+	}
+}
+
+func TestDelete_RollsBackOnFailure(t *testing.T) {
+	database, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+
+	published, err := services.Posts.Publish(alice, NewPost{Text: "keep me"})
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	// Make retracting the post's notifications fail
+	if _, err := database.Exec(`DROP TABLE notifications`); err != nil {
+		t.Fatalf("failed to drop notifications: %v", err)
+	}
+
+	if _, err := services.Posts.Delete(alice.ID, published.Post.ID); err == nil {
+		t.Fatal("expected delete to fail")
+	}
+
+	if _, err := stores.Posts.GetByID(published.Post.ID); err != nil {
+		t.Errorf("expected the delete to be rolled back, got %v", err)
+	}
+}
+
+func TestFeed_FanoutOnWrite(t *testing.T) {
+	database, stores, reads := setupServices(t)
+	writes := New(stores, log.New(io.Discard, "", 0), Options{Fanout: FanoutOnWrite, FanoutLimit: 2})
+
+	alice := register(t, writes, "alice")
+	bob := register(t, writes, "bob")
+	star := register(t, writes, "star")
+	var fans []*models.User
+	for _, name := range []string{"fan1", "fan2"} {
+		fans = append(fans, register(t, writes, name))
+	}
+
+	// Both ways of building the feed must agree after every change
+	same := func(step string, user *models.User, want int) {
+		t.Helper()
+		read, err := reads.Feed.Feed(user.ID, store.Page{})
+		if err != nil {
+			t.Fatalf("%s: failed to read feed: %v", step, err)
+		}
+		written, err := writes.Feed.Feed(user.ID, store.Page{})
+		if err != nil {
+			t.Fatalf("%s: failed to read timeline: %v", step, err)
+		}
+		if len(read) != want || len(written) != want {
+			t.Fatalf("%s: expected %d posts, got %d on read and %d on write", step, want, len(read), len(written))
+		}
+		for i := range read {
+			if read[i].Post.ID != written[i].Post.ID {
+				t.Fatalf("%s: feeds differ at %d: %s vs %s", step, i, read[i].Post.Text, written[i].Post.Text)
+			}
+		}
+	}
+
+	writes.Posts.Publish(bob, NewPost{Text: "before the follow"})
+	writes.Social.Follow(alice.ID, "bob")
+	same("backfill on follow", alice, 1)
+
+	writes.Posts.Publish(bob, NewPost{Text: "after the follow"})
+	writes.Posts.Publish(alice, NewPost{Text: "my own"})
+	same("fan out on post", alice, 3)
+
+	// star goes over the limit, so their posts are pulled on read
+	writes.Social.Follow(alice.ID, "star")
+	for _, fan := range fans {
+		writes.Social.Follow(fan.ID, "star")
+	}
+	pulled, _ := writes.Posts.Publish(star, NewPost{Text: "hello fans"})
+	var copies int
+	database.QueryRow(`SELECT COUNT(*) FROM timelines WHERE post_id = ?`, pulled.Post.ID).Scan(&copies)
+	if copies != 1 {
+		t.Errorf("expected the post only in star's own timeline, found %d copies", copies)
+	}
+	same("pull over the limit", alice, 4)
+	same("pull over the limit", fans[0], 1)
+
+	// Dropping back to the limit copies star's posts into timelines again
+	writes.Social.Unfollow(fans[1].ID, "star")
+	same("back under the limit", alice, 4)
+	same("back under the limit", fans[1], 0)
+
+	writes.Blocks.Block(alice.ID, "bob")
+	same("block", alice, 2)
+
+	writes.Posts.Delete(star.ID, pulled.Post.ID)
+	same("delete", alice, 1)
+
+	if err := writes.Feed.Rebuild(); err != nil {
+		t.Fatalf("failed to rebuild timelines: %v", err)
+	}
+	same("rebuild", alice, 1)
+	same("rebuild", fans[0], 0)
+}
+
+func TestPublish_Quote(t *testing.T) {
+	database, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+
+	original, _ := services.Posts.Publish(alice, NewPost{Text: "hello"})
+	quote, err := services.Posts.Publish(bob, NewPost{Text: "well said", QuotedID: &original.Post.ID})
+	if err != nil {
+		t.Fatalf("failed to quote: %v", err)
+	}
+
+	notifications, _ := stores.Notifications.GetNotifications(alice.ID, false, store.Page{Limit: 10})
+	if len(notifications) != 1 || notifications[0].Notification.Type != "quote" {
+		t.Errorf("expected one quote notification for alice, got %+v", notifications)
+	}
+
+	details, err := services.Posts.Details(original.Post.ID, alice.ID)
+	if err != nil {
+		t.Fatalf("failed to get details: %v", err)
+	}
+	if details.Post.QuoteCount != 1 || len(details.Quotes) != 1 || details.Quotes[0].Post.ID != quote.Post.ID {
+		t.Errorf("expected the quote listed on the original, got %d: %+v", details.Post.QuoteCount, details.Quotes)
+	}
+
+	if _, err := services.Posts.Publish(bob, NewPost{Text: "both", ParentID: &original.Post.ID, QuotedID: &original.Post.ID}); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error for a reply that quotes, got %v", err)
+	}
+
+	// The quote outlives the original
+	if _, err := services.Posts.Delete(alice.ID, original.Post.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	details, err = services.Posts.Details(quote.Post.ID, bob.ID)
+	if err != nil {
+		t.Fatalf("failed to get details: %v", err)
+	}
+	if details.Quoted == nil || !details.Quoted.Deleted {
+		t.Errorf("expected the quoted post marked deleted, got %+v", details.Quoted)
+	}
+
+	drift, err := db.ReconcileCounters(database, false)
+	if err != nil || len(drift) != 0 {
+		t.Errorf("expected no counter drift, got %+v (%v)", drift, err)
+	}
+}
+
+func TestRetweetID_ActsOnOriginal(t *testing.T) {
+	database, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+	carol := register(t, services, "carol")
+
+	original, _ := services.Posts.Publish(alice, NewPost{Text: "hello"})
+	retweet, err := services.Posts.Retweet(bob.ID, original.Post.ID)
+	if err != nil {
+		t.Fatalf("failed to retweet: %v", err)
+	}
+
+	// Liking and replying via the retweet land on alice's post, not bob's
+	if err := services.Social.Like(carol.ID, retweet.ID); err != nil {
+		t.Fatalf("failed to like via retweet: %v", err)
+	}
+	reply, err := services.Posts.Publish(carol, NewPost{Text: "hi back", ParentID: &retweet.ID})
+	if err != nil {
+		t.Fatalf("failed to reply via retweet: %v", err)
+	}
+	if reply.Post.ParentPostID == nil || *reply.Post.ParentPostID != original.Post.ID {
+		t.Errorf("expected the reply attached to the original, got %v", reply.Post.ParentPostID)
+	}
+
+	post, _ := stores.Posts.GetByID(original.Post.ID)
+	if post.LikeCount != 1 || post.ReplyCount != 1 {
+		t.Errorf("expected the original liked and replied to, got %d likes and %d replies", post.LikeCount, post.ReplyCount)
+	}
+	if liked, _ := stores.Social.HasLiked(carol.ID, retweet.ID); liked {
+		t.Error("expected no like recorded on the retweet")
+	}
+
+	notifications, _ := stores.Notifications.GetNotifications(alice.ID, false, store.Page{Limit: 10})
+	var types []string
+	for _, n := range notifications {
+		types = append(types, n.Notification.Type)
+	}
+	if len(types) != 3 {
+		t.Errorf("expected alice notified of the retweet, like and reply, got %v", types)
+	}
+	if bobs, _ := stores.Notifications.GetNotifications(bob.ID, false, store.Page{Limit: 10}); len(bobs) != 0 {
+		t.Errorf("expected nothing for the retweeter, got %+v", bobs)
+	}
+
+	if err := services.Social.Unlike(carol.ID, retweet.ID); err != nil {
+		t.Fatalf("failed to unlike via retweet: %v", err)
+	}
+	if liked, _ := stores.Social.HasLiked(carol.ID, original.Post.ID); liked {
+		t.Error("expected the like on the original removed")
+	}
+
+	// Showing the retweet shows the original with its counts and quotes
+	services.Posts.Publish(carol, NewPost{Text: "quoting", QuotedID: &original.Post.ID})
+	details, err := services.Posts.Details(retweet.ID, carol.ID)
+	if err != nil {
+		t.Fatalf("failed to get details via retweet: %v", err)
+	}
+	if details.Post.ID != original.Post.ID || details.RetweetCount != 1 || details.Post.ReplyCount != 1 || len(details.Quotes) != 1 {
+		t.Errorf("expected the original's details, got %+v", details)
+	}
+
+	// Deleting the retweet takes back its notification like unretweeting
+	if _, err := services.Posts.Delete(bob.ID, retweet.ID); err != nil {
+		t.Fatalf("failed to delete retweet: %v", err)
+	}
+	notifications, _ = stores.Notifications.GetNotifications(alice.ID, false, store.Page{Limit: 10})
+	for _, n := range notifications {
+		if n.Notification.Type == "retweet" {
+			t.Errorf("expected the retweet notification retracted, got %+v", n)
+		}
+	}
+	if post, _ := stores.Posts.GetByID(original.Post.ID); post.RetweetCount != 0 {
+		t.Errorf("expected no retweets left, got %d", post.RetweetCount)
+	}
+
+	drift, err := db.ReconcileCounters(database, false)
+	if err != nil || len(drift) != 0 {
+		t.Errorf("expected no counter drift, got %+v (%v)", drift, err)
+	}
+}
+
+func TestPublishThread(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+
+	published, err := services.Posts.PublishThread(alice, []NewPost{{Text: "one"}, {Text: "two"}, {Text: "three"}})
+	if err != nil {
+		t.Fatalf("failed to publish thread: %v", err)
+	}
+	for i, p := range published[1:] {
+		if parent := p.Post.ParentPostID; parent == nil || *parent != published[i].Post.ID {
+			t.Errorf("expected post %d to reply to the one before, got %v", i+2, parent)
+		}
+	}
+
+	// An invalid part publishes none of them
+	_, err = services.Posts.PublishThread(alice, []NewPost{{Text: "fine"}, {Text: strings.Repeat("x", 300)}})
+	if !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+	posts, _ := stores.Posts.GetByAuthorID(alice.ID, store.Page{Limit: 10})
+	if len(posts) != 3 {
+		t.Errorf("expected only the first thread's 3 posts, got %d", len(posts))
+	}
+}
+
+func TestEditPost(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+	carol := register(t, services, "carol")
+
+	post, _ := services.Posts.Publish(alice, NewPost{Text: "hi @bob #first"})
+	edited, err := services.Posts.Edit(alice, post.Post.ID, "hi @bob and @carol #second")
+	if err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	if edited.Post.EditedAt == nil {
+		t.Errorf("expected the post marked edited")
+	}
+
+	for tag, want := range map[string]int{"first": 0, "second": 1} {
+		posts, _ := stores.Hashtags.GetPostsByHashtag(tag, alice.ID, store.Page{Limit: 10})
+		if len(posts) != want {
+			t.Errorf("expected %d post(s) with #%s, got %d", want, tag, len(posts))
+		}
+	}
+
+	// bob was already mentioned, so only carol hears about it
+	for user, want := range map[*models.User]int{bob: 1, carol: 1} {
+		notifications, _ := stores.Notifications.GetNotifications(user.ID, false, store.Page{Limit: 10})
+		if len(notifications) != want {
+			t.Errorf("expected %d notification(s) for @%s, got %d", want, user.Username, len(notifications))
+		}
+	}
+
+	// Dropping a mention takes back its notification
+	if _, err := services.Posts.Edit(alice, post.Post.ID, "hi @carol"); err != nil {
+		t.Fatalf("failed to edit: %v", err)
+	}
+	if notifications, _ := stores.Notifications.GetNotifications(bob.ID, false, store.Page{Limit: 10}); len(notifications) != 0 {
+		t.Errorf("expected bob's mention retracted, got %+v", notifications)
+	}
+
+	versions, err := services.Posts.History(post.Post.ID)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	if len(versions) != 3 || versions[0].Text != "hi @bob #first" || versions[2].Text != "hi @carol" {
+		t.Errorf("expected 3 versions, oldest first, got %+v", versions)
+	}
+
+	if _, err := services.Posts.Edit(bob, post.Post.ID, "mine now"); !errors.Is(err, store.ErrNotPostOwner) {
+		t.Errorf("expected ErrNotPostOwner editing someone else's post, got %v", err)
+	}
+
+	strict := New(stores, log.New(io.Discard, "", 0), Options{EditWindow: time.Nanosecond})
+	time.Sleep(time.Second) // Post times are in seconds
+	if _, err := strict.Posts.Edit(alice, post.Post.ID, "too late"); !errors.Is(err, ErrEditWindow) {
+		t.Errorf("expected ErrEditWindow after the window, got %v", err)
+	}
+}
+
+func TestPolls(t *testing.T) {
+	database, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+	carol := register(t, services, "carol")
+
+	if _, err := services.Posts.Publish(alice, NewPost{Text: "pick", Poll: &NewPoll{Options: []string{"a", "A"}}}); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error for repeated options, got %v", err)
+	}
+
+	post, err := services.Posts.Publish(alice, NewPost{Text: "Which DB?", Poll: &NewPoll{Options: []string{"SQLite", " Postgres ", "DuckDB"}}})
+	if err != nil {
+		t.Fatalf("failed to publish poll: %v", err)
+	}
+	if post.Poll == nil || post.Poll.Options[1].Text != "Postgres" || post.Poll.Options[1].Votes != nil {
+		t.Fatalf("expected an open poll with hidden results, got %+v", post.Poll)
+	}
+
+	poll, err := services.Polls.Vote(bob.ID, post.Post.ID, "postgres")
+	if err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	if poll.Voted == nil || *poll.Voted != 2 || poll.Options[1].Votes == nil || *poll.Options[1].Votes != 1 {
+		t.Errorf("expected bob's vote for option 2 and the results, got %+v", poll)
+	}
+	if _, err := services.Polls.Vote(bob.ID, post.Post.ID, "1"); !errors.Is(err, store.ErrAlreadyVoted) {
+		t.Errorf("expected ErrAlreadyVoted voting twice, got %v", err)
+	}
+	if _, err := services.Polls.Vote(carol.ID, post.Post.ID, "4"); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error for a missing option, got %v", err)
+	}
+
+	// Carol hasn't voted, so she can't see the results yet
+	details, _ := services.Posts.Details(post.Post.ID, carol.ID)
+	if details.Poll == nil || details.Poll.TotalVotes != 1 || details.Poll.ShowsResults() {
+		t.Errorf("expected the results hidden from carol, got %+v", details.Poll)
+	}
+
+	database.Exec(`UPDATE polls SET closes_at = ?`, time.Now().Add(-time.Minute).Unix())
+	if _, err := services.Polls.Vote(carol.ID, post.Post.ID, "1"); !errors.Is(err, ErrPollClosed) {
+		t.Errorf("expected ErrPollClosed, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := services.Polls.NotifyClosed(time.Now()); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	notifications, _ := stores.Notifications.GetNotifications(alice.ID, false, store.Page{Limit: 10})
+	if len(notifications) != 1 || notifications[0].Notification.Type != "poll" {
+		t.Errorf("expected alice told once that her poll closed, got %+v", notifications)
+	}
+}
+
+func TestScheduledPosts(t *testing.T) {
+	_, stores, services := setupServices(t)
+	alice := register(t, services, "alice")
+	bob := register(t, services, "bob")
+
+	at := time.Now().Add(time.Hour)
+	draft, err := services.Drafts.Save(alice, NewDraft{Text: "later, @bob", PublishAt: &at})
+	if err != nil {
+		t.Fatalf("failed to schedule: %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, err := services.Drafts.Save(alice, NewDraft{Text: "too late", PublishAt: &past}); !errors.As(err, new(*ValidationError)) {
+		t.Errorf("expected a validation error for a time in the past, got %v", err)
+	}
+
+	// One that can't be published, saved behind the service's back
+	broken := &models.Draft{UserID: alice.ID, Text: "broken", Images: []string{"missing.png"}, PublishAt: new(int64)}
+	if err := stores.Drafts.Create(broken); err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+
+	if results, _ := services.Drafts.PublishDue(time.Now()); len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("expected only the broken draft to be due, and to fail, got %+v", results)
+	}
+
+	// Catching up later publishes the missed post; the failed one waits to be edited
+	results, err := services.Drafts.PublishDue(at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to publish due posts: %v", err)
+	}
+	if len(results) != 1 || results[0].Draft.ID != draft.ID || results[0].Post == nil {
+		t.Fatalf("expected the scheduled post published, got %+v", results)
+	}
+
+	notifications, _ := stores.Notifications.GetNotifications(bob.ID, false, store.Page{Limit: 10})
+	if len(notifications) != 1 || notifications[0].Notification.Type != "mention" {
+		t.Errorf("expected bob notified of the mention, got %+v", notifications)
+	}
+
+	drafts, _ := services.Drafts.Drafts(alice.ID, store.Page{Limit: 10})
+	if len(drafts) != 1 || drafts[0].ID != broken.ID || drafts[0].Error == nil {
+		t.Errorf("expected only the failed draft left, got %+v", drafts)
+	}
+}
+
+// benchmarkGraph creates users who each follow the next follows users and
+// have posted posts times, and returns them
+func benchmarkGraph(b *testing.B, stores *store.Stores, users, follows, posts int) []*models.User {
+	b.Helper()
+
+	var all []*models.User
+	for i := 0; i < users; i++ {
+		user, err := stores.Users.Create(fmt.Sprintf("user%d", i))
+		if err != nil {
+			b.Fatalf("failed to create user: %v", err)
+		}
+		all = append(all, user)
+	}
+
+	for i, user := range all {
+		for j := 1; j <= follows; j++ {
+			stores.Social.Follow(user.ID, all[(i+j)%users].ID)
+		}
+		for j := 0; j < posts; j++ {
+			stores.Posts.Create(user.ID, fmt.Sprintf("post %d", j))
+		}
+	}
+
+	return all
+}
+
+func BenchmarkFeed(b *testing.B) {
+	for _, fanout := range []Fanout{FanoutOnRead, FanoutOnWrite} {
+		b.Run(string(fanout), func(b *testing.B) {
+			_, stores, _ := setupServices(b)
+			services := New(stores, log.New(io.Discard, "", 0), Options{Fanout: fanout})
+			users := benchmarkGraph(b, stores, 200, 50, 20)
+			if err := services.Feed.Rebuild(); err != nil {
+				b.Fatalf("failed to rebuild timelines: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := services.Feed.Feed(users[i%len(users)].ID, store.Page{Limit: 20}); err != nil {
+					b.Fatalf("failed to read feed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPublish(b *testing.B) {
+	for _, fanout := range []Fanout{FanoutOnRead, FanoutOnWrite} {
+		b.Run(string(fanout), func(b *testing.B) {
+			_, stores, _ := setupServices(b)
+			services := New(stores, log.New(io.Discard, "", 0), Options{Fanout: fanout})
+			users := benchmarkGraph(b, stores, 200, 50, 0)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := services.Posts.Publish(users[i%len(users)], NewPost{Text: "benchmark"}); err != nil {
+					b.Fatalf("failed to publish: %v", err)
+				}
+			}
+		})
+	}
+}
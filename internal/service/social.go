@@ -0,0 +1,114 @@
+package service
+
+import (
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// SocialService owns following and liking
+type SocialService struct {
+	social        store.Social
+	users         store.Users
+	posts         store.Posts
+	notifications store.Notifications
+	timelines     store.Timelines
+	fanout        fanout
+	log           Logger
+}
+
+func NewSocialService(social store.Social, users store.Users, posts store.Posts, notifications store.Notifications, timelines store.Timelines, opts Options, logger Logger) *SocialService {
+	return &SocialService{
+		social:        social,
+		users:         users,
+		posts:         posts,
+		notifications: notifications,
+		timelines:     timelines,
+		fanout:        fanout{opts},
+		log:           logger,
+	}
+}
+
+// Follow makes follower follow the user named username, notifies them and,
+// with fan-out-on-write, backfills the follower's timeline with their posts
+func (s *SocialService) Follow(followerID, username string) (*models.User, error) {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.social.Follow(followerID, target.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.notifications.Create(target.ID, followerID, "follow", nil); err != nil {
+		s.log.Printf("failed to create notification: %v", err)
+	}
+
+	if err := s.fanout.follow(s.timelines, s.social, followerID, target.ID); err != nil {
+		s.log.Printf("failed to backfill timeline: %v", err)
+	}
+
+	return target, nil
+}
+
+// Unfollow stops follower following the user named username, retracts the
+// follow notification and takes their posts out of the follower's timeline
+func (s *SocialService) Unfollow(followerID, username string) (*models.User, error) {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.social.Unfollow(followerID, target.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.notifications.Retract(target.ID, followerID, "follow", nil); err != nil {
+		s.log.Printf("failed to retract notification: %v", err)
+	}
+
+	if err := s.fanout.unfollow(s.timelines, s.social, followerID, target.ID); err != nil {
+		s.log.Printf("failed to update timeline: %v", err)
+	}
+
+	return target, nil
+}
+
+// Like likes a post, or the original of a retweet, and notifies its author
+func (s *SocialService) Like(userID, postID string) error {
+	post, err := s.posts.GetOriginal(postID)
+	if err != nil {
+		return err
+	}
+	postID = post.ID
+
+	if err := s.social.Like(userID, postID); err != nil {
+		return err
+	}
+
+	if err := s.notifications.Create(post.AuthorID, userID, "like", &postID); err != nil {
+		s.log.Printf("failed to create notification: %v", err)
+	}
+
+	return nil
+}
+
+// Unlike removes a like of a post, or of the original of a retweet, and
+// retracts the like notification
+func (s *SocialService) Unlike(userID, postID string) error {
+	post, err := s.posts.GetOriginal(postID)
+	if err != nil {
+		return err
+	}
+	postID = post.ID
+
+	if err := s.social.Unlike(userID, postID); err != nil {
+		return err
+	}
+
+	if err := s.notifications.Retract(post.AuthorID, userID, "like", &postID); err != nil {
+		s.log.Printf("failed to retract notification: %v", err)
+	}
+
+	return nil
+}
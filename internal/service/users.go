@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/auth"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
+)
+
+var (
+	// ErrIncorrectPassword is returned when a logged-in user gets their
+	// current password wrong
+	ErrIncorrectPassword = errors.New("incorrect password")
+
+	// ErrHasPassword is returned when claiming an account that already
+	// has a password
+	ErrHasPassword = errors.New("account already has a password")
+
+	// ErrClaimDenied is returned when claiming an account without proof of
+	// owning it, or one that doesn't exist
+	ErrClaimDenied = errors.New("only the user last logged in as this account on this machine can claim it")
+)
+
+// UserService owns accounts, passwords and sessions
+type UserService struct {
+	users    store.Users
+	sessions store.Sessions
+	posts    store.Posts
+	social   store.Social
+	messages store.Messages
+}
+
+func NewUserService(users store.Users, sessions store.Sessions, posts store.Posts, social store.Social, messages store.Messages) *UserService {
+	return &UserService{
+		users:    users,
+		sessions: sessions,
+		posts:    posts,
+		social:   social,
+		messages: messages,
+	}
+}
+
+// Register creates an account with a password
+func (s *UserService) Register(rawUsername, password string) (*models.User, error) {
+	username := validation.SanitizeUsername(rawUsername)
+	if err := validation.ValidateUsername(username); err != nil {
+		return nil, invalid(err)
+	}
+
+	if err := validation.ValidatePassword(password); err != nil {
+		return nil, invalid(err)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.users.CreateWithPassword(username, hash)
+}
+
+// Authenticate checks a username and password. Unknown users, wrong
+// passwords and accounts created before passwords existed all return
+// auth.ErrWrongPassword, so logins can't be used to discover usernames.
+func (s *UserService) Authenticate(rawUsername, password string) (*models.User, error) {
+	user, err := s.users.GetByUsername(validation.SanitizeUsername(rawUsername))
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, auth.ErrWrongPassword
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := s.users.GetPasswordHash(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash == "" {
+		return nil, auth.ErrWrongPassword
+	}
+
+	if err := auth.CheckPassword(hash, password); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// HasPassword reports whether an account has a password. Accounts created
+// before passwords existed don't, and must be claimed with ClaimAccount.
+// Logging in never claims them. It tells unknown usernames apart, so only
+// use it for the logged-in user.
+func (s *UserService) HasPassword(rawUsername string) (bool, error) {
+	user, err := s.users.GetByUsername(validation.SanitizeUsername(rawUsername))
+	if errors.Is(err, store.ErrUserNotFound) {
+		return false, auth.ErrWrongPassword
+	}
+	if err != nil {
+		return false, err
+	}
+
+	hash, err := s.users.GetPasswordHash(user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return hash != "", nil
+}
+
+// ClaimAccount sets the first password of an account that has none. It is
+// a deliberate step for the account's owner, separate from logging in, so
+// the first person to log in as a legacy account can't take it over.
+// legacyUser is the proof of ownership: the username the old,
+// password-less login saved on this machine. Unknown usernames and
+// missing proof both return ErrClaimDenied.
+func (s *UserService) ClaimAccount(rawUsername, legacyUser, password string) (*models.User, error) {
+	username := validation.SanitizeUsername(rawUsername)
+	if legacyUser == "" || validation.SanitizeUsername(legacyUser) != username {
+		return nil, ErrClaimDenied
+	}
+
+	user, err := s.users.GetByUsername(username)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrClaimDenied
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := s.users.GetPasswordHash(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hash != "" {
+		return nil, ErrHasPassword
+	}
+
+	if err := s.SetPassword(user.ID, password); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetPassword replaces a user's password without checking the old one
+func (s *UserService) SetPassword(userID, password string) error {
+	if err := validation.ValidatePassword(password); err != nil {
+		return invalid(err)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.users.SetPasswordHash(userID, hash)
+}
+
+// ChangePassword checks the current password, sets a new one and ends
+// every session except keepToken. It returns how many sessions ended.
+func (s *UserService) ChangePassword(userID, keepToken, current, password string) (int64, error) {
+	hash, err := s.users.GetPasswordHash(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if hash != "" {
+		if err := auth.CheckPassword(hash, current); err != nil {
+			return 0, ErrIncorrectPassword
+		}
+	}
+
+	if err := s.SetPassword(userID, password); err != nil {
+		return 0, err
+	}
+
+	return s.sessions.DeleteOthers(userID, keepToken)
+}
+
+// StartSession logs a user in and returns the session token
+func (s *UserService) StartSession(userID string) (string, *models.Session, error) {
+	return s.sessions.Create(userID, auth.SessionTTL)
+}
+
+// SessionUser returns the user a session token belongs to
+func (s *UserService) SessionUser(token string) (*models.User, error) {
+	return s.sessions.GetUser(token)
+}
+
+// EndSession logs out a single session
+func (s *UserService) EndSession(token string) error {
+	return s.sessions.Delete(token)
+}
+
+// EndAllSessions logs a user out everywhere and returns how many sessions ended
+func (s *UserService) EndAllSessions(userID string) (int64, error) {
+	return s.sessions.DeleteAllForUser(userID)
+}
+
+// Stats summarizes a user's activity
+func (s *UserService) Stats(username string) (*models.UserStats, error) {
+	user, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	postCount, err := s.posts.CountByAuthor(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	following, followers, err := s.social.GetFollowCounts(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sent, received, err := s.messages.GetMessageCounts(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserStats{
+		Username:         user.Username,
+		Posts:            postCount,
+		Following:        following,
+		Followers:        followers,
+		MessagesSent:     sent,
+		MessagesReceived: received,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+type BlockStore struct {
+	db DBTX
+}
+
+func NewBlockStore(db DBTX) *BlockStore {
+	return &BlockStore{db: db}
+}
+
+// Block records that blocker has blocked blocked and removes any follows
+// between them, in both directions. Blocking twice is a no-op.
+func (s *BlockStore) Block(blockerID, blockedID string) error {
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT OR IGNORE INTO blocks (blocker_id, blocked_id, created_at)
+			VALUES (?, ?, ?)
+		`
+
+		_, err := tx.Exec(query, blockerID, blockedID, time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("failed to block user: %w", err)
+		}
+
+		if _, err := removeFollow(tx, blockerID, blockedID); err != nil {
+			return fmt.Errorf("failed to remove follows: %w", err)
+		}
+		if _, err := removeFollow(tx, blockedID, blockerID); err != nil {
+			return fmt.Errorf("failed to remove follows: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Unblock removes a block
+func (s *BlockStore) Unblock(blockerID, blockedID string) error {
+	query := `DELETE FROM blocks WHERE blocker_id = ? AND blocked_id = ?`
+
+	result, err := s.db.Exec(query, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotBlocked
+	}
+
+	return nil
+}
+
+// IsBlocked checks if blocker has blocked blocked
+func (s *BlockStore) IsBlocked(blockerID, blockedID string) (bool, error) {
+	query := `SELECT COUNT(*) FROM blocks WHERE blocker_id = ? AND blocked_id = ?`
+
+	var count int
+	err := s.db.QueryRow(query, blockerID, blockedID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check block status: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// EitherBlocked checks if either user has blocked the other. A block in
+// either direction stops the two users interacting.
+func (s *BlockStore) EitherBlocked(userA, userB string) (bool, error) {
+	return eitherBlocked(s.db, userA, userB)
+}
+
+func eitherBlocked(q DBTX, userA, userB string) (bool, error) {
+	query := `SELECT ` + blockedBetween("?")
+
+	var blocked bool
+	err := q.QueryRow(query, userA, userB, userB, userA).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check block status: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// blockedBetween is an SQL condition that holds when the user in column and
+// the user bound to both ? have blocked each other, in either direction.
+// Queries that show posts to a user add NOT blockedBetween(author column)
+// to leave out blocked authors.
+func blockedBetween(column string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM blocks b
+		WHERE (b.blocker_id = ? AND b.blocked_id = %[1]s)
+		   OR (b.blocker_id = %[1]s AND b.blocked_id = ?)
+	)`, column)
+}
+
+// GetBlocked returns a page of the users blockerID has blocked, most
+// recently blocked first
+func (s *BlockStore) GetBlocked(blockerID string, page Page) ([]Connection, error) {
+	query := `
+		SELECT u.id, u.username, u.created_at, b.created_at
+		FROM blocks b
+		JOIN users u ON b.blocked_id = u.id
+		WHERE b.blocker_id = ?
+	`
+
+	users, err := queryConnections(s.db, query, "b.created_at", blockerID, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked users: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+)
+
+func TestBlocks_Enforced(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	carol, _ := stores.Users.Create("carol")
+
+	stores.Social.Follow(alice.ID, bob.ID)
+	stores.Social.Follow(bob.ID, alice.ID)
+	stores.Social.Follow(alice.ID, carol.ID)
+
+	bobPost, _ := stores.Posts.Create(bob.ID, "hello @alice")
+	stores.Mentions.CreateMentions(bobPost.ID, []string{alice.ID})
+	carolPost, _ := stores.Posts.Create(carol.ID, "carol here")
+	stores.Posts.Retweet(carol.ID, bobPost.ID)
+	reply, _ := stores.Posts.CreateReply(bob.ID, "replying", carolPost.ID)
+
+	if err := stores.Blocks.Block(alice.ID, bob.ID); err != nil {
+		t.Fatalf("failed to block: %v", err)
+	}
+
+	// Follows are removed both ways
+	for _, pair := range [][2]string{{alice.ID, bob.ID}, {bob.ID, alice.ID}} {
+		following, _ := stores.Social.IsFollowing(pair[0], pair[1])
+		if following {
+			t.Errorf("expected follow %s -> %s to be removed", pair[0], pair[1])
+		}
+	}
+
+	// Neither side can follow, like or message the other
+	if err := stores.Social.Follow(bob.ID, alice.ID); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked following the blocker, got %v", err)
+	}
+	if err := stores.Social.Follow(alice.ID, bob.ID); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked following the blocked user, got %v", err)
+	}
+	if err := stores.Social.Like(alice.ID, bobPost.ID); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked liking, got %v", err)
+	}
+	if _, err := stores.Messages.Send(bob.ID, alice.ID, "hi"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked messaging, got %v", err)
+	}
+	if count, _ := stores.Messages.GetUnreadCount(alice.ID); count != 0 {
+		t.Errorf("expected the blocked message not to be stored, got %d", count)
+	}
+
+	// Notifications from the blocked user are dropped
+	stores.Notifications.Create(alice.ID, bob.ID, "like", &bobPost.ID)
+	if count, _ := stores.Notifications.GetUnreadCount(alice.ID); count != 0 {
+		t.Errorf("expected no notifications, got %d", count)
+	}
+
+	// Alice's feed keeps carol's post but not the retweet of bob's
+	feed, err := stores.Posts.GetFeed(alice.ID, Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get feed: %v", err)
+	}
+	if len(feed) != 1 || feed[0].Post.ID != carolPost.ID {
+		t.Errorf("expected only carol's post in the feed, got %+v", feed)
+	}
+
+	mentions, err := stores.Mentions.GetMentions(alice.ID, Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get mentions: %v", err)
+	}
+	if len(mentions) != 0 {
+		t.Errorf("expected no mentions from bob, got %+v", mentions)
+	}
+
+	// Bob's reply is hidden from alice but not from anyone else
+	if thread, _ := stores.Posts.GetThread(carolPost.ID, alice.ID, ThreadOptions{}); len(thread.Post.Replies) != 0 {
+		t.Errorf("expected bob's reply hidden from alice, got %+v", thread.Post.Replies)
+	}
+	if thread, _ := stores.Posts.GetThread(carolPost.ID, "", ThreadOptions{}); len(thread.Post.Replies) != 1 || thread.Post.Replies[0].Post.ID != reply.ID {
+		t.Errorf("expected the full thread without a viewer, got %+v", thread.Post.Replies)
+	}
+}
@@ -0,0 +1,16 @@
+package store
+
+import "fmt"
+
+// bump adds delta to a counter column of one row of posts or users. Call it
+// in the same transaction as the write that changes what it counts, so the
+// counter can't drift from it.
+func bump(q DBTX, table, column, id string, delta int) error {
+	query := fmt.Sprintf(`UPDATE %s SET %[2]s = %[2]s + ? WHERE id = ?`, table, column)
+
+	if _, err := q.Exec(query, delta, id); err != nil {
+		return fmt.Errorf("failed to update %s: %w", column, err)
+	}
+
+	return nil
+}
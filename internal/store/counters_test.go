@@ -0,0 +1,132 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+func TestCounters(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	var users []*models.User
+	for _, name := range []string{"alice", "bob", "carol"} {
+		user, err := stores.Users.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		users = append(users, user)
+	}
+	alice, bob, carol := users[0], users[1], users[2]
+
+	post, err := stores.Posts.Create(alice.ID, "hello")
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	reply, err := stores.Posts.CreateReply(bob.ID, "hi", post.ID)
+	if err != nil {
+		t.Fatalf("failed to create reply: %v", err)
+	}
+	if _, err := stores.Posts.Retweet(carol.ID, post.ID); err != nil {
+		t.Fatalf("failed to retweet: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"like", func() error { return stores.Social.Like(bob.ID, post.ID) }},
+		{"like", func() error { return stores.Social.Like(carol.ID, post.ID) }},
+		{"unlike", func() error { return stores.Social.Unlike(carol.ID, post.ID) }},
+		{"follow", func() error { return stores.Social.Follow(bob.ID, alice.ID) }},
+		{"follow", func() error { return stores.Social.Follow(carol.ID, alice.ID) }},
+		{"follow", func() error { return stores.Social.Follow(alice.ID, bob.ID) }},
+		{"add image", func() error {
+			return stores.Media.Create(&models.Media{ID: "m1", PostID: post.ID, FilePath: "a.png", FileName: "a.png", FileType: "image/png"})
+		}},
+		{"add image", func() error {
+			return stores.Media.Create(&models.Media{ID: "m2", PostID: post.ID, FilePath: "b.png", FileName: "b.png", FileType: "image/png", Position: 1})
+		}},
+		{"delete image", func() error { return stores.Media.Delete("m1") }},
+		// Blocking drops the follows both ways
+		{"block", func() error { return stores.Blocks.Block(alice.ID, bob.ID) }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("failed to %s: %v", step.name, err)
+		}
+	}
+
+	got, err := stores.Posts.GetByID(post.ID)
+	if err != nil {
+		t.Fatalf("failed to get post: %v", err)
+	}
+	if got.LikeCount != 1 || got.RetweetCount != 1 || got.ReplyCount != 1 || got.MediaCount != 1 {
+		t.Errorf("expected 1 like, retweet, reply and image, got %+v", got)
+	}
+
+	following, followers, _ := stores.Social.GetFollowCounts(alice.ID)
+	if following != 0 || followers != 1 {
+		t.Errorf("expected alice to follow 0 and have 1 follower, got %d and %d", following, followers)
+	}
+
+	// Deleting a reply takes it off its parent and its author
+	if err := stores.Posts.Delete(reply.ID, bob.ID); err != nil {
+		t.Fatalf("failed to delete reply: %v", err)
+	}
+	if got, _ := stores.Posts.GetByID(post.ID); got.ReplyCount != 0 {
+		t.Errorf("expected no replies after delete, got %d", got.ReplyCount)
+	}
+	if count, _ := stores.Posts.CountByAuthor(bob.ID); count != 0 {
+		t.Errorf("expected bob to have no posts, got %d", count)
+	}
+
+	// Everything the stores wrote agrees with a recount
+	drift, err := db.ReconcileCounters(database, false)
+	if err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if len(drift) != 0 {
+		t.Fatalf("expected no drift, got %+v", drift)
+	}
+
+	// Drift written behind the stores' back is found, then fixed
+	if _, err := database.Exec(`UPDATE posts SET like_count = 7 WHERE id = ?`, post.ID); err != nil {
+		t.Fatalf("failed to write drift: %v", err)
+	}
+	if _, err := database.Exec(`UPDATE users SET post_count = 0 WHERE id = ?`, carol.ID); err != nil {
+		t.Fatalf("failed to write drift: %v", err)
+	}
+
+	if drift, err = db.ReconcileCounters(database, false); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if len(drift) != 2 {
+		t.Fatalf("expected 2 drifted counters, got %+v", drift)
+	}
+	if d := drift[0]; d.Column != "like_count" || d.Stored != 7 || d.Actual != 1 {
+		t.Errorf("expected like_count 7 to be 1, got %+v", d)
+	}
+
+	// A dry run leaves them alone
+	if count, _ := stores.Social.GetLikeCount(post.ID); count != 7 {
+		t.Errorf("expected dry run to leave the drift, got %d", count)
+	}
+
+	if _, err := db.ReconcileCounters(database, true); err != nil {
+		t.Fatalf("failed to fix counters: %v", err)
+	}
+	if drift, _ := db.ReconcileCounters(database, false); len(drift) != 0 {
+		t.Errorf("expected no drift after fixing, got %+v", drift)
+	}
+	if count, _ := stores.Posts.CountByAuthor(carol.ID); count != 1 {
+		t.Errorf("expected carol's post count fixed to 1, got %d", count)
+	}
+}
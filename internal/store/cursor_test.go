@@ -0,0 +1,121 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+func TestPages(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	stores.Social.Follow(alice.ID, bob.ID)
+
+	// Five posts in the same second, so only the IDs tell them apart
+	var ids []string
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		post, _ := stores.Posts.Create(bob.ID, text)
+		ids = append(ids, post.ID)
+	}
+
+	page := func(p Page) ([]string, *models.Cursor) {
+		t.Helper()
+		posts, err := stores.Posts.GetFeed(alice.ID, p.Probe())
+		if err != nil {
+			t.Fatalf("failed to get feed: %v", err)
+		}
+		posts, next := Paginate(posts, p)
+		var got []string
+		for _, pwa := range posts {
+			got = append(got, pwa.Post.Text)
+		}
+		return got, next
+	}
+
+	expect := func(got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+	}
+
+	got, next := page(Page{Limit: 2})
+	expect(got, "five", "four")
+
+	// A post arriving between pages doesn't shift the next one
+	stores.Posts.Create(bob.ID, "six")
+
+	got, next = page(Page{Before: next, Limit: 2})
+	expect(got, "three", "two")
+	got, next = page(Page{Before: next, Limit: 2})
+	expect(got, "one")
+	if next != nil {
+		t.Errorf("expected no cursor after the last page, got %v", next)
+	}
+
+	// After pages take the posts just newer than the cursor, newest first
+	first := models.Cursor{CreatedAt: 0, ID: ""}
+	got, next = page(Page{After: &first, Limit: 2})
+	expect(got, "two", "one")
+	got, _ = page(Page{After: next, Limit: 10})
+	expect(got, "six", "five", "four", "three")
+
+	// Cursors survive a round trip through their token
+	parsed, err := models.ParseCursor(next.String())
+	if err != nil || *parsed != *next {
+		t.Errorf("expected %v back from its token, got %v (%v)", next, parsed, err)
+	}
+	if _, err := models.ParseCursor("not a cursor"); err != models.ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+
+	// Other lists page the same way
+	for _, text := range []string{"a", "b", "c"} {
+		stores.Messages.Send(bob.ID, alice.ID, text)
+	}
+	messages, _ := stores.Messages.GetInbox(alice.ID, Page{Limit: 2}.Probe())
+	messages, cursor := Paginate(messages, Page{Limit: 2})
+	if len(messages) != 2 || messages[0].Message.Text != "c" || cursor == nil {
+		t.Fatalf("expected the two newest messages, got %+v", messages)
+	}
+	older, _ := stores.Messages.GetInbox(alice.ID, Page{Before: cursor, Limit: 2})
+	if len(older) != 1 || older[0].Message.Text != "a" {
+		t.Errorf("expected the oldest message, got %+v", older)
+	}
+
+	carol, _ := stores.Users.Create("carol")
+	stores.Social.Follow(carol.ID, bob.ID)
+	followers, _ := stores.Social.GetFollowers(bob.ID, Page{Limit: 1})
+	if len(followers) != 1 || followers[0].Username != "carol" {
+		t.Fatalf("expected the newest follower, got %+v", followers)
+	}
+	c := followers[0].Cursor()
+	if rest, _ := stores.Social.GetFollowers(bob.ID, Page{Before: &c}); len(rest) != 1 || rest[0].Username != "alice" {
+		t.Errorf("expected alice after carol, got %+v", rest)
+	}
+
+	// Conversations are placed by their latest message
+	stores.Messages.Send(carol.ID, alice.ID, "hi")
+	conversations, _ := stores.Messages.GetConversations(alice.ID, Page{Limit: 1})
+	if len(conversations) != 1 || conversations[0].OtherUsername != "carol" {
+		t.Fatalf("expected the conversation with carol first, got %+v", conversations)
+	}
+	c = conversations[0].Cursor()
+	if rest, _ := stores.Messages.GetConversations(alice.ID, Page{Before: &c, Limit: 10}); len(rest) != 1 || rest[0].OtherUsername != "bob" || rest[0].LastMessage != "c" {
+		t.Errorf("expected the conversation with bob after carol, got %+v", rest)
+	}
+}
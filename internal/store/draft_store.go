@@ -0,0 +1,233 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// DraftStore keeps drafts and scheduled posts, with the images attached to
+// them
+type DraftStore struct {
+	db DBTX
+}
+
+func NewDraftStore(db DBTX) *DraftStore {
+	return &DraftStore{db: db}
+}
+
+// Create saves a new draft, filling in its ID and timestamps
+func (s *DraftStore) Create(draft *models.Draft) error {
+	draft.ID = ulid.Make().String()
+	draft.CreatedAt = time.Now().Unix()
+	draft.UpdatedAt = draft.CreatedAt
+	draft.Error = nil
+
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO drafts (id, user_id, text, publish_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := tx.Exec(query, draft.ID, draft.UserID, draft.Text, draft.PublishAt, draft.CreatedAt, draft.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create draft: %w", err)
+		}
+
+		return setDraftImages(tx, draft.ID, draft.Images)
+	})
+}
+
+// Update saves a draft's text, images and publish time, and clears any
+// error from publishing it, so the scheduler tries it again
+func (s *DraftStore) Update(draft *models.Draft) error {
+	draft.UpdatedAt = time.Now().Unix()
+	draft.Error = nil
+
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			UPDATE drafts
+			SET text = ?, publish_at = ?, error = NULL, updated_at = ?
+			WHERE id = ? AND user_id = ?
+		`
+
+		result, err := tx.Exec(query, draft.Text, draft.PublishAt, draft.UpdatedAt, draft.ID, draft.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update draft: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrDraftNotFound
+		}
+
+		return setDraftImages(tx, draft.ID, draft.Images)
+	})
+}
+
+// setDraftImages replaces the images attached to a draft
+func setDraftImages(tx DBTX, draftID string, images []string) error {
+	if _, err := tx.Exec(`DELETE FROM draft_media WHERE draft_id = ?`, draftID); err != nil {
+		return fmt.Errorf("failed to clear draft images: %w", err)
+	}
+
+	for i, path := range images {
+		query := `INSERT INTO draft_media (draft_id, position, file_path) VALUES (?, ?, ?)`
+		if _, err := tx.Exec(query, draftID, i, path); err != nil {
+			return fmt.Errorf("failed to attach draft image: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetByID returns one of a user's drafts
+func (s *DraftStore) GetByID(draftID, userID string) (*models.Draft, error) {
+	drafts, err := s.queryDrafts(`WHERE id = ? AND user_id = ?`, draftID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(drafts) == 0 {
+		return nil, ErrDraftNotFound
+	}
+
+	return &drafts[0], nil
+}
+
+// GetByUser returns a page of a user's drafts, newest first
+func (s *DraftStore) GetByUser(userID string, page Page) ([]models.Draft, error) {
+	keyset, keysetArgs := page.keyset("created_at", "id")
+	orderBy, limit := page.orderBy("created_at", "id")
+
+	args := append([]interface{}{userID}, keysetArgs...)
+	drafts, err := s.queryDrafts(`WHERE user_id = ? AND `+keyset+orderBy, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return order(page, drafts), nil
+}
+
+// GetDue returns up to limit scheduled drafts whose time has come by now,
+// longest overdue first. Drafts that failed to publish are left out until
+// they're edited.
+func (s *DraftStore) GetDue(now int64, limit int) ([]models.Draft, error) {
+	return s.queryDrafts(`
+		WHERE publish_at <= ? AND error IS NULL
+		ORDER BY publish_at, id
+		LIMIT ?
+	`, now, limit)
+}
+
+// SetError records why a draft couldn't be published
+func (s *DraftStore) SetError(draftID, message string) error {
+	query := `UPDATE drafts SET error = ? WHERE id = ?`
+
+	if _, err := s.db.Exec(query, message, draftID); err != nil {
+		return fmt.Errorf("failed to record draft error: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes one of a user's drafts. The caller removes its image files.
+func (s *DraftStore) Delete(draftID, userID string) error {
+	query := `DELETE FROM drafts WHERE id = ? AND user_id = ?`
+
+	result, err := s.db.Exec(query, draftID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrDraftNotFound
+	}
+
+	return nil
+}
+
+// queryDrafts returns the drafts matching the clauses that follow FROM,
+// with their images
+func (s *DraftStore) queryDrafts(clauses string, args ...interface{}) ([]models.Draft, error) {
+	query := `
+		SELECT id, user_id, text, publish_at, error, created_at, updated_at
+		FROM drafts
+	` + clauses
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var drafts []models.Draft
+	for rows.Next() {
+		var d models.Draft
+		err := rows.Scan(&d.ID, &d.UserID, &d.Text, &d.PublishAt, &d.Error, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		drafts = append(drafts, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drafts: %w", err)
+	}
+	rows.Close() // Before querying again, in case s.db is a transaction
+
+	if err := s.attachImages(drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// attachImages fills in the images of drafts
+func (s *DraftStore) attachImages(drafts []models.Draft) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+
+	index := make(map[string]*models.Draft, len(drafts))
+	ids := make([]string, len(drafts))
+	for i := range drafts {
+		index[drafts[i].ID] = &drafts[i]
+		ids[i] = drafts[i].ID
+	}
+
+	in, args := inList(ids)
+	query := `
+		SELECT draft_id, file_path
+		FROM draft_media
+		WHERE draft_id IN (` + in + `)
+		ORDER BY draft_id, position
+	`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query draft images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var draftID, path string
+		if err := rows.Scan(&draftID, &path); err != nil {
+			return fmt.Errorf("failed to scan draft image: %w", err)
+		}
+		d := index[draftID]
+		d.Images = append(d.Images, path)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating draft images: %w", err)
+	}
+	return nil
+}
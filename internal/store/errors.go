@@ -0,0 +1,31 @@
+package store
+
+import "errors"
+
+// Sentinel errors returned by the stores. Callers can match them with
+// errors.Is; the messages are what the CLI shows to the user.
+var (
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUsernameTaken        = errors.New("username already exists")
+	ErrPostNotFound         = errors.New("post not found")
+	ErrNotPostOwner         = errors.New("post not found or you don't own this post")
+	ErrMessageNotFound      = errors.New("message not found or you don't own it")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrDraftNotFound        = errors.New("draft not found")
+	ErrPollNotFound         = errors.New("poll not found")
+	ErrAlreadyVoted         = errors.New("already voted in this poll")
+	ErrBlocked              = errors.New("you cannot interact with this user")
+	ErrNotBlocked           = errors.New("user was not blocked")
+	ErrNotMuted             = errors.New("not muted")
+	ErrFollowSelf           = errors.New("cannot follow yourself")
+	ErrAlreadyFollowing     = errors.New("already following this user")
+	ErrNotFollowing         = errors.New("not following this user")
+	ErrAlreadyLiked         = errors.New("already liked this post")
+	ErrNotLiked             = errors.New("post not liked")
+	ErrAlreadyRetweeted     = errors.New("already retweeted this post")
+	ErrNotRetweeted         = errors.New("post not retweeted")
+	ErrRetweetOwnPost       = errors.New("cannot retweet your own post")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrSessionExpired       = errors.New("session expired")
+	ErrEmptyQuery           = errors.New("search query has no words to search for")
+)
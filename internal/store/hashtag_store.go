@@ -7,19 +7,23 @@ import (
 )
 
 type HashtagStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewHashtagStore(db *sql.DB) *HashtagStore {
+func NewHashtagStore(db DBTX) *HashtagStore {
 	return &HashtagStore{db: db}
 }
 
 // GetOrCreateHashtag gets existing hashtag or creates new one
 func (s *HashtagStore) GetOrCreateHashtag(tag string) (int64, error) {
+	return getOrCreateHashtag(s.db, tag)
+}
+
+func getOrCreateHashtag(q DBTX, tag string) (int64, error) {
 	// Try to get existing
 	var id int64
 	query := `SELECT id FROM hashtags WHERE tag = ?`
-	err := s.db.QueryRow(query, tag).Scan(&id)
+	err := q.QueryRow(query, tag).Scan(&id)
 
 	if err == nil {
 		return id, nil
@@ -31,7 +35,7 @@ func (s *HashtagStore) GetOrCreateHashtag(tag string) (int64, error) {
 
 	// Create new
 	insertQuery := `INSERT INTO hashtags (tag, created_at) VALUES (?, ?)`
-	result, err := s.db.Exec(insertQuery, tag, time.Now().Unix())
+	result, err := q.Exec(insertQuery, tag, time.Now().Unix())
 	if err != nil {
 		return 0, fmt.Errorf("failed to create hashtag: %w", err)
 	}
@@ -45,46 +49,56 @@ func (s *HashtagStore) LinkPostToHashtags(postID string, hashtags []string) erro
 		return nil
 	}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	for _, tag := range hashtags {
-		// Get or create hashtag
-		hashtagID, err := s.GetOrCreateHashtag(tag)
-		if err != nil {
-			return err
+	return withTx(s.db, func(tx DBTX) error {
+		for _, tag := range hashtags {
+			// Get or create hashtag
+			hashtagID, err := getOrCreateHashtag(tx, tag)
+			if err != nil {
+				return err
+			}
+
+			// Link to post
+			query := `INSERT OR IGNORE INTO post_hashtags (post_id, hashtag_id) VALUES (?, ?)`
+			if _, err := tx.Exec(query, postID, hashtagID); err != nil {
+				return fmt.Errorf("failed to link hashtag: %w", err)
+			}
 		}
 
-		// Link to post
-		query := `INSERT OR IGNORE INTO post_hashtags (post_id, hashtag_id) VALUES (?, ?)`
-		_, err = tx.Exec(query, postID, hashtagID)
-		if err != nil {
-			return fmt.Errorf("failed to link hashtag: %w", err)
-		}
-	}
+		return nil
+	})
+}
 
-	return tx.Commit()
+// UnlinkPost removes a post's links to its hashtags, as before relinking
+// an edited post
+func (s *HashtagStore) UnlinkPost(postID string) error {
+	if _, err := s.db.Exec(`DELETE FROM post_hashtags WHERE post_id = ?`, postID); err != nil {
+		return fmt.Errorf("failed to unlink hashtags: %w", err)
+	}
+	return nil
 }
 
-// GetPostsByHashtag retrieves posts with a specific hashtag
-func (s *HashtagStore) GetPostsByHashtag(tag string, limit int) ([]PostWithAuthor, error) {
+// GetPostsByHashtag retrieves a page of posts with a specific hashtag,
+// leaving out posts viewerID has muted. Pass an empty viewerID to see every
+// post.
+func (s *HashtagStore) GetPostsByHashtag(tag, viewerID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
 	query := `
 		SELECT 
-			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id,
+			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
 			u.username
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
 		JOIN post_hashtags ph ON p.id = ph.post_id
 		JOIN hashtags h ON ph.hashtag_id = h.id
 		WHERE h.tag = ?
-		ORDER BY p.created_at DESC
-		LIMIT ?
-	`
+		AND NOT ` + mutedPost("p") + `
+		AND ` + keyset + orderBy
+	args := append([]interface{}{tag, viewerID, time.Now().Unix()}, keysetArgs...)
 
-	rows, err := s.db.Query(query, tag, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
@@ -100,6 +114,14 @@ func (s *HashtagStore) GetPostsByHashtag(tag string, limit int) ([]PostWithAutho
 			&pwa.Post.CreatedAt,
 			&pwa.Post.IsRetweet,
 			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
 			&pwa.Username,
 		)
 		if err != nil {
@@ -108,7 +130,14 @@ func (s *HashtagStore) GetPostsByHashtag(tag string, limit int) ([]PostWithAutho
 		posts = append(posts, pwa)
 	}
 
-	return posts, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
 }
 
 // GetTrendingHashtags gets most used hashtags
@@ -146,6 +175,6 @@ func (s *HashtagStore) GetTrendingHashtags(limit int, since int64) ([]TrendingHa
 }
 
 type TrendingHashtag struct {
-	Tag   string
-	Count int
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
 }
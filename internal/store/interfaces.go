@@ -0,0 +1,243 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+// The interfaces below describe each store so services can be built and
+// tested against something other than SQLite. The SQLite implementations
+// live next to them in this package.
+
+// Users stores accounts and their password hashes
+type Users interface {
+	Create(username string) (*models.User, error)
+	CreateWithPassword(username, passwordHash string) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	GetByID(id string) (*models.User, error)
+	GetPasswordHash(userID string) (string, error)
+	SetPasswordHash(userID, passwordHash string) error
+}
+
+// Sessions stores login sessions
+type Sessions interface {
+	Create(userID string, ttl time.Duration) (string, *models.Session, error)
+	GetUser(token string) (*models.User, error)
+	Delete(token string) error
+	DeleteAllForUser(userID string) (int64, error)
+	DeleteOthers(userID, keepToken string) (int64, error)
+}
+
+// Posts stores posts, replies, retweets and quotes
+type Posts interface {
+	Create(authorID, text string) (*models.Post, error)
+	CreateReply(authorID, text, parentPostID string) (*models.Post, error)
+	CreateQuote(authorID, text, quotedPostID string) (*models.Post, error)
+	GetByID(postID string) (*models.Post, error)
+	GetOriginal(postID string) (*models.Post, error)
+	GetByAuthorID(authorID string, page Page) ([]PostWithAuthor, error)
+	GetByUsername(username string, page Page) ([]PostWithAuthor, error)
+	Delete(postID, authorID string) error
+	Edit(postID, authorID, text string) (*models.Post, error)
+	GetVersions(postID string) ([]models.PostVersion, error)
+	GetFeed(userID string, page Page) ([]PostWithAuthor, error)
+	Retweet(userID, postID string) (*models.Post, error)
+	Unretweet(userID, postID string) error
+	HasRetweeted(userID, originalPostID string) (bool, error)
+	GetRetweetCount(postID string) (int, error)
+	CountByAuthor(authorID string) (int, error)
+	Search(query, viewerID string, limit, offset int) ([]PostSearchResult, error)
+	GetThread(postID, viewerID string, opts ThreadOptions) (*Thread, error)
+	GetQuotes(postID, viewerID string, page Page) ([]PostWithAuthor, error)
+	Resolve(posts []PostWithAuthor) error
+}
+
+// Timelines stores materialized home timelines for fan-out-on-write
+type Timelines interface {
+	Add(userID string, post *models.Post) error
+	FanOut(post *models.Post) error
+	Backfill(userID, authorID string, limit int) error
+	BackfillFollowers(authorID string, limit int) error
+	Remove(userID, authorID string) error
+	Rebuild(limit, pullOver int) error
+	GetTimeline(userID string, page Page) ([]PostWithAuthor, error)
+	GetPulled(userID string, pullOver int, page Page) ([]PostWithAuthor, error)
+}
+
+// Ranking reads the candidates and signals the ranked feed is built from,
+// and remembers which posts it has shown
+type Ranking interface {
+	FollowedPosts(userID string, since int64, limit int) ([]PostWithAuthor, error)
+	EngagedPosts(userID string, since int64, limit int) ([]PostWithAuthor, error)
+	TaggedPosts(userID string, tags []string, since int64, limit int) ([]PostWithAuthor, error)
+	NetworkPosts(userID string, since int64, limit int) ([]PostWithAuthor, error)
+	Affinity(userID string) (map[string]int, error)
+	BlockedPosts(userID string, postIDs []string) (map[string]bool, error)
+	MutedPosts(userID string, postIDs []string) (map[string]bool, error)
+	SeenPosts(userID string, postIDs []string) (map[string]bool, error)
+	MarkSeen(userID string, postIDs []string, forgetBefore int64) error
+	ForgetSeen(userID string) error
+}
+
+// Social stores follows and likes
+type Social interface {
+	Follow(followerID, followeeID string) error
+	Unfollow(followerID, followeeID string) error
+	IsFollowing(followerID, followeeID string) (bool, error)
+	GetFollowing(userID string, page Page) ([]Connection, error)
+	GetFollowers(userID string, page Page) ([]Connection, error)
+	GetFollowingByUsername(username string, page Page) ([]Connection, error)
+	GetFollowersByUsername(username string, page Page) ([]Connection, error)
+	GetFollowCounts(userID string) (following int, followers int, err error)
+	Like(userID, postID string) error
+	Unlike(userID, postID string) error
+	HasLiked(userID, postID string) (bool, error)
+	GetLikes(postID string, page Page) ([]Connection, error)
+	GetLikeCount(postID string) (int, error)
+}
+
+// Messages stores direct messages
+type Messages interface {
+	Send(senderID, receiverID, text string) (*models.Message, error)
+	GetInbox(userID string, page Page) ([]models.MessageWithUser, error)
+	GetConversation(user1ID, user2ID string, page Page) ([]models.MessageWithUser, error)
+	GetConversations(userID string, page Page) ([]models.Conversation, error)
+	MarkAsRead(receiverID, senderID string) error
+	GetUnreadCount(userID string) (int, error)
+	GetMessageCounts(userID string) (sent int, received int, err error)
+	DeleteMessage(messageID, senderID string) error
+	SearchMessages(userID, query string, limit, offset int) ([]MessageSearchResult, error)
+}
+
+// Blocks stores which users have blocked which
+type Blocks interface {
+	Block(blockerID, blockedID string) error
+	Unblock(blockerID, blockedID string) error
+	IsBlocked(blockerID, blockedID string) (bool, error)
+	EitherBlocked(userA, userB string) (bool, error)
+	GetBlocked(blockerID string, page Page) ([]Connection, error)
+}
+
+// Mutes stores the users, words and hashtags each user has muted
+type Mutes interface {
+	Mute(userID, kind, value string, expiresAt *int64) error
+	Unmute(userID, kind, value string) error
+	GetMutes(userID string, page Page) ([]models.Mute, error)
+}
+
+// Notifications stores notifications
+type Notifications interface {
+	Create(userID, actorID, notifType string, targetID *string) error
+	Retract(userID, actorID, notifType string, targetID *string) error
+	RetractTarget(targetID string) error
+	GetNotifications(userID string, unreadOnly bool, page Page) ([]models.NotificationWithDetails, error)
+	GetGroupedNotifications(userID string, unreadOnly bool, page Page) ([]models.NotificationGroup, error)
+	MarkAsRead(userID string) error
+	GetUnreadCount(userID string) (int, error)
+	DeleteNotification(notificationID, userID string) error
+	DeleteAllRead(userID string) error
+	GetSettings(userID string) (*models.NotificationSettings, error)
+	SetRule(userID string, rule models.NotificationRule) error
+	SetQuietHours(userID string, quiet *models.QuietHours) error
+}
+
+// Hashtags stores hashtags and their links to posts
+type Hashtags interface {
+	LinkPostToHashtags(postID string, hashtags []string) error
+	UnlinkPost(postID string) error
+	GetPostsByHashtag(tag, viewerID string, page Page) ([]PostWithAuthor, error)
+	GetTrendingHashtags(limit int, since int64) ([]TrendingHashtag, error)
+}
+
+// Mentions stores @mentions in posts
+type Mentions interface {
+	CreateMentions(postID string, userIDs []string) error
+	GetPostMentions(postID string) ([]string, error)
+	DeleteMentions(postID string) error
+	GetMentions(userID string, page Page) ([]PostWithAuthor, error)
+	GetMentionedUsers(usernames []string) ([]string, error)
+}
+
+// MediaFiles stores metadata for images attached to posts
+type MediaFiles interface {
+	Create(media *models.Media) error
+	GetByPostID(postID string) ([]models.Media, error)
+	Delete(mediaID string) error
+	DeleteByPostID(postID string) error
+	GetMediaCount(postID string) (int, error)
+}
+
+// Drafts stores drafts and scheduled posts
+type Drafts interface {
+	Create(draft *models.Draft) error
+	Update(draft *models.Draft) error
+	GetByID(draftID, userID string) (*models.Draft, error)
+	GetByUser(userID string, page Page) ([]models.Draft, error)
+	GetDue(now int64, limit int) ([]models.Draft, error)
+	SetError(draftID, message string) error
+	Delete(draftID, userID string) error
+}
+
+// Polls stores polls attached to posts and the votes cast in them
+type Polls interface {
+	Create(postID string, options []string, closesAt int64) error
+	Get(postID, viewerID string) (*models.Poll, error)
+	Vote(postID, userID string, position int) error
+	GetClosed(now int64, limit int) ([]models.Post, error)
+	MarkNotified(postID string) error
+}
+
+// Transactor runs a unit of work across several stores atomically.
+// *Stores implements it with a database transaction.
+type Transactor interface {
+	InTx(fn func(tx *Stores) error) error
+}
+
+// Stores groups one implementation of every store
+type Stores struct {
+	Users         Users
+	Sessions      Sessions
+	Posts         Posts
+	Timelines     Timelines
+	Ranking       Ranking
+	Social        Social
+	Messages      Messages
+	Blocks        Blocks
+	Mutes         Mutes
+	Notifications Notifications
+	Hashtags      Hashtags
+	Mentions      Mentions
+	Media         MediaFiles
+	Drafts        Drafts
+	Polls         Polls
+
+	db DBTX // What InTx begins transactions on
+}
+
+// NewStores creates the SQLite-backed stores for db
+func NewStores(db *sql.DB) *Stores {
+	return newStores(db)
+}
+
+func newStores(db DBTX) *Stores {
+	return &Stores{
+		Users:         NewUserStore(db),
+		Sessions:      NewSessionStore(db),
+		Posts:         NewPostStore(db),
+		Timelines:     NewTimelineStore(db),
+		Ranking:       NewRankingStore(db),
+		Social:        NewSocialStore(db),
+		Messages:      NewMessageStore(db),
+		Blocks:        NewBlockStore(db),
+		Mutes:         NewMuteStore(db),
+		Notifications: NewNotificationStore(db),
+		Hashtags:      NewHashtagStore(db),
+		Mentions:      NewMentionStore(db),
+		Media:         NewMediaStore(db),
+		Drafts:        NewDraftStore(db),
+		Polls:         NewPollStore(db),
+		db:            db,
+	}
+}
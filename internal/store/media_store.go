@@ -10,10 +10,10 @@ import (
 )
 
 type MediaStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewMediaStore(db *sql.DB) *MediaStore {
+func NewMediaStore(db DBTX) *MediaStore {
 	return &MediaStore{db: db}
 }
 
@@ -26,32 +26,34 @@ func (s *MediaStore) Create(media *models.Media) error {
 		media.CreatedAt = time.Now().Unix()
 	}
 
-	query := `
-		INSERT INTO media (
-			id, post_id, file_path, file_name, file_type, file_size,
-			width, height, position, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	_, err := s.db.Exec(
-		query,
-		media.ID,
-		media.PostID,
-		media.FilePath,
-		media.FileName,
-		media.FileType,
-		media.FileSize,
-		media.Width,
-		media.Height,
-		media.Position,
-		media.CreatedAt,
-	)
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO media (
+				id, post_id, file_path, file_name, file_type, file_size,
+				width, height, position, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+
+		_, err := tx.Exec(
+			query,
+			media.ID,
+			media.PostID,
+			media.FilePath,
+			media.FileName,
+			media.FileType,
+			media.FileSize,
+			media.Width,
+			media.Height,
+			media.Position,
+			media.CreatedAt,
+		)
 
-	if err != nil {
-		return fmt.Errorf("failed to create media: %w", err)
-	}
+		if err != nil {
+			return fmt.Errorf("failed to create media: %w", err)
+		}
 
-	return nil
+		return bump(tx, "posts", "media_count", media.PostID, 1)
+	})
 }
 
 // GetByPostID retrieves all media for a post
@@ -97,31 +99,42 @@ func (s *MediaStore) GetByPostID(postID string) ([]models.Media, error) {
 
 // Delete deletes a media record
 func (s *MediaStore) Delete(mediaID string) error {
-	query := `DELETE FROM media WHERE id = ?`
-	_, err := s.db.Exec(query, mediaID)
-	if err != nil {
-		return fmt.Errorf("failed to delete media: %w", err)
-	}
-	return nil
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			UPDATE posts SET media_count = media_count - 1
+			WHERE id = (SELECT post_id FROM media WHERE id = ?)
+		`
+		if _, err := tx.Exec(query, mediaID); err != nil {
+			return fmt.Errorf("failed to update media_count: %w", err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM media WHERE id = ?`, mediaID); err != nil {
+			return fmt.Errorf("failed to delete media: %w", err)
+		}
+		return nil
+	})
 }
 
 // DeleteByPostID deletes all media for a post
 func (s *MediaStore) DeleteByPostID(postID string) error {
-	query := `DELETE FROM media WHERE post_id = ?`
-	_, err := s.db.Exec(query, postID)
-	if err != nil {
-		return fmt.Errorf("failed to delete media: %w", err)
-	}
-	return nil
+	return withTx(s.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`DELETE FROM media WHERE post_id = ?`, postID); err != nil {
+			return fmt.Errorf("failed to delete media: %w", err)
+		}
+
+		if _, err := tx.Exec(`UPDATE posts SET media_count = 0 WHERE id = ?`, postID); err != nil {
+			return fmt.Errorf("failed to update media_count: %w", err)
+		}
+		return nil
+	})
 }
 
-// GetMediaCount returns number of media items for a post
+// GetMediaCount returns number of media items for a post, or 0 if there's
+// no such post
 func (s *MediaStore) GetMediaCount(postID string) (int, error) {
-	query := `SELECT COUNT(*) FROM media WHERE post_id = ?`
-
 	var count int
-	err := s.db.QueryRow(query, postID).Scan(&count)
-	if err != nil {
+	err := s.db.QueryRow(`SELECT media_count FROM posts WHERE id = ?`, postID).Scan(&count)
+	if err != nil && err != sql.ErrNoRows {
 		return 0, fmt.Errorf("failed to get media count: %w", err)
 	}
 
@@ -1,16 +1,15 @@
 package store
 
 import (
-	"database/sql"
 	"fmt"
 	"time"
 )
 
 type MentionStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewMentionStore(db *sql.DB) *MentionStore {
+func NewMentionStore(db DBTX) *MentionStore {
 	return &MentionStore{db: db}
 }
 
@@ -20,40 +19,68 @@ func (s *MentionStore) CreateMentions(postID string, userIDs []string) error {
 		return nil
 	}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
 	now := time.Now().Unix()
 	query := `INSERT OR IGNORE INTO mentions (post_id, mentioned_user_id, created_at) VALUES (?, ?, ?)`
 
-	for _, userID := range userIDs {
-		_, err := tx.Exec(query, postID, userID, now)
-		if err != nil {
-			return fmt.Errorf("failed to create mention: %w", err)
+	return withTx(s.db, func(tx DBTX) error {
+		for _, userID := range userIDs {
+			if _, err := tx.Exec(query, postID, userID, now); err != nil {
+				return fmt.Errorf("failed to create mention: %w", err)
+			}
 		}
+		return nil
+	})
+}
+
+// GetPostMentions returns the IDs of the users a post mentions
+func (s *MentionStore) GetPostMentions(postID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT mentioned_user_id FROM mentions WHERE post_id = ?`, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post mentions: %w", err)
 	}
+	defer rows.Close()
 
-	return tx.Commit()
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// DeleteMentions removes a post's mention records, as before recreating
+// them for an edited post
+func (s *MentionStore) DeleteMentions(postID string) error {
+	if _, err := s.db.Exec(`DELETE FROM mentions WHERE post_id = ?`, postID); err != nil {
+		return fmt.Errorf("failed to delete mentions: %w", err)
+	}
+	return nil
 }
 
-// GetMentions retrieves posts that mention a user
-func (s *MentionStore) GetMentions(userID string, limit int) ([]PostWithAuthor, error) {
+// GetMentions retrieves a page of posts that mention a user, leaving out
+// posts by users blocked either way
+func (s *MentionStore) GetMentions(userID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
 	query := `
 		SELECT 
-			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id,
+			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
 			u.username
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
 		JOIN mentions m ON p.id = m.post_id
 		WHERE m.mentioned_user_id = ?
-		ORDER BY p.created_at DESC
-		LIMIT ?
-	`
+		AND NOT ` + blockedBetween("p.author_id") + `
+		AND ` + keyset + orderBy
+	args := append([]interface{}{userID, userID, userID}, keysetArgs...)
 
-	rows, err := s.db.Query(query, userID, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query mentions: %w", err)
 	}
@@ -69,6 +96,14 @@ func (s *MentionStore) GetMentions(userID string, limit int) ([]PostWithAuthor,
 			&pwa.Post.CreatedAt,
 			&pwa.Post.IsRetweet,
 			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
 			&pwa.Username,
 		)
 		if err != nil {
@@ -77,7 +112,14 @@ func (s *MentionStore) GetMentions(userID string, limit int) ([]PostWithAuthor,
 		posts = append(posts, pwa)
 	}
 
-	return posts, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
 }
 
 // GetMentionedUsers gets user IDs from usernames
@@ -1,30 +1,33 @@
 package store
 
 import (
-	"database/sql"
 	"fmt"
-	"sort"
+	"strings"
 	"time"
 
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
 	"github.com/RazinShafayet2007/twitter-cli/internal/models"
 	"github.com/oklog/ulid/v2"
 )
 
 type MessageStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewMessageStore(db *sql.DB) *MessageStore {
+func NewMessageStore(db DBTX) *MessageStore {
 	return &MessageStore{db: db}
 }
 
-// GetDB returns the underlying *sql.DB instance
-func (s *MessageStore) GetDB() *sql.DB {
-	return s.db
-}
-
-// Send creates a new message
+// Send creates a new message, unless either user has blocked the other
 func (s *MessageStore) Send(senderID, receiverID, text string) (*models.Message, error) {
+	blocked, err := eitherBlocked(s.db, senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
 	id := ulid.Make().String()
 	now := time.Now().Unix()
 
@@ -33,20 +36,11 @@ func (s *MessageStore) Send(senderID, receiverID, text string) (*models.Message,
 		VALUES (?, ?, ?, ?, ?, 0)
 	`
 
-	_, err := s.db.Exec(query, id, senderID, receiverID, text, now)
+	_, err = s.db.Exec(query, id, senderID, receiverID, text, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
 
-	// Check if sender is blocked by receiver
-	blocked, err := s.IsBlocked(receiverID, senderID)
-	if err != nil {
-		return nil, err
-	}
-	if blocked {
-		return nil, fmt.Errorf("you cannot send messages to this user")
-	}
-
 	return &models.Message{
 		ID:         id,
 		SenderID:   senderID,
@@ -57,8 +51,11 @@ func (s *MessageStore) Send(senderID, receiverID, text string) (*models.Message,
 	}, nil
 }
 
-// GetInbox retrieves messages received by a user
-func (s *MessageStore) GetInbox(userID string, limit int) ([]models.MessageWithUser, error) {
+// GetInbox retrieves a page of the messages a user received
+func (s *MessageStore) GetInbox(userID string, page Page) ([]models.MessageWithUser, error) {
+	keyset, keysetArgs := page.keyset("m.created_at", "m.id")
+	orderBy, limit := page.orderBy("m.created_at", "m.id")
+
 	query := `
 		SELECT 
 			m.id, m.sender_id, m.receiver_id, m.text, m.created_at, m.read,
@@ -66,11 +63,10 @@ func (s *MessageStore) GetInbox(userID string, limit int) ([]models.MessageWithU
 		FROM messages m
 		JOIN users u ON m.sender_id = u.id
 		WHERE m.receiver_id = ?
-		ORDER BY m.created_at DESC
-		LIMIT ?
-	`
+		AND ` + keyset + orderBy
+	args := append([]interface{}{userID}, keysetArgs...)
 
-	rows, err := s.db.Query(query, userID, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get inbox: %w", err)
 	}
@@ -96,11 +92,19 @@ func (s *MessageStore) GetInbox(userID string, limit int) ([]models.MessageWithU
 		messages = append(messages, m)
 	}
 
-	return messages, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return order(page, messages), nil
 }
 
-// GetConversation retrieves messages between two users
-func (s *MessageStore) GetConversation(user1ID, user2ID string, limit int) ([]models.MessageWithUser, error) {
+// GetConversation retrieves a page of the messages between two users,
+// newest first
+func (s *MessageStore) GetConversation(user1ID, user2ID string, page Page) ([]models.MessageWithUser, error) {
+	keyset, keysetArgs := page.keyset("m.created_at", "m.id")
+	orderBy, limit := page.orderBy("m.created_at", "m.id")
+
 	query := `
 		SELECT 
 			m.id, m.sender_id, m.receiver_id, m.text, m.created_at, m.read,
@@ -109,13 +113,12 @@ func (s *MessageStore) GetConversation(user1ID, user2ID string, limit int) ([]mo
 		FROM messages m
 		JOIN users sender ON m.sender_id = sender.id
 		JOIN users receiver ON m.receiver_id = receiver.id
-		WHERE (m.sender_id = ? AND m.receiver_id = ?)
-		   OR (m.sender_id = ? AND m.receiver_id = ?)
-		ORDER BY m.created_at ASC
-		LIMIT ?
-	`
+		WHERE ((m.sender_id = ? AND m.receiver_id = ?)
+		   OR (m.sender_id = ? AND m.receiver_id = ?))
+		AND ` + keyset + orderBy
+	args := append([]interface{}{user1ID, user2ID, user2ID, user1ID}, keysetArgs...)
 
-	rows, err := s.db.Query(query, user1ID, user2ID, user2ID, user1ID, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
@@ -142,92 +145,61 @@ func (s *MessageStore) GetConversation(user1ID, user2ID string, limit int) ([]mo
 		messages = append(messages, m)
 	}
 
-	return messages, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return order(page, messages), nil
 }
 
-// GetConversations retrieves list of conversations with unread counts
-func (s *MessageStore) GetConversations(userID string) ([]models.Conversation, error) {
-	// First, get all unique conversation partners
-	query := `
-		SELECT DISTINCT
-			CASE 
-				WHEN sender_id = ? THEN receiver_id 
-				ELSE sender_id 
-			END as other_user_id
-		FROM messages
-		WHERE sender_id = ? OR receiver_id = ?
-	`
+// GetConversations returns a page of a user's conversations with unread
+// counts, the one with the latest message first
+func (s *MessageStore) GetConversations(userID string, page Page) ([]models.Conversation, error) {
+	keyset, keysetArgs := page.keyset("l.created_at", "l.id")
+	orderBy, limit := page.orderBy("l.created_at", "l.id")
 
-	rows, err := s.db.Query(query, userID, userID, userID)
+	// The latest message exchanged with each partner
+	query := `
+		WITH exchanged AS (
+			SELECT id, text, created_at,
+				CASE WHEN sender_id = ? THEN receiver_id ELSE sender_id END AS other_id
+			FROM messages
+			WHERE sender_id = ? OR receiver_id = ?
+		), latest AS (
+			SELECT *, ROW_NUMBER() OVER (
+				PARTITION BY other_id ORDER BY created_at DESC, id DESC
+			) AS n
+			FROM exchanged
+		)
+		SELECT l.other_id, u.username, l.text, l.created_at, l.id,
+			(SELECT COUNT(*) FROM messages
+			 WHERE sender_id = l.other_id AND receiver_id = ? AND read = 0)
+		FROM latest l
+		JOIN users u ON u.id = l.other_id
+		WHERE l.n = 1 AND ` + keyset + orderBy
+	args := append([]interface{}{userID, userID, userID, userID}, keysetArgs...)
+
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get conversation partners: %w", err)
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
 	}
 	defer rows.Close()
 
-	var partnerIDs []string
+	conversations := []models.Conversation{}
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("failed to scan partner ID: %w", err)
+		var c models.Conversation
+		err := rows.Scan(&c.OtherUserID, &c.OtherUsername, &c.LastMessage, &c.LastMessageAt, &c.LastMessageID, &c.UnreadCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
 		}
-		partnerIDs = append(partnerIDs, id)
+		conversations = append(conversations, c)
 	}
 
-	if len(partnerIDs) == 0 {
-		return []models.Conversation{}, nil
-	}
-
-	// Now get details for each conversation
-	var conversations []models.Conversation
-
-	for _, partnerID := range partnerIDs {
-		// Get partner username
-		var username string
-		userQuery := `SELECT username FROM users WHERE id = ?`
-		if err := s.db.QueryRow(userQuery, partnerID).Scan(&username); err != nil {
-			continue // Skip if user deleted
-		}
-
-		// Get last message
-		var lastText string
-		var lastTime int64
-		lastMsgQuery := `
-			SELECT text, created_at
-			FROM messages
-			WHERE (sender_id = ? AND receiver_id = ?)
-			   OR (sender_id = ? AND receiver_id = ?)
-			ORDER BY created_at DESC
-			LIMIT 1
-		`
-		if err := s.db.QueryRow(lastMsgQuery, userID, partnerID, partnerID, userID).Scan(&lastText, &lastTime); err != nil {
-			continue
-		}
-
-		// Get unread count
-		var unreadCount int
-		unreadQuery := `
-			SELECT COUNT(*) FROM messages
-			WHERE sender_id = ? AND receiver_id = ? AND read = 0
-		`
-		if err := s.db.QueryRow(unreadQuery, partnerID, userID).Scan(&unreadCount); err != nil {
-			unreadCount = 0
-		}
-
-		conversations = append(conversations, models.Conversation{
-			OtherUserID:   partnerID,
-			OtherUsername: username,
-			LastMessage:   lastText,
-			LastMessageAt: lastTime,
-			UnreadCount:   unreadCount,
-		})
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
 	}
 
-	// Sort by last message time (newest first)
-	sort.Slice(conversations, func(i, j int) bool {
-		return conversations[i].LastMessageAt > conversations[j].LastMessageAt
-	})
-
-	return conversations, nil
+	return order(page, conversations), nil
 }
 
 // MarkAsRead marks all messages in a conversation as read
@@ -262,6 +234,21 @@ func (s *MessageStore) GetUnreadCount(userID string) (int, error) {
 	return count, nil
 }
 
+// GetMessageCounts returns how many messages a user has sent and received
+func (s *MessageStore) GetMessageCounts(userID string) (sent int, received int, err error) {
+	query := `SELECT COUNT(*) FROM messages WHERE sender_id = ?`
+	if err = s.db.QueryRow(query, userID).Scan(&sent); err != nil {
+		return 0, 0, fmt.Errorf("failed to get sent message count: %w", err)
+	}
+
+	query = `SELECT COUNT(*) FROM messages WHERE receiver_id = ?`
+	if err = s.db.QueryRow(query, userID).Scan(&received); err != nil {
+		return 0, 0, fmt.Errorf("failed to get received message count: %w", err)
+	}
+
+	return sent, received, nil
+}
+
 // DeleteMessage deletes a message (only sender can delete)
 func (s *MessageStore) DeleteMessage(messageID, senderID string) error {
 	query := `
@@ -280,67 +267,110 @@ func (s *MessageStore) DeleteMessage(messageID, senderID string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("message not found or you don't own it")
+		return ErrMessageNotFound
 	}
 
 	return nil
 }
 
-func (s *MessageStore) SearchMessages(userID, query string) ([]models.MessageWithUser, error) {
+// SearchMessages finds messages a user sent or received that match a
+// full-text query, best matches first. See matchQuery for the query syntax.
+// Builds without the full-text index list the newest matches first.
+func (s *MessageStore) SearchMessages(userID, query string, limit, offset int) ([]MessageSearchResult, error) {
+	if !db.FullTextSearch {
+		return s.searchMessagesText(userID, query, limit, offset)
+	}
+
+	match, err := matchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
 	sqlQuery := `
 		SELECT 
 			m.id, m.sender_id, m.receiver_id, m.text, m.created_at, m.read,
 			sender.username as sender_name,
-			receiver.username as receiver_name
+			receiver.username as receiver_name,
+			highlight(messages_fts, 1, '` + HighlightStart + `', '` + HighlightEnd + `'),
+			-bm25(messages_fts) AS score
+		FROM messages_fts
+		JOIN messages m ON m.id = messages_fts.message_id
+		JOIN users sender ON m.sender_id = sender.id
+		JOIN users receiver ON m.receiver_id = receiver.id
+		WHERE messages_fts MATCH ?
+		  AND (m.sender_id = ? OR m.receiver_id = ?)
+		ORDER BY score DESC, m.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	return s.scanSearch(sqlQuery, match, userID, userID, limit, offset)
+}
+
+// searchMessagesText is SearchMessages for builds without the full-text
+// index: every word or phrase must appear in the text
+func (s *MessageStore) searchMessagesText(userID, query string, limit, offset int) ([]MessageSearchResult, error) {
+	terms, err := plainQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	conds := make([]string, len(terms))
+	args := []interface{}{userID, userID}
+	for i, term := range terms {
+		conds[i] = containsTerm("m.text")
+		args = append(args, term)
+	}
+	args = append(args, limit, offset)
+
+	sqlQuery := `
+		SELECT 
+			m.id, m.sender_id, m.receiver_id, m.text, m.created_at, m.read,
+			sender.username as sender_name,
+			receiver.username as receiver_name,
+			m.text,
+			0 AS score
 		FROM messages m
 		JOIN users sender ON m.sender_id = sender.id
 		JOIN users receiver ON m.receiver_id = receiver.id
 		WHERE (m.sender_id = ? OR m.receiver_id = ?)
-		  AND m.text LIKE ?
-		ORDER BY m.created_at DESC
-		LIMIT 50
+		  AND ` + strings.Join(conds, " AND ") + `
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT ? OFFSET ?
 	`
 
-	searchTerm := "%" + query + "%"
+	return s.scanSearch(sqlQuery, args...)
+}
 
-	rows, err := s.db.Query(sqlQuery, userID, userID, searchTerm)
+// scanSearch runs a message search query and scans its results
+func (s *MessageStore) scanSearch(sqlQuery string, args ...interface{}) ([]MessageSearchResult, error) {
+	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search messages: %w", err)
 	}
 	defer rows.Close()
 
-	var messages []models.MessageWithUser
+	var results []MessageSearchResult
 	for rows.Next() {
-		var m models.MessageWithUser
+		var r MessageSearchResult
 		var readInt int
 		err := rows.Scan(
-			&m.Message.ID,
-			&m.Message.SenderID,
-			&m.Message.ReceiverID,
-			&m.Message.Text,
-			&m.Message.CreatedAt,
+			&r.Message.ID,
+			&r.Message.SenderID,
+			&r.Message.ReceiverID,
+			&r.Message.Text,
+			&r.Message.CreatedAt,
 			&readInt,
-			&m.SenderName,
-			&m.ReceiverName,
+			&r.SenderName,
+			&r.ReceiverName,
+			&r.Snippet,
+			&r.Score,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
-		m.Message.Read = readInt == 1
-		messages = append(messages, m)
-	}
-
-	return messages, nil
-}
-
-func (s *MessageStore) IsBlocked(blockerID, blockedID string) (bool, error) {
-	query := `SELECT COUNT(*) FROM blocks WHERE blocker_id = ? AND blocked_id = ?`
-
-	var count int
-	err := s.db.QueryRow(query, blockerID, blockedID).Scan(&count)
-	if err != nil {
-		return false, fmt.Errorf("failed to check block status: %w", err)
+		r.Message.Read = readInt == 1
+		results = append(results, r)
 	}
 
-	return count > 0, nil
+	return results, rows.Err()
 }
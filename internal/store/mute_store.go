@@ -0,0 +1,165 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+type MuteStore struct {
+	db DBTX
+}
+
+func NewMuteStore(db DBTX) *MuteStore {
+	return &MuteStore{db: db}
+}
+
+// Mute mutes a user (by ID), word or hashtag for userID until expiresAt, or
+// until unmuted if expiresAt is nil. Muting again replaces the expiry.
+func (s *MuteStore) Mute(userID, kind, value string, expiresAt *int64) error {
+	if err := s.deleteExpired(userID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO mutes (user_id, kind, value, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, kind, value) DO UPDATE SET expires_at = excluded.expires_at
+	`
+
+	_, err := s.db.Exec(query, userID, kind, value, time.Now().Unix(), expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to mute: %w", err)
+	}
+
+	return nil
+}
+
+// Unmute removes a mute
+func (s *MuteStore) Unmute(userID, kind, value string) error {
+	if err := s.deleteExpired(userID); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM mutes WHERE user_id = ? AND kind = ? AND value = ?`
+
+	result, err := s.db.Exec(query, userID, kind, value)
+	if err != nil {
+		return fmt.Errorf("failed to unmute: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotMuted
+	}
+
+	return nil
+}
+
+// GetMutes returns a page of a user's mutes that haven't expired, newest
+// first, with muted users by username
+func (s *MuteStore) GetMutes(userID string, page Page) ([]models.Mute, error) {
+	if err := s.deleteExpired(userID); err != nil {
+		return nil, err
+	}
+
+	// Mutes have no ID of their own, so what's muted breaks ties. It must
+	// match models.Mute.Cursor.
+	key := `m.kind || ':' || COALESCE(u.username, m.value)`
+	keyset, keysetArgs := page.keyset("m.created_at", key)
+	orderBy, limit := page.orderBy("m.created_at", key)
+
+	query := `
+		SELECT m.user_id, m.kind, COALESCE(u.username, m.value), m.created_at, m.expires_at
+		FROM mutes m
+		LEFT JOIN users u ON m.kind = 'user' AND m.value = u.id
+		WHERE m.user_id = ? AND ` + keyset + orderBy
+	args := append([]interface{}{userID}, keysetArgs...)
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mutes: %w", err)
+	}
+	defer rows.Close()
+
+	var mutes []models.Mute
+	for rows.Next() {
+		var m models.Mute
+		err := rows.Scan(&m.UserID, &m.Kind, &m.Value, &m.CreatedAt, &m.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan mute: %w", err)
+		}
+		mutes = append(mutes, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating mutes: %w", err)
+	}
+
+	return order(page, mutes), nil
+}
+
+// deleteExpired clears out a user's expired mutes. Queries ignore expired
+// mutes anyway; this just keeps them from piling up.
+func (s *MuteStore) deleteExpired(userID string) error {
+	query := `DELETE FROM mutes WHERE user_id = ? AND expires_at <= ?`
+
+	if _, err := s.db.Exec(query, userID, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to remove expired mutes: %w", err)
+	}
+
+	return nil
+}
+
+// mutedPost is an SQL condition that holds when the post aliased post, or
+// the post it retweets, is hidden by an active mute of the user bound to the
+// first ?. The second ? is the current Unix time. A user's own posts are
+// never hidden from them.
+func mutedPost(post string) string {
+	mutedWord := `mp.id IN (
+				SELECT post_id FROM posts_fts WHERE posts_fts MATCH '"' || mu.value || '"'
+			)`
+	if !db.FullTextSearch {
+		mutedWord = containsWords("mp.text", "mu.value")
+	}
+
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM mutes mu
+		JOIN posts mp ON mp.id = COALESCE(%[1]s.original_post_id, %[1]s.id)
+		WHERE mu.user_id = ?
+		AND (mu.expires_at IS NULL OR mu.expires_at > ?)
+		AND %[1]s.author_id != mu.user_id
+		AND (
+			(mu.kind = 'user' AND mu.value IN (%[1]s.author_id, mp.author_id))
+			OR (mu.kind = 'hashtag' AND EXISTS (
+				SELECT 1 FROM post_hashtags mph JOIN hashtags mh ON mph.hashtag_id = mh.id
+				WHERE mph.post_id = mp.id AND mh.tag = mu.value
+			))
+			OR (mu.kind = 'word' AND %[2]s)
+		)
+	)`, post, mutedWord)
+}
+
+// mutedUser reports whether userID has an active mute on otherID
+func mutedUser(q DBTX, userID, otherID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM mutes
+			WHERE user_id = ? AND kind = 'user' AND value = ?
+			AND (expires_at IS NULL OR expires_at > ?)
+		)
+	`
+
+	var muted bool
+	err := q.QueryRow(query, userID, otherID, time.Now().Unix()).Scan(&muted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mutes: %w", err)
+	}
+
+	return muted, nil
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+func TestMutes(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	carol, _ := stores.Users.Create("carol")
+
+	stores.Social.Follow(alice.ID, bob.ID)
+	stores.Social.Follow(alice.ID, carol.ID)
+
+	bobPost, _ := stores.Posts.Create(bob.ID, "good morning")
+	spoiler, _ := stores.Posts.Create(carol.ID, "The ending spoilers are wild")
+	tagged, _ := stores.Posts.Create(carol.ID, "Watching #worldcup")
+	stores.Hashtags.LinkPostToHashtags(tagged.ID, []string{"worldcup"})
+	plain, _ := stores.Posts.Create(carol.ID, "Nothing to see")
+	retweet, _ := stores.Posts.Retweet(carol.ID, bobPost.ID)
+	own, _ := stores.Posts.Create(alice.ID, "my own spoilers")
+
+	feedIDs := func() []string {
+		t.Helper()
+		feed, err := stores.Posts.GetFeed(alice.ID, Page{Limit: 20})
+		if err != nil {
+			t.Fatalf("failed to get feed: %v", err)
+		}
+		var ids []string
+		for _, p := range feed {
+			ids = append(ids, p.Post.ID)
+		}
+		return ids
+	}
+
+	// Carol's retweet stands in for bob's post
+	if got := feedIDs(); len(got) != 5 {
+		t.Fatalf("expected 5 posts before muting, got %d", len(got))
+	}
+
+	past := time.Now().Add(-time.Minute).Unix()
+	stores.Mutes.Mute(alice.ID, "user", bob.ID, nil)
+	stores.Mutes.Mute(alice.ID, "word", "spoiler", nil)
+	stores.Mutes.Mute(alice.ID, "hashtag", "worldcup", nil)
+	stores.Mutes.Mute(alice.ID, "word", "nothing", &past)
+
+	// Bob's post and carol's retweet of it, the spoiler (matched by its
+	// stem) and the tagged post are hidden; the expired mute and alice's
+	// own post aren't
+	got := feedIDs()
+	sort.Strings(got)
+	want := []string{own.ID, plain.ID}
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected only alice's own post and the plain post, got %v", got)
+	}
+
+	if posts, _ := stores.Hashtags.GetPostsByHashtag("worldcup", alice.ID, Page{Limit: 10}); len(posts) != 0 {
+		t.Errorf("expected muted hashtag page to be empty, got %+v", posts)
+	}
+	if posts, _ := stores.Hashtags.GetPostsByHashtag("worldcup", "", Page{Limit: 10}); len(posts) != 1 {
+		t.Errorf("expected hashtag page without a viewer to show the post, got %+v", posts)
+	}
+	if results, _ := stores.Posts.Search("ending", alice.ID, 10, 0); len(results) != 0 {
+		t.Errorf("expected muted word to be left out of search, got %+v", results)
+	}
+
+	// Notifications from muted users, or about muted posts, are dropped
+	stores.Notifications.Create(alice.ID, bob.ID, "follow", nil)
+	stores.Notifications.Create(alice.ID, carol.ID, "mention", &spoiler.ID)
+	stores.Notifications.Create(alice.ID, carol.ID, "mention", &plain.ID)
+	if count, _ := stores.Notifications.GetUnreadCount(alice.ID); count != 1 {
+		t.Errorf("expected 1 notification, got %d", count)
+	}
+
+	// The expired mute has lifted and been cleared
+	mutes, err := stores.Mutes.GetMutes(alice.ID, Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get mutes: %v", err)
+	}
+	byName := false
+	for _, m := range mutes {
+		byName = byName || (m.Kind == "user" && m.Value == "bob")
+	}
+	if len(mutes) != 3 || !byName {
+		t.Errorf("expected 3 active mutes with bob by name, got %+v", mutes)
+	}
+
+	// Mutes page by cursor like every other list
+	first, next := Paginate(mustMutes(t, stores, alice.ID, Page{Limit: 2}.Probe()), Page{Limit: 2})
+	if len(first) != 2 || next == nil {
+		t.Fatalf("expected a first page of 2 with a cursor, got %+v, %v", first, next)
+	}
+	if rest := mustMutes(t, stores, alice.ID, Page{Limit: 2, Before: next}); len(rest) != 1 || rest[0] == first[0] || rest[0] == first[1] {
+		t.Errorf("expected the third mute on the next page, got %+v", rest)
+	}
+
+	if err := stores.Mutes.Unmute(alice.ID, "user", bob.ID); err != nil {
+		t.Fatalf("failed to unmute: %v", err)
+	}
+	if err := stores.Mutes.Unmute(alice.ID, "user", bob.ID); !errors.Is(err, ErrNotMuted) {
+		t.Errorf("expected ErrNotMuted, got %v", err)
+	}
+	if got := feedIDs(); len(got) != 3 || !strings.Contains(strings.Join(got, ","), retweet.ID) {
+		t.Errorf("expected the retweet of bob's post back after unmuting, got %v", got)
+	}
+}
+
+func mustMutes(t *testing.T, stores *Stores, userID string, page Page) []models.Mute {
+	t.Helper()
+	mutes, err := stores.Mutes.GetMutes(userID, page)
+	if err != nil {
+		t.Fatalf("failed to get mutes: %v", err)
+	}
+	return mutes
+}
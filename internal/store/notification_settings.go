@@ -0,0 +1,157 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+// defaultNotificationRule is what a user gets for a type they haven't
+// configured: every notification
+func defaultNotificationRule(notifType string) models.NotificationRule {
+	return models.NotificationRule{Type: notifType, Enabled: true}
+}
+
+// GetSettings returns a user's rule for every notification type, and their
+// quiet hours
+func (s *NotificationStore) GetSettings(userID string) (*models.NotificationSettings, error) {
+	settings := &models.NotificationSettings{}
+
+	for _, notifType := range models.NotificationTypes {
+		rule, err := s.getRule(userID, notifType)
+		if err != nil {
+			return nil, err
+		}
+		settings.Rules = append(settings.Rules, *rule)
+	}
+
+	quiet, err := s.getQuietHours(userID)
+	if err != nil {
+		return nil, err
+	}
+	settings.QuietHours = quiet
+
+	return settings, nil
+}
+
+// SetRule saves a user's rule for one notification type
+func (s *NotificationStore) SetRule(userID string, rule models.NotificationRule) error {
+	query := `
+		INSERT INTO notification_settings (user_id, type, enabled, only_following, min_likes)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, type) DO UPDATE SET
+			enabled = excluded.enabled,
+			only_following = excluded.only_following,
+			min_likes = excluded.min_likes
+	`
+
+	_, err := s.db.Exec(query, userID, rule.Type, rule.Enabled, rule.OnlyFollowing, rule.MinLikes)
+	if err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+
+	return nil
+}
+
+// SetQuietHours sets a user's quiet hours, or turns them off if quiet is nil
+func (s *NotificationStore) SetQuietHours(userID string, quiet *models.QuietHours) error {
+	var err error
+	if quiet == nil {
+		_, err = s.db.Exec(`DELETE FROM notification_quiet_hours WHERE user_id = ?`, userID)
+	} else {
+		query := `
+			INSERT INTO notification_quiet_hours (user_id, start_minute, end_minute)
+			VALUES (?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET
+				start_minute = excluded.start_minute,
+				end_minute = excluded.end_minute
+		`
+		_, err = s.db.Exec(query, userID, quiet.Start, quiet.End)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save quiet hours: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationStore) getRule(userID, notifType string) (*models.NotificationRule, error) {
+	rule := defaultNotificationRule(notifType)
+
+	query := `
+		SELECT enabled, only_following, min_likes
+		FROM notification_settings
+		WHERE user_id = ? AND type = ?
+	`
+
+	err := s.db.QueryRow(query, userID, notifType).Scan(&rule.Enabled, &rule.OnlyFollowing, &rule.MinLikes)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	return &rule, nil
+}
+
+func (s *NotificationStore) getQuietHours(userID string) (*models.QuietHours, error) {
+	query := `SELECT start_minute, end_minute FROM notification_quiet_hours WHERE user_id = ?`
+
+	var quiet models.QuietHours
+	err := s.db.QueryRow(query, userID).Scan(&quiet.Start, &quiet.End)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+
+	return &quiet, nil
+}
+
+// allowed evaluates userID's notification settings for a notification
+// about to be created at now
+func (s *NotificationStore) allowed(userID, actorID, notifType string, targetID *string, now time.Time) (bool, error) {
+	rule, err := s.getRule(userID, notifType)
+	if err != nil {
+		return false, err
+	}
+
+	if !rule.Enabled {
+		return false, nil
+	}
+
+	// Your own poll closing comes from you, whom you can't follow
+	if rule.OnlyFollowing && actorID != userID {
+		var following bool
+		query := `SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = ?)`
+		if err := s.db.QueryRow(query, userID, actorID).Scan(&following); err != nil {
+			return false, fmt.Errorf("failed to check follow: %w", err)
+		}
+		if !following {
+			return false, nil
+		}
+	}
+
+	if rule.MinLikes > 0 && targetID != nil {
+		// Targets that aren't posts have no likes
+		var likes int
+		query := `SELECT COALESCE((SELECT like_count FROM posts WHERE id = ?), 0)`
+		if err := s.db.QueryRow(query, *targetID).Scan(&likes); err != nil {
+			return false, fmt.Errorf("failed to get like count: %w", err)
+		}
+		if likes < rule.MinLikes {
+			return false, nil
+		}
+	}
+
+	quiet, err := s.getQuietHours(userID)
+	if err != nil {
+		return false, err
+	}
+	if quiet != nil && quiet.Contains(now) {
+		return false, nil
+	}
+
+	return true, nil
+}
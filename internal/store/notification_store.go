@@ -1,8 +1,8 @@
 package store
 
 import (
-	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/RazinShafayet2007/twitter-cli/internal/models"
@@ -10,29 +10,60 @@ import (
 )
 
 type NotificationStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewNotificationStore(db *sql.DB) *NotificationStore {
+func NewNotificationStore(db DBTX) *NotificationStore {
 	return &NotificationStore{db: db}
 }
 
-// Create creates a new notification
+// Create creates a new notification. Nothing is created for the user's own
+// actions, between users blocked either way, for actors or posts the user
+// has muted, or when the user's notification settings turn it away.
 func (s *NotificationStore) Create(userID, actorID, notifType string, targetID *string) error {
-	// Don't notify yourself
-	if userID == actorID {
+	// Don't notify yourself, except that your poll has closed
+	if userID == actorID && notifType != "poll" {
+		return nil
+	}
+
+	// Nor about users either of you has blocked
+	blocked, err := eitherBlocked(s.db, userID, actorID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return nil
+	}
+
+	// Nor about users, words or hashtags the user has muted
+	muted, err := s.muted(userID, actorID, targetID)
+	if err != nil {
+		return err
+	}
+	if muted {
+		return nil
+	}
+
+	// Nor if the user's notification settings turn it away
+	allowed, err := s.allowed(userID, actorID, notifType, targetID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !allowed {
 		return nil
 	}
 
 	id := ulid.Make().String()
 	now := time.Now().Unix()
 
+	// The same action twice, such as liking a post that was already
+	// liked, keeps the existing notification
 	query := `
-		INSERT INTO notifications (id, user_id, actor_id, type, target_id, created_at, read)
+		INSERT OR IGNORE INTO notifications (id, user_id, actor_id, type, target_id, created_at, read)
 		VALUES (?, ?, ?, ?, ?, ?, 0)
 	`
 
-	_, err := s.db.Exec(query, id, userID, actorID, notifType, targetID, now)
+	_, err = s.db.Exec(query, id, userID, actorID, notifType, targetID, now)
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
@@ -40,31 +71,88 @@ func (s *NotificationStore) Create(userID, actorID, notifType string, targetID *
 	return nil
 }
 
-// GetNotifications retrieves notifications for a user
-func (s *NotificationStore) GetNotifications(userID string, unreadOnly bool, limit int) ([]models.NotificationWithDetails, error) {
+// muted reports whether userID has muted actorID, or a word or hashtag in
+// the post targetID refers to
+func (s *NotificationStore) muted(userID, actorID string, targetID *string) (bool, error) {
+	muted, err := mutedUser(s.db, userID, actorID)
+	if err != nil || muted || targetID == nil {
+		return muted, err
+	}
+
+	query := `SELECT EXISTS (SELECT 1 FROM posts p WHERE p.id = ? AND ` + mutedPost("p") + `)`
+
+	err = s.db.QueryRow(query, *targetID, userID, time.Now().Unix()).Scan(&muted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mutes: %w", err)
+	}
+
+	return muted, nil
+}
+
+// Retract removes the notification actorID's action caused userID, such
+// as a like's once the post is unliked. It's not an error if there is none.
+func (s *NotificationStore) Retract(userID, actorID, notifType string, targetID *string) error {
+	query := `
+		DELETE FROM notifications
+		WHERE user_id = ? AND actor_id = ? AND type = ? AND IFNULL(target_id, '') = IFNULL(?, '')
+	`
+
+	_, err := s.db.Exec(query, userID, actorID, notifType, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to retract notification: %w", err)
+	}
+
+	return nil
+}
+
+// RetractTarget removes every notification about a post or message, for
+// when it is deleted
+func (s *NotificationStore) RetractTarget(targetID string) error {
+	_, err := s.db.Exec(`DELETE FROM notifications WHERE target_id = ?`, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to retract notifications: %w", err)
+	}
+
+	return nil
+}
+
+// notificationTargetText is the text of the post or message a notification
+// aliased n is about, joined as p and m
+const notificationTargetText = `
+	CASE
+		WHEN n.type IN ('like', 'retweet', 'mention', 'reply', 'quote', 'poll') THEN p.text
+		WHEN n.type = 'message' THEN m.text
+		ELSE NULL
+	END`
+
+const notificationTargetJoins = `
+	LEFT JOIN posts p ON n.target_id = p.id AND n.type IN ('like', 'retweet', 'mention', 'reply', 'quote', 'poll')
+	LEFT JOIN messages m ON n.target_id = m.id AND n.type = 'message'`
+
+// GetNotifications retrieves a page of a user's notifications
+func (s *NotificationStore) GetNotifications(userID string, unreadOnly bool, page Page) ([]models.NotificationWithDetails, error) {
+	keyset, keysetArgs := page.keyset("n.created_at", "n.id")
+	orderBy, limit := page.orderBy("n.created_at", "n.id")
+
 	query := `
 		SELECT 
 			n.id, n.user_id, n.actor_id, n.type, n.target_id, n.created_at, n.read,
 			u.username as actor_name,
-			CASE 
-				WHEN n.type IN ('like', 'retweet') THEN p.text
-				WHEN n.type = 'message' THEN m.text
-				ELSE NULL
-			END as target_text
+			` + notificationTargetText + ` as target_text
 		FROM notifications n
 		JOIN users u ON n.actor_id = u.id
-		LEFT JOIN posts p ON n.target_id = p.id AND n.type IN ('like', 'retweet')
-		LEFT JOIN messages m ON n.target_id = m.id AND n.type = 'message'
+		` + notificationTargetJoins + `
 		WHERE n.user_id = ?
-	`
+		AND ` + keyset
+	args := append([]interface{}{userID}, keysetArgs...)
 
 	if unreadOnly {
 		query += " AND n.read = 0"
 	}
 
-	query += " ORDER BY n.created_at DESC LIMIT ?"
+	query += orderBy
 
-	rows, err := s.db.Query(query, userID, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get notifications: %w", err)
 	}
@@ -92,7 +180,75 @@ func (s *NotificationStore) GetNotifications(userID string, unreadOnly bool, lim
 		notifications = append(notifications, n)
 	}
 
-	return notifications, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return order(page, notifications), nil
+}
+
+// GetGroupedNotifications retrieves a user's notifications with likes and
+// retweets of the same post, and follows, grouped together. Read and unread
+// notifications are grouped separately. The page counts groups, which are
+// ordered by their newest notification.
+func (s *NotificationStore) GetGroupedNotifications(userID string, unreadOnly bool, page Page) ([]models.NotificationGroup, error) {
+	keyset, keysetArgs := page.keyset("MAX(n.created_at)", "MAX(n.id)")
+	orderBy, limit := page.orderBy("MAX(n.created_at)", "MAX(n.id)")
+
+	query := `
+		SELECT
+			n.type, n.target_id, n.read,
+			COUNT(*),
+			MAX(n.created_at),
+			MAX(n.id),
+			GROUP_CONCAT(n.actor_name, ' '),
+			` + notificationTargetText + `
+		FROM (
+			SELECT n.*, u.username AS actor_name
+			FROM notifications n
+			JOIN users u ON n.actor_id = u.id
+			WHERE n.user_id = ?
+			ORDER BY n.created_at DESC, n.id DESC
+		) n
+		` + notificationTargetJoins + `
+	`
+
+	if unreadOnly {
+		query += " WHERE n.read = 0"
+	}
+
+	query += `
+		GROUP BY
+			n.type, n.read,
+			CASE WHEN n.type IN ('like', 'retweet', 'follow') THEN IFNULL(n.target_id, '') ELSE n.id END
+		HAVING ` + keyset + orderBy
+	args := append([]interface{}{userID}, keysetArgs...)
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []models.NotificationGroup
+	for rows.Next() {
+		var g models.NotificationGroup
+		var readInt int
+		var actors string
+		err := rows.Scan(&g.Type, &g.TargetID, &readInt, &g.Count, &g.CreatedAt, &g.LatestID, &actors, &g.TargetText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		g.Read = readInt == 1
+		g.Actors = strings.Fields(actors)
+		groups = append(groups, g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return order(page, groups), nil
 }
 
 // MarkAsRead marks notifications as read
@@ -131,7 +287,7 @@ func (s *NotificationStore) DeleteNotification(notificationID, userID string) er
 
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("notification not found")
+		return ErrNotificationNotFound
 	}
 
 	return nil
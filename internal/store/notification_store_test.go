@@ -0,0 +1,174 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+func TestNotifications_DedupeRetractGroup(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	notifications := stores.Notifications
+	alice, _ := stores.Users.Create("alice")
+	post, _ := stores.Posts.Create(alice.ID, "Hello world")
+
+	var likers []string
+	for _, name := range []string{"bob", "carol", "dave", "erin"} {
+		user, _ := stores.Users.Create(name)
+		likers = append(likers, user.ID)
+		notifications.Create(alice.ID, user.ID, "like", &post.ID)
+		notifications.Create(alice.ID, user.ID, "follow", nil)
+	}
+
+	// Liking twice doesn't notify twice
+	notifications.Create(alice.ID, likers[0], "like", &post.ID)
+	if count, _ := notifications.GetUnreadCount(alice.ID); count != 8 {
+		t.Fatalf("expected 8 notifications, got %d", count)
+	}
+
+	// Undoing a like or follow takes its notification back
+	if err := notifications.Retract(alice.ID, likers[3], "like", &post.ID); err != nil {
+		t.Fatalf("failed to retract: %v", err)
+	}
+	if err := notifications.Retract(alice.ID, likers[3], "follow", nil); err != nil {
+		t.Fatalf("failed to retract: %v", err)
+	}
+
+	reply, _ := stores.Posts.CreateReply(likers[0], "Hi back", post.ID)
+	notifications.Create(alice.ID, likers[0], "reply", &reply.ID)
+
+	groups, err := notifications.GetGroupedNotifications(alice.ID, false, Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to get groups: %v", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("expected like, follow and reply groups, got %+v", groups)
+	}
+
+	byType := make(map[string]int)
+	for i, g := range groups {
+		byType[g.Type] = i
+	}
+
+	likes := groups[byType["like"]]
+	if likes.Count != 3 || len(likes.Actors) != 3 || likes.TargetText == nil || *likes.TargetText != "Hello world" {
+		t.Errorf("expected 3 likes of the post, got %+v", likes)
+	}
+	if follows := groups[byType["follow"]]; follows.Count != 3 {
+		t.Errorf("expected 3 follows, got %+v", follows)
+	}
+	if replies := groups[byType["reply"]]; replies.TargetText == nil || *replies.TargetText != "Hi back" {
+		t.Errorf("expected the reply's text, got %+v", replies)
+	}
+
+	// Read notifications are grouped apart from new ones
+	notifications.MarkAsRead(alice.ID)
+	frank, _ := stores.Users.Create("frank")
+	notifications.Create(alice.ID, frank.ID, "like", &post.ID)
+
+	groups, _ = notifications.GetGroupedNotifications(alice.ID, true, Page{Limit: 10})
+	if len(groups) != 1 || groups[0].Count != 1 || groups[0].Actors[0] != "frank" {
+		t.Errorf("expected one unread like from frank, got %+v", groups)
+	}
+
+	// Deleting the post takes back everything about it
+	if err := notifications.RetractTarget(post.ID); err != nil {
+		t.Fatalf("failed to retract target: %v", err)
+	}
+	all, _ := notifications.GetNotifications(alice.ID, false, Page{Limit: 20})
+	for _, n := range all {
+		if n.Notification.TargetID != nil && *n.Notification.TargetID == post.ID {
+			t.Errorf("expected notifications about the post to be gone, got %+v", n)
+		}
+	}
+}
+
+func TestNotifications_Settings(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	notifications := stores.Notifications
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	carol, _ := stores.Users.Create("carol")
+	post, _ := stores.Posts.Create(alice.ID, "Hello world")
+
+	stores.Social.Follow(alice.ID, carol.ID)
+
+	notifications.SetRule(alice.ID, models.NotificationRule{Type: "message", Enabled: false})
+	notifications.SetRule(alice.ID, models.NotificationRule{Type: "follow", Enabled: true, OnlyFollowing: true})
+	notifications.SetRule(alice.ID, models.NotificationRule{Type: "like", Enabled: true, MinLikes: 2})
+
+	count := func() int {
+		t.Helper()
+		n, err := notifications.GetUnreadCount(alice.ID)
+		if err != nil {
+			t.Fatalf("failed to count notifications: %v", err)
+		}
+		return n
+	}
+
+	// Disabled type
+	notifications.Create(alice.ID, bob.ID, "message", nil)
+	if got := count(); got != 0 {
+		t.Errorf("expected disabled messages to be dropped, got %d", got)
+	}
+
+	// Only from people alice follows
+	notifications.Create(alice.ID, bob.ID, "follow", nil)
+	notifications.Create(alice.ID, carol.ID, "follow", nil)
+	if got := count(); got != 1 {
+		t.Errorf("expected only carol's follow, got %d", got)
+	}
+
+	// Likes only once the post has two
+	stores.Social.Like(bob.ID, post.ID)
+	notifications.Create(alice.ID, bob.ID, "like", &post.ID)
+	if got := count(); got != 1 {
+		t.Errorf("expected the first like to be held back, got %d", got)
+	}
+	stores.Social.Like(carol.ID, post.ID)
+	notifications.Create(alice.ID, carol.ID, "like", &post.ID)
+	if got := count(); got != 2 {
+		t.Errorf("expected the second like to notify, got %d", got)
+	}
+
+	// Quiet hours around now drop everything
+	now := time.Now()
+	minute := now.Hour()*60 + now.Minute()
+	quiet := &models.QuietHours{Start: (minute + 1439) % 1440, End: (minute + 2) % 1440}
+	if err := notifications.SetQuietHours(alice.ID, quiet); err != nil {
+		t.Fatalf("failed to set quiet hours: %v", err)
+	}
+	notifications.Create(alice.ID, carol.ID, "mention", &post.ID)
+	if got := count(); got != 2 {
+		t.Errorf("expected nothing during quiet hours, got %d", got)
+	}
+
+	settings, err := notifications.GetSettings(alice.ID)
+	if err != nil {
+		t.Fatalf("failed to get settings: %v", err)
+	}
+	if len(settings.Rules) != len(models.NotificationTypes) || settings.QuietHours == nil || *settings.QuietHours != *quiet {
+		t.Errorf("expected a rule per type and the quiet hours, got %+v", settings)
+	}
+
+	notifications.SetQuietHours(alice.ID, nil)
+	notifications.Create(alice.ID, carol.ID, "mention", &post.ID)
+	if got := count(); got != 3 {
+		t.Errorf("expected the mention after quiet hours were turned off, got %d", got)
+	}
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+// Page selects part of a list ordered newest first. Before keeps items
+// older than its cursor and After items newer than its cursor; with neither
+// the page starts at the newest item. Pages are always returned newest
+// first; an After page holds the Limit items just after its cursor.
+type Page struct {
+	Before *models.Cursor
+	After  *models.Cursor
+	Limit  int
+}
+
+// Probe returns the page with room for one more item, so Paginate can tell
+// whether another page follows
+func (p Page) Probe() Page {
+	p.Limit++
+	return p
+}
+
+// First reports whether the page starts at the newest item
+func (p Page) First() bool {
+	return p.Before == nil && p.After == nil
+}
+
+// forward reports whether the page is read oldest first from After
+func (p Page) forward() bool {
+	return p.After != nil && p.Before == nil
+}
+
+// keyset returns the SQL condition and args that limit the created and id
+// columns to the page, or "1 = 1" for the first page
+func (p Page) keyset(created, id string) (string, []interface{}) {
+	cond := "1 = 1"
+	var args []interface{}
+
+	if p.Before != nil {
+		cond += fmt.Sprintf(" AND (%s, %s) < (?, ?)", created, id)
+		args = append(args, p.Before.CreatedAt, p.Before.ID)
+	}
+	if p.After != nil {
+		cond += fmt.Sprintf(" AND (%s, %s) > (?, ?)", created, id)
+		args = append(args, p.After.CreatedAt, p.After.ID)
+	}
+
+	return cond, args
+}
+
+// orderBy returns the ORDER BY and LIMIT clauses for the page, and the
+// limit to bind. Forward pages are read oldest first, so they take the
+// items nearest the cursor; order fixes them up afterwards.
+func (p Page) orderBy(created, id string) (string, int) {
+	dir := "DESC"
+	if p.forward() {
+		dir = "ASC"
+	}
+
+	limit := p.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+
+	return fmt.Sprintf(" ORDER BY %[1]s %[3]s, %[2]s %[3]s LIMIT ?", created, id, dir), limit
+}
+
+// order puts items read for the page newest first
+func order[T any](p Page, items []T) []T {
+	if p.forward() {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items
+}
+
+// Paged is an item of a list that can be paged through by cursor
+type Paged interface {
+	Cursor() models.Cursor
+}
+
+// Merge combines pages of different lists read with the same page p into
+// one, newest first, keeping the p.Limit items nearest its start. An item
+// in more than one list is kept once.
+func Merge[T Paged](p Page, lists ...[]T) []T {
+	seen := make(map[models.Cursor]bool)
+	var items []T
+	for _, list := range lists {
+		for _, item := range list {
+			if c := item.Cursor(); !seen[c] {
+				seen[c] = true
+				items = append(items, item)
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Cursor(), items[j].Cursor()
+		return a.CreatedAt > b.CreatedAt || (a.CreatedAt == b.CreatedAt && a.ID > b.ID)
+	})
+
+	if p.Limit > 0 && len(items) > p.Limit {
+		if p.forward() {
+			return items[len(items)-p.Limit:]
+		}
+		return items[:p.Limit]
+	}
+	return items
+}
+
+// Paginate trims items read with p.Probe() to p.Limit and returns the
+// cursor that continues in the same direction: the oldest item's for the
+// next --before page, or the newest item's for the next --after page. It's
+// nil when there are no more items that way.
+func Paginate[T Paged](items []T, p Page) ([]T, *models.Cursor) {
+	if p.Limit <= 0 || len(items) <= p.Limit {
+		return items, nil
+	}
+
+	if p.forward() {
+		items = items[len(items)-p.Limit:]
+		next := items[0].Cursor()
+		return items, &next
+	}
+
+	items = items[:p.Limit]
+	next := items[len(items)-1].Cursor()
+	return items, &next
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+// PollStore keeps the polls attached to posts and the votes cast in them
+type PollStore struct {
+	db DBTX
+}
+
+func NewPollStore(db DBTX) *PollStore {
+	return &PollStore{db: db}
+}
+
+// Create attaches a poll with options, numbered from 1, to a post
+func (s *PollStore) Create(postID string, options []string, closesAt int64) error {
+	return withTx(s.db, func(tx DBTX) error {
+		_, err := tx.Exec(`INSERT INTO polls (post_id, closes_at) VALUES (?, ?)`, postID, closesAt)
+		if err != nil {
+			return fmt.Errorf("failed to create poll: %w", err)
+		}
+
+		for i, option := range options {
+			query := `INSERT INTO poll_options (post_id, position, text) VALUES (?, ?, ?)`
+			if _, err := tx.Exec(query, postID, i+1, option); err != nil {
+				return fmt.Errorf("failed to add poll option: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Get returns the poll attached to a post as viewerID sees it, with the
+// results only if the poll has closed or they have voted
+func (s *PollStore) Get(postID, viewerID string) (*models.Poll, error) {
+	polls, err := queryPolls(s.db, []string{postID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	poll, ok := polls[postID]
+	if !ok {
+		return nil, ErrPollNotFound
+	}
+	return poll, nil
+}
+
+// Vote records userID's vote for the option at position. Each user votes
+// once per poll.
+func (s *PollStore) Vote(postID, userID string, position int) error {
+	query := `
+		INSERT OR IGNORE INTO poll_votes (post_id, user_id, position, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query, postID, userID, position, time.Now().Unix())
+	if err != nil {
+		if err.Error() == "FOREIGN KEY constraint failed" {
+			return ErrPollNotFound
+		}
+		return fmt.Errorf("failed to vote: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAlreadyVoted
+	}
+
+	return nil
+}
+
+// GetClosed returns up to limit posts whose polls had closed by now and
+// whose authors haven't been told yet, the longest closed first
+func (s *PollStore) GetClosed(now int64, limit int) ([]models.Post, error) {
+	query := `
+		SELECT p.id, p.author_id, p.text, p.created_at
+		FROM polls pl
+		JOIN posts p ON p.id = pl.post_id
+		WHERE pl.closes_at <= ? AND pl.notified = 0
+		ORDER BY pl.closes_at, pl.post_id
+		LIMIT ?
+	`
+
+	rows, err := s.db.Query(query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed polls: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var p models.Post
+		if err := rows.Scan(&p.ID, &p.AuthorID, &p.Text, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan closed poll: %w", err)
+		}
+		posts = append(posts, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating closed polls: %w", err)
+	}
+	return posts, nil
+}
+
+// MarkNotified records that a poll's author has been told it closed
+func (s *PollStore) MarkNotified(postID string) error {
+	if _, err := s.db.Exec(`UPDATE polls SET notified = 1 WHERE post_id = ?`, postID); err != nil {
+		return fmt.Errorf("failed to mark poll notified: %w", err)
+	}
+	return nil
+}
+
+// attachPolls sets Poll on each post in posts that has one, reading
+// retweets through to the original's. Results are left out of polls that
+// are still open, since no viewer is known here.
+func attachPolls(q DBTX, posts []PostWithAuthor) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(posts))
+	for i, pwa := range posts {
+		ids[i] = pollPostID(pwa.Post)
+	}
+
+	polls, err := queryPolls(q, ids, "")
+	if err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		posts[i].Poll = polls[id]
+	}
+	return nil
+}
+
+// pollPostID is the ID of the post whose poll p shows
+func pollPostID(p models.Post) string {
+	if p.IsRetweet && p.OriginalPostID != nil {
+		return *p.OriginalPostID
+	}
+	return p.ID
+}
+
+// queryPolls returns the polls attached to the posts postIDs, by post ID,
+// as viewerID sees them. An empty viewerID sees no votes of their own.
+func queryPolls(q DBTX, postIDs []string, viewerID string) (map[string]*models.Poll, error) {
+	in, args := inList(postIDs)
+	query := `
+		SELECT pl.post_id, pl.closes_at, o.position, o.text,
+			(SELECT COUNT(*) FROM poll_votes v WHERE v.post_id = o.post_id AND v.position = o.position),
+			(SELECT v.position FROM poll_votes v WHERE v.post_id = pl.post_id AND v.user_id = ?)
+		FROM polls pl
+		JOIN poll_options o ON o.post_id = pl.post_id
+		WHERE pl.post_id IN (` + in + `)
+		ORDER BY pl.post_id, o.position
+	`
+
+	rows, err := q.Query(query, append([]interface{}{viewerID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query polls: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now().Unix()
+	polls := make(map[string]*models.Poll)
+	for rows.Next() {
+		var postID string
+		var closesAt int64
+		var option models.PollOption
+		var votes int
+		var voted sql.NullInt64
+		if err := rows.Scan(&postID, &closesAt, &option.Position, &option.Text, &votes, &voted); err != nil {
+			return nil, fmt.Errorf("failed to scan poll: %w", err)
+		}
+
+		poll, ok := polls[postID]
+		if !ok {
+			poll = &models.Poll{PostID: postID, ClosesAt: closesAt, Closed: closesAt <= now}
+			if voted.Valid {
+				position := int(voted.Int64)
+				poll.Voted = &position
+			}
+			polls[postID] = poll
+		}
+
+		poll.TotalVotes += votes
+		if poll.ShowsResults() {
+			option.Votes = &votes
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating polls: %w", err)
+	}
+	return polls, nil
+}
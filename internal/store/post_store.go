@@ -2,19 +2,20 @@ package store
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
 	"github.com/oklog/ulid/v2"
 )
 
 type PostStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewPostStore(db *sql.DB) *PostStore {
+func NewPostStore(db DBTX) *PostStore {
 	return &PostStore{db: db}
 }
 
@@ -23,14 +24,20 @@ func (s *PostStore) Create(authorID, text string) (*models.Post, error) {
 	id := ulid.Make().String()
 	now := time.Now().Unix()
 
-	query := `
-		INSERT INTO posts (id, author_id, text, created_at, is_retweet)
-		VALUES (?, ?, ?, ?, 0)
-	`
+	err := withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO posts (id, author_id, text, created_at, is_retweet)
+			VALUES (?, ?, ?, ?, 0)
+		`
+
+		if _, err := tx.Exec(query, id, authorID, text, now); err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
 
-	_, err := s.db.Exec(query, id, authorID, text, now)
+		return bump(tx, "users", "post_count", authorID, 1)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create post: %w", err)
+		return nil, err
 	}
 
 	return &models.Post{
@@ -53,14 +60,23 @@ func (s *PostStore) CreateReply(authorID, text, parentPostID string) (*models.Po
 	id := ulid.Make().String()
 	now := time.Now().Unix()
 
-	query := `
-		INSERT INTO posts (id, author_id, text, created_at, is_retweet, parent_post_id)
-		VALUES (?, ?, ?, ?, 0, ?)
-	`
+	err = withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO posts (id, author_id, text, created_at, is_retweet, parent_post_id)
+			VALUES (?, ?, ?, ?, 0, ?)
+		`
+
+		if _, err := tx.Exec(query, id, authorID, text, now, parentPostID); err != nil {
+			return fmt.Errorf("failed to create reply: %w", err)
+		}
 
-	_, err = s.db.Exec(query, id, authorID, text, now, parentPostID)
+		if err := bump(tx, "posts", "reply_count", parentPostID, 1); err != nil {
+			return err
+		}
+		return bump(tx, "users", "post_count", authorID, 1)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create reply: %w", err)
+		return nil, err
 	}
 
 	return &models.Post{
@@ -73,10 +89,48 @@ func (s *PostStore) CreateReply(authorID, text, parentPostID string) (*models.Po
 	}, nil
 }
 
+// CreateQuote creates a post quoting another, with its own text
+func (s *PostStore) CreateQuote(authorID, text, quotedPostID string) (*models.Post, error) {
+	if _, err := s.GetByID(quotedPostID); err != nil {
+		return nil, fmt.Errorf("quoted %w", ErrPostNotFound)
+	}
+
+	id := ulid.Make().String()
+	now := time.Now().Unix()
+
+	err := withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO posts (id, author_id, text, created_at, is_retweet, quoted_post_id)
+			VALUES (?, ?, ?, ?, 0, ?)
+		`
+
+		if _, err := tx.Exec(query, id, authorID, text, now, quotedPostID); err != nil {
+			return fmt.Errorf("failed to create quote: %w", err)
+		}
+
+		if err := bump(tx, "posts", "quote_count", quotedPostID, 1); err != nil {
+			return err
+		}
+		return bump(tx, "users", "post_count", authorID, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Post{
+		ID:           id,
+		AuthorID:     authorID,
+		Text:         text,
+		CreatedAt:    now,
+		QuotedPostID: &quotedPostID,
+	}, nil
+}
+
 // GetByID retrieves a single post by ID
 func (s *PostStore) GetByID(postID string) (*models.Post, error) {
 	query := `
-		SELECT id, author_id, text, created_at, is_retweet, original_post_id, parent_post_id
+		SELECT id, author_id, text, created_at, is_retweet, original_post_id, parent_post_id,
+			like_count, retweet_count, reply_count, media_count, quote_count, quoted_post_id, edited_at
 		FROM posts
 		WHERE id = ?
 	`
@@ -90,10 +144,17 @@ func (s *PostStore) GetByID(postID string) (*models.Post, error) {
 		&post.IsRetweet,
 		&post.OriginalPostID,
 		&post.ParentPostID,
+		&post.LikeCount,
+		&post.RetweetCount,
+		&post.ReplyCount,
+		&post.MediaCount,
+		&post.QuoteCount,
+		&post.QuotedPostID,
+		&post.EditedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("post not found")
+		return nil, ErrPostNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post: %w", err)
@@ -102,26 +163,228 @@ func (s *PostStore) GetByID(postID string) (*models.Post, error) {
 	return &post, nil
 }
 
-// PostWithAuthor represents a post with author information
+// GetOriginal returns the post postID refers to: the post itself, or the
+// post it retweets if it's a retweet
+func (s *PostStore) GetOriginal(postID string) (*models.Post, error) {
+	post, err := s.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if !post.IsRetweet {
+		return post, nil
+	}
+
+	if post.OriginalPostID == nil {
+		return nil, ErrPostNotFound
+	}
+	return s.GetByID(*post.OriginalPostID)
+}
+
+// PostWithAuthor represents a post with author information. A retweet is
+// read through to the post it retweets: its Text, QuotedPostID, EditedAt
+// and MediaCount are the original's, which is in Original.
 type PostWithAuthor struct {
-	Post     models.Post
-	Username string
+	Post     models.Post     `json:"post"`
+	Username string          `json:"username"`
+	Original *PostWithAuthor `json:"original,omitempty"` // Set for retweets
+	Quoted   *QuotedPost     `json:"quoted,omitempty"`   // Set for quote posts
+	Poll     *models.Poll    `json:"poll,omitempty"`     // Set for posts with a poll
+}
+
+// QuotedPost is the post a quote post quotes, as shown nested inside it.
+// Only ID and Deleted are set once the quoted post has been deleted.
+type QuotedPost struct {
+	ID        string `json:"id"`
+	AuthorID  string `json:"author_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Text      string `json:"text,omitempty"`
+	CreatedAt int64  `json:"created_at,omitempty"`
+	Deleted   bool   `json:"deleted"`
+}
+
+// Resolve fills in what each post in posts points at: the original of
+// each retweet, the post each quote quotes and any poll
+func (s *PostStore) Resolve(posts []PostWithAuthor) error {
+	return resolve(s.db, posts)
+}
+
+// resolve fills in what each post in posts points at. Every list of posts
+// goes through it before it's returned.
+func resolve(q DBTX, posts []PostWithAuthor) error {
+	if err := attachOriginals(q, posts); err != nil {
+		return err
+	}
+	if err := attachQuotes(q, posts); err != nil {
+		return err
+	}
+	return attachPolls(q, posts)
+}
+
+// attachOriginals reads each retweet in posts through to the post it
+// retweets, looking up all the originals at once. A retweet whose original
+// is gone is left with no text and no Original.
+func attachOriginals(q DBTX, posts []PostWithAuthor) error {
+	var ids []string
+	for _, pwa := range posts {
+		if pwa.Post.IsRetweet && pwa.Post.OriginalPostID != nil {
+			ids = append(ids, *pwa.Post.OriginalPostID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	in, args := inList(ids)
+	query := `
+		SELECT
+			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
+			u.username
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		WHERE p.id IN (` + in + `)
+	`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query retweeted posts: %w", err)
+	}
+	defer rows.Close()
+
+	originals := make(map[string]PostWithAuthor)
+	for rows.Next() {
+		var pwa PostWithAuthor
+		err := rows.Scan(
+			&pwa.Post.ID,
+			&pwa.Post.AuthorID,
+			&pwa.Post.Text,
+			&pwa.Post.CreatedAt,
+			&pwa.Post.IsRetweet,
+			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
+			&pwa.Username,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan retweeted post: %w", err)
+		}
+		originals[pwa.Post.ID] = pwa
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating retweeted posts: %w", err)
+	}
+
+	for i := range posts {
+		p := &posts[i].Post
+		if !p.IsRetweet || p.OriginalPostID == nil {
+			continue
+		}
+		original, ok := originals[*p.OriginalPostID]
+		if !ok {
+			continue
+		}
+		p.Text = original.Post.Text
+		p.QuotedPostID = original.Post.QuotedPostID
+		p.EditedAt = original.Post.EditedAt
+		p.MediaCount = original.Post.MediaCount
+		posts[i].Original = &original
+	}
+
+	return nil
+}
+
+// attachQuotes sets Quoted on each quote post in posts, looking up all the
+// quoted posts at once
+func attachQuotes(q DBTX, posts []PostWithAuthor) error {
+	var ids []string
+	for _, pwa := range posts {
+		if pwa.Post.QuotedPostID != nil {
+			ids = append(ids, *pwa.Post.QuotedPostID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	in, args := inList(ids)
+	query := `
+		SELECT p.id, p.author_id, u.username, p.text, p.created_at
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		WHERE p.id IN (` + in + `)
+	`
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query quoted posts: %w", err)
+	}
+	defer rows.Close()
+
+	quoted := make(map[string]QuotedPost)
+	for rows.Next() {
+		var qp QuotedPost
+		if err := rows.Scan(&qp.ID, &qp.AuthorID, &qp.Username, &qp.Text, &qp.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan quoted post: %w", err)
+		}
+		quoted[qp.ID] = qp
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating quoted posts: %w", err)
+	}
+
+	for i := range posts {
+		id := posts[i].Post.QuotedPostID
+		if id == nil {
+			continue
+		}
+		qp, ok := quoted[*id]
+		if !ok {
+			qp = QuotedPost{ID: *id, Deleted: true}
+		}
+		posts[i].Quoted = &qp
+	}
+
+	return nil
+}
+
+// Cursor returns the post's position in a list of posts
+func (p PostWithAuthor) Cursor() models.Cursor {
+	return models.Cursor{CreatedAt: p.Post.CreatedAt, ID: p.Post.ID}
+}
+
+// PostDetails is a post with its engagement stats, attached media and
+// latest quotes
+type PostDetails struct {
+	PostWithAuthor
+	LikeCount    int              `json:"like_count"`
+	RetweetCount int              `json:"retweet_count"`
+	Media        []models.Media   `json:"media"`
+	Quotes       []PostWithAuthor `json:"quotes"`
 }
 
-// GetByAuthorID retrieves all posts by a specific author
-func (s *PostStore) GetByAuthorID(authorID string, limit int) ([]PostWithAuthor, error) {
+// GetByAuthorID retrieves a page of posts by a specific author
+func (s *PostStore) GetByAuthorID(authorID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
 	query := `
 		SELECT 
 			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
 			u.username
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
 		WHERE p.author_id = ?
-		ORDER BY p.created_at DESC
-		LIMIT ?
-	`
+		AND ` + keyset + orderBy
+	args := append([]interface{}{authorID}, keysetArgs...)
 
-	rows, err := s.db.Query(query, authorID, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
@@ -138,6 +401,13 @@ func (s *PostStore) GetByAuthorID(authorID string, limit int) ([]PostWithAuthor,
 			&pwa.Post.IsRetweet,
 			&pwa.Post.OriginalPostID,
 			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
 			&pwa.Username,
 		)
 		if err != nil {
@@ -150,23 +420,29 @@ func (s *PostStore) GetByAuthorID(authorID string, limit int) ([]PostWithAuthor,
 		return nil, fmt.Errorf("error iterating posts: %w", err)
 	}
 
-	return posts, nil
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
 }
 
-// GetByUsername retrieves all posts by username
-func (s *PostStore) GetByUsername(username string, limit int) ([]PostWithAuthor, error) {
+// GetByUsername retrieves a page of posts by username
+func (s *PostStore) GetByUsername(username string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
 	query := `
 		SELECT 
 			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
 			u.username
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
 		WHERE u.username = ?
-		ORDER BY p.created_at DESC
-		LIMIT ?
-	`
+		AND ` + keyset + orderBy
+	args := append([]interface{}{username}, keysetArgs...)
 
-	rows, err := s.db.Query(query, username, limit)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
@@ -183,6 +459,13 @@ func (s *PostStore) GetByUsername(username string, limit int) ([]PostWithAuthor,
 			&pwa.Post.IsRetweet,
 			&pwa.Post.OriginalPostID,
 			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
 			&pwa.Username,
 		)
 		if err != nil {
@@ -195,39 +478,204 @@ func (s *PostStore) GetByUsername(username string, limit int) ([]PostWithAuthor,
 		return nil, fmt.Errorf("error iterating posts: %w", err)
 	}
 
-	return posts, nil
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
 }
 
 // Delete deletes a post (only by the author)
 func (s *PostStore) Delete(postID, authorID string) error {
+	return withTx(s.db, func(tx DBTX) error {
+		// What the post counted towards
+		var parentID, originalID, quotedID *string
+		var isRetweet bool
+		query := `
+			SELECT parent_post_id, original_post_id, quoted_post_id, is_retweet
+			FROM posts
+			WHERE id = ? AND author_id = ?
+		`
+
+		err := tx.QueryRow(query, postID, authorID).Scan(&parentID, &originalID, &quotedID, &isRetweet)
+		if err == sql.ErrNoRows {
+			return ErrNotPostOwner
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get post: %w", err)
+		}
+
+		// Retweets are only references, so they go with the post
+		if !isRetweet {
+			if err := deleteRetweets(tx, postID); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM posts WHERE id = ?`, postID); err != nil {
+			return fmt.Errorf("failed to delete post: %w", err)
+		}
+
+		if parentID != nil {
+			if err := bump(tx, "posts", "reply_count", *parentID, -1); err != nil {
+				return err
+			}
+		}
+		if isRetweet && originalID != nil {
+			if err := bump(tx, "posts", "retweet_count", *originalID, -1); err != nil {
+				return err
+			}
+		}
+		if !isRetweet && quotedID != nil {
+			if err := bump(tx, "posts", "quote_count", *quotedID, -1); err != nil {
+				return err
+			}
+		}
+		return bump(tx, "users", "post_count", authorID, -1)
+	})
+}
+
+// Edit replaces the text of one of an author's posts, keeping the text it
+// had as an earlier version. The caller has checked the post may be edited.
+func (s *PostStore) Edit(postID, authorID, text string) (*models.Post, error) {
+	now := time.Now().Unix()
+
+	err := withTx(s.db, func(tx DBTX) error {
+		// The text being replaced, and when it was written
+		var oldText string
+		var written int64
+		query := `
+			SELECT text, COALESCE(edited_at, created_at)
+			FROM posts
+			WHERE id = ? AND author_id = ? AND is_retweet = 0
+		`
+
+		err := tx.QueryRow(query, postID, authorID).Scan(&oldText, &written)
+		if err == sql.ErrNoRows {
+			return ErrNotPostOwner
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get post: %w", err)
+		}
+
+		query = `
+			INSERT INTO post_edits (post_id, version, text, created_at)
+			SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?
+			FROM post_edits
+			WHERE post_id = ?
+		`
+		if _, err := tx.Exec(query, postID, oldText, written, postID); err != nil {
+			return fmt.Errorf("failed to save earlier version: %w", err)
+		}
+
+		query = `UPDATE posts SET text = ?, edited_at = ? WHERE id = ?`
+		if _, err := tx.Exec(query, text, now, postID); err != nil {
+			return fmt.Errorf("failed to edit post: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(postID)
+}
+
+// GetVersions returns every version of a post's text, oldest first, ending
+// with the current one
+func (s *PostStore) GetVersions(postID string) ([]models.PostVersion, error) {
+	post, err := s.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		DELETE FROM posts
-		WHERE id = ? AND author_id = ?
+		SELECT version, text, created_at
+		FROM post_edits
+		WHERE post_id = ?
+		ORDER BY version
 	`
 
-	result, err := s.db.Exec(query, postID, authorID)
+	rows, err := s.db.Query(query, postID)
 	if err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+		return nil, fmt.Errorf("failed to query post versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.PostVersion
+	for rows.Next() {
+		var v models.PostVersion
+		if err := rows.Scan(&v.Version, &v.Text, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating post versions: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	current := models.PostVersion{Version: len(versions) + 1, Text: post.Text, CreatedAt: post.CreatedAt}
+	if post.EditedAt != nil {
+		current.CreatedAt = *post.EditedAt
+	}
+	return append(versions, current), nil
+}
+
+// retweetedLater is an SQL condition that holds when the post with the
+// given alias, or the post it retweets, was retweeted after it by the user
+// bound to the first two ? or by someone they follow. The last two ? are
+// as for mutedPost, so muted retweets don't count. Feeds add NOT
+// retweetedLater to show each post once, where it was last retweeted.
+func retweetedLater(post string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM posts rl
+		WHERE rl.is_retweet = 1
+		AND rl.original_post_id = COALESCE(%[1]s.original_post_id, %[1]s.id)
+		AND (rl.created_at, rl.id) > (%[1]s.created_at, %[1]s.id)
+		AND (rl.author_id = ? OR rl.author_id IN (SELECT followee_id FROM follows WHERE follower_id = ?))
+		AND NOT %[2]s
+	)`, post, mutedPost("rl"))
+}
+
+// deleteRetweets deletes every retweet of a post
+func deleteRetweets(tx DBTX, postID string) error {
+	rows, err := tx.Query(`SELECT author_id FROM posts WHERE original_post_id = ? AND is_retweet = 1`, postID)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to get retweets: %w", err)
+	}
+	var retweeters []string
+	for rows.Next() {
+		var authorID string
+		if err := rows.Scan(&authorID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan retweet: %w", err)
+		}
+		retweeters = append(retweeters, authorID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating retweets: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("post not found or you don't own this post")
+	if _, err := tx.Exec(`DELETE FROM posts WHERE original_post_id = ? AND is_retweet = 1`, postID); err != nil {
+		return fmt.Errorf("failed to delete retweets: %w", err)
 	}
 
+	for _, authorID := range retweeters {
+		if err := bump(tx, "users", "post_count", authorID, -1); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// GetFeed returns posts for a user's feed (posts from followed users + own posts)
-func (s *PostStore) GetFeed(userID string, limit, offset int) ([]PostWithAuthor, error) {
-	// This query gets:
-	// 1. Posts from users that userID follows
-	// 2. Posts from userID themselves
-	// Ordered by creation time (newest first)
+// GetFeed returns a page of a user's feed: posts from followed users and
+// their own posts, leaving out users blocked either way and anything they
+// have muted
+func (s *PostStore) GetFeed(userID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
 
 	query := `
 		SELECT 
@@ -238,20 +686,35 @@ func (s *PostStore) GetFeed(userID string, limit, offset int) ([]PostWithAuthor,
 			p.is_retweet, 
 			p.original_post_id,
 			p.parent_post_id,
+			p.like_count,
+			p.retweet_count,
+			p.reply_count,
+			p.media_count,
+			p.quote_count,
+			p.quoted_post_id,
+			p.edited_at,
 			u.username
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
-		WHERE p.author_id IN (
-			SELECT followee_id 
-			FROM follows 
-			WHERE follower_id = ?
+		LEFT JOIN posts op ON p.original_post_id = op.id
+		WHERE (
+			p.author_id IN (
+				SELECT followee_id 
+				FROM follows 
+				WHERE follower_id = ?
+			)
+			OR p.author_id = ?
 		)
-		OR p.author_id = ?
-		ORDER BY p.created_at DESC
-		LIMIT ? OFFSET ?
-	`
+		AND NOT ` + blockedBetween("p.author_id") + `
+		AND NOT ` + blockedBetween("op.author_id") + `
+		AND NOT ` + mutedPost("p") + `
+		AND NOT ` + retweetedLater("p") + `
+		AND ` + keyset + orderBy
+	now := time.Now().Unix()
+	args := []interface{}{userID, userID, userID, userID, userID, userID, userID, now, userID, userID, userID, now}
+	args = append(args, keysetArgs...)
 
-	rows, err := s.db.Query(query, userID, userID, limit, offset)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query feed: %w", err)
 	}
@@ -268,6 +731,13 @@ func (s *PostStore) GetFeed(userID string, limit, offset int) ([]PostWithAuthor,
 			&pwa.Post.IsRetweet,
 			&pwa.Post.OriginalPostID,
 			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
 			&pwa.Username,
 		)
 		if err != nil {
@@ -280,16 +750,21 @@ func (s *PostStore) GetFeed(userID string, limit, offset int) ([]PostWithAuthor,
 		return nil, fmt.Errorf("error iterating posts: %w", err)
 	}
 
-	return posts, nil
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
 }
 
-// Retweet creates a retweet of an existing post
-func (s *PostStore) Retweet(userID, originalPostID string) (*models.Post, error) {
-	// Verify original post exists
-	originalPost, err := s.GetByID(originalPostID)
+// Retweet creates a retweet of a post. A retweet is only a reference to
+// the post it retweets, with no text of its own; retweeting a retweet
+// retweets its original.
+func (s *PostStore) Retweet(userID, postID string) (*models.Post, error) {
+	originalPost, err := s.GetOriginal(postID)
 	if err != nil {
-		return nil, fmt.Errorf("original post not found")
+		return nil, fmt.Errorf("original %w", ErrPostNotFound)
 	}
+	originalPostID := originalPost.ID
 
 	// Check if user already retweeted this post
 	hasRetweeted, err := s.HasRetweeted(userID, originalPostID)
@@ -297,38 +772,142 @@ func (s *PostStore) Retweet(userID, originalPostID string) (*models.Post, error)
 		return nil, err
 	}
 	if hasRetweeted {
-		return nil, errors.New("already retweeted this post")
+		return nil, ErrAlreadyRetweeted
 	}
 
 	// Can't retweet your own post
 	if originalPost.AuthorID == userID {
-		return nil, errors.New("cannot retweet your own post")
+		return nil, ErrRetweetOwnPost
 	}
 
 	id := ulid.Make().String()
 	now := time.Now().Unix()
 
-	query := `
-		INSERT INTO posts (id, author_id, text, created_at, is_retweet, original_post_id)
-		VALUES (?, ?, ?, ?, 1, ?)
-	`
+	err = withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO posts (id, author_id, text, created_at, is_retweet, original_post_id)
+			VALUES (?, ?, '', ?, 1, ?)
+		`
+
+		if _, err := tx.Exec(query, id, userID, now, originalPostID); err != nil {
+			return fmt.Errorf("failed to create retweet: %w", err)
+		}
 
-	// Retweet uses the original post's text
-	_, err = s.db.Exec(query, id, userID, originalPost.Text, now, originalPostID)
+		if err := bump(tx, "posts", "retweet_count", originalPostID, 1); err != nil {
+			return err
+		}
+		return bump(tx, "users", "post_count", userID, 1)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create retweet: %w", err)
+		return nil, err
 	}
 
 	return &models.Post{
 		ID:             id,
 		AuthorID:       userID,
-		Text:           originalPost.Text,
 		CreatedAt:      now,
 		IsRetweet:      true,
 		OriginalPostID: &originalPostID,
 	}, nil
 }
 
+// Unretweet removes a user's retweet of a post, or of the original of a
+// retweet
+func (s *PostStore) Unretweet(userID, postID string) error {
+	originalPost, err := s.GetOriginal(postID)
+	if err != nil {
+		return err
+	}
+
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			DELETE FROM posts
+			WHERE author_id = ? AND original_post_id = ? AND is_retweet = 1
+		`
+
+		result, err := tx.Exec(query, userID, originalPost.ID)
+		if err != nil {
+			return fmt.Errorf("failed to unretweet post: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return ErrNotRetweeted
+		}
+
+		if err := bump(tx, "posts", "retweet_count", originalPost.ID, -1); err != nil {
+			return err
+		}
+		return bump(tx, "users", "post_count", userID, -1)
+	})
+}
+
+// GetQuotes returns a page of the posts quoting a post, as viewerID sees
+// them: posts by users blocked either way and posts viewerID has muted are
+// left out. Pass an empty viewerID to see every post.
+func (s *PostStore) GetQuotes(postID, viewerID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
+	query := `
+		SELECT
+			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+			p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
+			u.username
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		WHERE p.quoted_post_id = ? AND p.is_retweet = 0
+		AND NOT ` + blockedBetween("p.author_id") + `
+		AND NOT ` + mutedPost("p") + `
+		AND ` + keyset + orderBy
+	args := append([]interface{}{postID, viewerID, viewerID, viewerID, time.Now().Unix()}, keysetArgs...)
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quotes: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []PostWithAuthor
+	for rows.Next() {
+		var pwa PostWithAuthor
+		err := rows.Scan(
+			&pwa.Post.ID,
+			&pwa.Post.AuthorID,
+			&pwa.Post.Text,
+			&pwa.Post.CreatedAt,
+			&pwa.Post.IsRetweet,
+			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
+			&pwa.Username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, pwa)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
+}
+
 // HasRetweeted checks if a user has retweeted a post
 func (s *PostStore) HasRetweeted(userID, originalPostID string) (bool, error) {
 	query := `
@@ -345,126 +924,357 @@ func (s *PostStore) HasRetweeted(userID, originalPostID string) (bool, error) {
 	return count > 0, nil
 }
 
-// GetRetweetCount returns the number of retweets for a post
+// GetRetweetCount returns the number of retweets for a post, or 0 if
+// there's no such post
 func (s *PostStore) GetRetweetCount(postID string) (int, error) {
-	query := `
-		SELECT COUNT(*) FROM posts
-		WHERE original_post_id = ? AND is_retweet = 1
-	`
-
 	var count int
-	err := s.db.QueryRow(query, postID).Scan(&count)
-	if err != nil {
+	err := s.db.QueryRow(`SELECT retweet_count FROM posts WHERE id = ?`, postID).Scan(&count)
+	if err != nil && err != sql.ErrNoRows {
 		return 0, fmt.Errorf("failed to get retweet count: %w", err)
 	}
 
 	return count, nil
 }
 
-// Search searches posts by text
-func (s *PostStore) Search(query string, limit int) ([]PostWithAuthor, error) {
-	sqlQuery := `
-		SELECT 
-			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
-			u.username
-		FROM posts p
-		JOIN users u ON p.author_id = u.id
-		WHERE p.text LIKE ?
-		ORDER BY p.created_at DESC
-		LIMIT ?
-	`
+// CountByAuthor returns the number of posts written by a user, or 0 if
+// there's no such user
+func (s *PostStore) CountByAuthor(authorID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT post_count FROM users WHERE id = ?`, authorID).Scan(&count)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get post count: %w", err)
+	}
+
+	return count, nil
+}
 
-	searchTerm := "%" + query + "%"
+// Search finds posts matching a search query, best matches first. Queries
+// without words list the newest matching posts first. See
+// parser.ParseQuery for the syntax. Posts viewerID has muted are left out;
+// pass an empty viewerID to search every post. Builds without the
+// full-text index match words in the text directly, newest first.
+func (s *PostStore) Search(query, viewerID string, limit, offset int) ([]PostSearchResult, error) {
+	parsed, err := parser.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := s.db.Query(sqlQuery, searchTerm, limit)
+	filter, err := compilePostSearch(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranking and snippets need the full-text index. Without words to
+	// match, or without the index, search every post (including retweets,
+	// which aren't indexed).
+	var sqlQuery string
+	var args []interface{}
+	if filter.match != "" {
+		sqlQuery = `
+			SELECT 
+				p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+				p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
+				u.username,
+				highlight(posts_fts, 1, '` + HighlightStart + `', '` + HighlightEnd + `'),
+				-bm25(posts_fts) AS score
+			FROM posts_fts
+			JOIN posts p ON p.id = posts_fts.post_id
+			JOIN users u ON p.author_id = u.id
+			WHERE posts_fts MATCH ?
+		`
+		args = append(args, filter.match)
+	} else {
+		sqlQuery = `
+			SELECT 
+				p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+				p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
+				u.username,
+				p.text,
+				0 AS score
+			FROM posts p
+			JOIN users u ON p.author_id = u.id
+			WHERE 1 = 1
+		`
+	}
+
+	for _, cond := range filter.where {
+		sqlQuery += " AND " + cond
+	}
+	args = append(args, filter.args...)
+
+	sqlQuery += " AND NOT " + mutedPost("p")
+	args = append(args, viewerID, time.Now().Unix())
+
+	sqlQuery += ` ORDER BY score DESC, p.created_at DESC, p.id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search posts: %w", err)
 	}
 	defer rows.Close()
 
-	var posts []PostWithAuthor
+	var results []PostSearchResult
 	for rows.Next() {
-		var pwa PostWithAuthor
+		var r PostSearchResult
 		err := rows.Scan(
-			&pwa.Post.ID,
-			&pwa.Post.AuthorID,
-			&pwa.Post.Text,
-			&pwa.Post.CreatedAt,
-			&pwa.Post.IsRetweet,
-			&pwa.Post.OriginalPostID,
-			&pwa.Post.ParentPostID,
-			&pwa.Username,
+			&r.Post.ID,
+			&r.Post.AuthorID,
+			&r.Post.Text,
+			&r.Post.CreatedAt,
+			&r.Post.IsRetweet,
+			&r.Post.OriginalPostID,
+			&r.Post.ParentPostID,
+			&r.Post.LikeCount,
+			&r.Post.RetweetCount,
+			&r.Post.ReplyCount,
+			&r.Post.MediaCount,
+			&r.Post.QuoteCount,
+			&r.Post.QuotedPostID,
+			&r.Post.EditedAt,
+			&r.Username,
+			&r.Snippet,
+			&r.Score,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
-		posts = append(posts, pwa)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
 	}
 
-	return posts, nil
+	posts := make([]PostWithAuthor, len(results))
+	for i, r := range results {
+		posts[i] = r.PostWithAuthor
+	}
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].PostWithAuthor = posts[i]
+	}
+
+	return results, nil
 }
 
-// GetThread retrieves the thread context for a post (ancestors + post + direct replies)
-func (s *PostStore) GetThread(postID string) ([]PostWithAuthor, error) {
-	// Reusable recursive CTE to get ancestors and children would be nice, but simple approach:
-	// 1. Get the requested post
-	// 2. Walk up to find ancestors (or use CTE)
-	// 3. Get direct children
+// Ways to order the replies in a thread
+const (
+	ThreadSortTop = "top" // Most likes, retweets, replies and quotes first
+	ThreadSortNew = "new" // Newest first
+)
 
-	// CTE for ancestors + self + children
-	query := `
+// ThreadOptions selects how much of a conversation GetThread returns and
+// how replies are ordered
+type ThreadOptions struct {
+	Depth int    // Levels of replies under the post, or 0 for all of them
+	Sort  string // ThreadSortTop or ThreadSortNew; oldest first otherwise
+}
+
+// Thread is the conversation around a post: the posts it replies to, root
+// first, and the post itself with the tree of replies under it
+type Thread struct {
+	Ancestors []PostWithAuthor `json:"ancestors"`
+	Post      *ThreadNode      `json:"post"`
+}
+
+// ThreadNode is a post in a thread with the replies under it. MoreReplies
+// counts replies that weren't loaded because they're deeper than asked for.
+type ThreadNode struct {
+	PostWithAuthor
+	Replies     []*ThreadNode `json:"replies"`
+	MoreReplies int           `json:"more_replies,omitempty"`
+}
+
+// Walk calls fn for the node and every reply under it, depth first in
+// order, with how far below the node each one is
+func (n *ThreadNode) Walk(fn func(node *ThreadNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *ThreadNode) walk(fn func(node *ThreadNode, depth int), depth int) {
+	fn(n, depth)
+	for _, r := range n.Replies {
+		r.walk(fn, depth+1)
+	}
+}
+
+// GetThread returns the conversation around a post: its ancestors and its
+// replies, their replies and so on, down to opts.Depth levels. Posts by
+// users blocked either way are left out of the ancestors, and their replies
+// are left out of the tree along with everything under them. Pass an empty
+// viewerID to see every post. A retweet's ID gives its original's thread.
+func (s *PostStore) GetThread(postID, viewerID string, opts ThreadOptions) (*Thread, error) {
+	original, err := s.GetOriginal(postID)
+	if err != nil {
+		return nil, err
+	}
+	postID = original.ID
+
+	ancestors, err := s.threadPosts(`
 		WITH RECURSIVE ancestors AS (
-			SELECT id, author_id, text, created_at, is_retweet, original_post_id, parent_post_id, 0 as level
+			SELECT parent_post_id AS id, 1 AS level
 			FROM posts
 			WHERE id = ?
-			
+
 			UNION ALL
-			
-			SELECT p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id, a.level - 1
+
+			SELECT p.parent_post_id, a.level + 1
 			FROM posts p
-			JOIN ancestors a ON p.id = a.parent_post_id
-		),
-		children AS (
-			SELECT id, author_id, text, created_at, is_retweet, original_post_id, parent_post_id, 1 as level
-			FROM posts
-			WHERE parent_post_id = ?
+			JOIN ancestors a ON p.id = a.id
 		)
-		SELECT 
-			p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
-			u.username
-		FROM (
-			SELECT * FROM ancestors
+		SELECT `+threadColumns+`, a.level
+		FROM ancestors a
+		JOIN posts p ON p.id = a.id
+		JOIN users u ON p.author_id = u.id
+		WHERE NOT `+blockedBetween("p.author_id")+`
+		ORDER BY a.level DESC
+	`, postID, viewerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.threadPosts(`
+		WITH RECURSIVE descendants AS (
+			SELECT id, 0 AS level
+			FROM posts p
+			WHERE id = ?
+			AND NOT `+blockedBetween("p.author_id")+`
+
 			UNION ALL
-			SELECT * FROM children
-		) p
+
+			SELECT p.id, d.level + 1
+			FROM posts p
+			JOIN descendants d ON p.parent_post_id = d.id
+			WHERE (? <= 0 OR d.level < ?)
+			AND NOT `+blockedBetween("p.author_id")+`
+		)
+		SELECT `+threadColumns+`, d.level
+		FROM descendants d
+		JOIN posts p ON p.id = d.id
 		JOIN users u ON p.author_id = u.id
-		ORDER BY p.level ASC, p.created_at ASC
-	`
+		ORDER BY d.level ASC, p.created_at ASC, p.id ASC
+	`, postID, viewerID, viewerID, opts.Depth, opts.Depth, viewerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(descendants) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	thread := &Thread{Ancestors: make([]PostWithAuthor, 0, len(ancestors))}
+	for _, a := range ancestors {
+		thread.Ancestors = append(thread.Ancestors, a.PostWithAuthor)
+	}
+
+	// Posts come a level at a time, so each one's parent is already placed
+	nodes := make(map[string]*ThreadNode, len(descendants))
+	for _, d := range descendants {
+		node := &ThreadNode{PostWithAuthor: d.PostWithAuthor, Replies: []*ThreadNode{}}
+		nodes[node.Post.ID] = node
+		if d.level == 0 {
+			thread.Post = node
+			continue
+		}
+		parent := nodes[*node.Post.ParentPostID]
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	thread.Post.Walk(func(node *ThreadNode, depth int) {
+		if opts.Depth > 0 && depth == opts.Depth {
+			node.MoreReplies = node.Post.ReplyCount
+		}
+		sortReplies(node.Replies, opts.Sort)
+	})
+
+	return thread, nil
+}
+
+// threadColumns are the columns threadPosts scans, for posts p and users u
+const threadColumns = `
+	p.id, p.author_id, p.text, p.created_at, p.is_retweet, p.original_post_id, p.parent_post_id,
+	p.like_count, p.retweet_count, p.reply_count, p.media_count, p.quote_count, p.quoted_post_id, p.edited_at,
+	u.username`
+
+// threadPost is a post read for a thread with how far it is from the post
+// the thread is about
+type threadPost struct {
+	PostWithAuthor
+	level int
+}
 
-	rows, err := s.db.Query(query, postID, postID)
+// threadPosts runs a query for posts in a thread, selecting threadColumns
+// and a level
+func (s *PostStore) threadPosts(query string, args ...interface{}) ([]threadPost, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query thread: %w", err)
 	}
 	defer rows.Close()
 
-	var posts []PostWithAuthor
+	var posts []threadPost
 	for rows.Next() {
-		var pwa PostWithAuthor
+		var tp threadPost
 		err := rows.Scan(
-			&pwa.Post.ID,
-			&pwa.Post.AuthorID,
-			&pwa.Post.Text,
-			&pwa.Post.CreatedAt,
-			&pwa.Post.IsRetweet,
-			&pwa.Post.OriginalPostID,
-			&pwa.Post.ParentPostID,
-			&pwa.Username,
+			&tp.Post.ID,
+			&tp.Post.AuthorID,
+			&tp.Post.Text,
+			&tp.Post.CreatedAt,
+			&tp.Post.IsRetweet,
+			&tp.Post.OriginalPostID,
+			&tp.Post.ParentPostID,
+			&tp.Post.LikeCount,
+			&tp.Post.RetweetCount,
+			&tp.Post.ReplyCount,
+			&tp.Post.MediaCount,
+			&tp.Post.QuoteCount,
+			&tp.Post.QuotedPostID,
+			&tp.Post.EditedAt,
+			&tp.Username,
+			&tp.level,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
-		posts = append(posts, pwa)
+		posts = append(posts, tp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	resolved := make([]PostWithAuthor, len(posts))
+	for i, tp := range posts {
+		resolved[i] = tp.PostWithAuthor
+	}
+	if err := resolve(s.db, resolved); err != nil {
+		return nil, err
+	}
+	for i := range posts {
+		posts[i].PostWithAuthor = resolved[i]
 	}
 
 	return posts, nil
 }
+
+// engagement is how much a post has been liked, retweeted, replied to and
+// quoted
+func engagement(p models.Post) int {
+	return p.LikeCount + p.RetweetCount + p.ReplyCount + p.QuoteCount
+}
+
+// sortReplies orders replies to a post as by GetThread
+func sortReplies(replies []*ThreadNode, by string) {
+	sort.SliceStable(replies, func(i, j int) bool {
+		a, b := replies[i].Post, replies[j].Post
+		switch by {
+		case ThreadSortTop:
+			if ea, eb := engagement(a), engagement(b); ea != eb {
+				return ea > eb
+			}
+		case ThreadSortNew:
+			return a.CreatedAt > b.CreatedAt || (a.CreatedAt == b.CreatedAt && a.ID > b.ID)
+		}
+		return a.CreatedAt < b.CreatedAt || (a.CreatedAt == b.CreatedAt && a.ID < b.ID)
+	})
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+)
+
+func TestRetweets(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	carol, _ := stores.Users.Create("carol")
+	dave, _ := stores.Users.Create("dave")
+
+	original, _ := stores.Posts.Create(dave.ID, "hello #world")
+	bobs, err := stores.Posts.Retweet(bob.ID, original.ID)
+	if err != nil {
+		t.Fatalf("failed to retweet: %v", err)
+	}
+
+	// Retweeting a retweet retweets the original
+	carols, err := stores.Posts.Retweet(carol.ID, bobs.ID)
+	if err != nil {
+		t.Fatalf("failed to retweet a retweet: %v", err)
+	}
+	if *carols.OriginalPostID != original.ID {
+		t.Errorf("expected the retweet to point at the original, got %s", *carols.OriginalPostID)
+	}
+	if _, err := stores.Posts.Retweet(dave.ID, bobs.ID); !errors.Is(err, ErrRetweetOwnPost) {
+		t.Errorf("expected ErrRetweetOwnPost through a retweet, got %v", err)
+	}
+
+	// Retweets are read through to the original
+	posts, _ := stores.Posts.GetByUsername("carol", Page{Limit: 10})
+	if len(posts) != 1 || posts[0].Post.Text != "hello #world" || posts[0].Original == nil || posts[0].Original.Username != "dave" {
+		t.Fatalf("expected carol's retweet to show dave's post, got %+v", posts)
+	}
+
+	// The feed shows the post once, where it was last retweeted
+	stores.Social.Follow(alice.ID, bob.ID)
+	stores.Social.Follow(alice.ID, carol.ID)
+	stores.Social.Follow(alice.ID, dave.ID)
+	feed, _ := stores.Posts.GetFeed(alice.ID, Page{Limit: 10})
+	if len(feed) != 1 || feed[0].Post.ID != carols.ID {
+		t.Errorf("expected only carol's retweet in the feed, got %+v", feed)
+	}
+
+	if err := stores.Posts.Unretweet(carol.ID, bobs.ID); err != nil {
+		t.Fatalf("failed to unretweet: %v", err)
+	}
+	if err := stores.Posts.Unretweet(carol.ID, original.ID); !errors.Is(err, ErrNotRetweeted) {
+		t.Errorf("expected ErrNotRetweeted, got %v", err)
+	}
+	feed, _ = stores.Posts.GetFeed(alice.ID, Page{Limit: 10})
+	if len(feed) != 1 || feed[0].Post.ID != bobs.ID {
+		t.Errorf("expected bob's retweet in the feed after carol's was undone, got %+v", feed)
+	}
+
+	// Retweets go with the original
+	if err := stores.Posts.Delete(original.ID, dave.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := stores.Posts.GetByID(bobs.ID); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected the retweet deleted with the original, got %v", err)
+	}
+	if count, _ := stores.Posts.CountByAuthor(bob.ID); count != 0 {
+		t.Errorf("expected bob's post count back to 0, got %d", count)
+	}
+}
+
+func TestThread(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+
+	root, _ := stores.Posts.Create(alice.ID, "root")
+	focus, _ := stores.Posts.CreateReply(bob.ID, "focus", root.ID)
+	quiet, _ := stores.Posts.CreateReply(alice.ID, "quiet", focus.ID)
+	liked, _ := stores.Posts.CreateReply(alice.ID, "liked", focus.ID)
+	deep, _ := stores.Posts.CreateReply(bob.ID, "deep", liked.ID)
+	stores.Posts.CreateReply(alice.ID, "deeper", deep.ID)
+	stores.Social.Like(bob.ID, liked.ID)
+
+	thread, err := stores.Posts.GetThread(focus.ID, "", ThreadOptions{Sort: ThreadSortTop})
+	if err != nil {
+		t.Fatalf("failed to get thread: %v", err)
+	}
+	if len(thread.Ancestors) != 1 || thread.Ancestors[0].Post.ID != root.ID {
+		t.Errorf("expected the root as the only ancestor, got %+v", thread.Ancestors)
+	}
+
+	var got []string
+	thread.Post.Walk(func(node *ThreadNode, depth int) {
+		got = append(got, strings.Repeat(">", depth)+node.Post.Text)
+	})
+	want := "focus,>liked,>>deep,>>>deeper,>quiet"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+
+	// Newest first, cut off one level down
+	thread, _ = stores.Posts.GetThread(focus.ID, "", ThreadOptions{Depth: 1, Sort: ThreadSortNew})
+	replies := thread.Post.Replies
+	if len(replies) != 2 || replies[0].Post.ID != liked.ID || replies[1].Post.ID != quiet.ID {
+		t.Fatalf("expected the later reply first, got %+v", replies)
+	}
+	if len(replies[0].Replies) != 0 || replies[0].MoreReplies != 1 {
+		t.Errorf("expected the deeper reply left out and counted, got %+v", replies[0])
+	}
+
+	// A retweet's ID shows the thread of the post it retweets
+	retweet, _ := stores.Posts.Retweet(alice.ID, focus.ID)
+	thread, err = stores.Posts.GetThread(retweet.ID, "", ThreadOptions{})
+	if err != nil {
+		t.Fatalf("failed to get thread: %v", err)
+	}
+	if thread.Post.Post.ID != focus.ID || len(thread.Post.Replies) != 2 || len(thread.Ancestors) != 1 {
+		t.Errorf("expected focus's thread, got %+v", thread.Post)
+	}
+
+	if _, err := stores.Posts.GetThread("missing", "", ThreadOptions{}); !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("expected ErrPostNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RankingStore reads what the ranked feed is built from: candidate posts
+// from around a user's network, and the signals they're scored and
+// filtered by. Candidates are original posts (not retweets) by other
+// users, newest first; blocks and mutes are left to the ranker's filters.
+type RankingStore struct {
+	db DBTX
+}
+
+func NewRankingStore(db DBTX) *RankingStore {
+	return &RankingStore{db: db}
+}
+
+// FollowedPosts returns posts by the users a user follows
+func (s *RankingStore) FollowedPosts(userID string, since int64, limit int) ([]PostWithAuthor, error) {
+	return s.candidates(`
+		p.author_id IN (SELECT followee_id FROM follows WHERE follower_id = ?)
+	`, []interface{}{userID}, userID, since, limit)
+}
+
+// EngagedPosts returns posts the users a user follows have liked or
+// retweeted
+func (s *RankingStore) EngagedPosts(userID string, since int64, limit int) ([]PostWithAuthor, error) {
+	return s.candidates(`
+		p.id IN (
+			SELECT l.post_id
+			FROM likes l
+			JOIN follows f ON f.followee_id = l.user_id
+			WHERE f.follower_id = ?
+			UNION
+			SELECT r.original_post_id
+			FROM posts r
+			JOIN follows f ON f.followee_id = r.author_id
+			WHERE f.follower_id = ? AND r.is_retweet = 1
+		)
+	`, []interface{}{userID, userID}, userID, since, limit)
+}
+
+// TaggedPosts returns posts with any of the given hashtags
+func (s *RankingStore) TaggedPosts(userID string, tags []string, since int64, limit int) ([]PostWithAuthor, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	in, args := inList(tags)
+	return s.candidates(`
+		p.id IN (
+			SELECT ph.post_id
+			FROM post_hashtags ph
+			JOIN hashtags h ON ph.hashtag_id = h.id
+			WHERE h.tag IN (`+in+`)
+		)
+	`, args, userID, since, limit)
+}
+
+// NetworkPosts returns posts by users followed by the users a user
+// follows, who the user doesn't follow themselves
+func (s *RankingStore) NetworkPosts(userID string, since int64, limit int) ([]PostWithAuthor, error) {
+	return s.candidates(`
+		p.author_id IN (
+			SELECT f2.followee_id
+			FROM follows f1
+			JOIN follows f2 ON f2.follower_id = f1.followee_id
+			WHERE f1.follower_id = ?
+		)
+		AND p.author_id NOT IN (SELECT followee_id FROM follows WHERE follower_id = ?)
+	`, []interface{}{userID, userID}, userID, since, limit)
+}
+
+// candidates runs a candidate query: original posts by anyone but userID
+// since a time, matching cond
+func (s *RankingStore) candidates(cond string, condArgs []interface{}, userID string, since int64, limit int) ([]PostWithAuthor, error) {
+	query := `
+		SELECT
+			p.id,
+			p.author_id,
+			p.text,
+			p.created_at,
+			p.is_retweet,
+			p.original_post_id,
+			p.parent_post_id,
+			p.like_count,
+			p.retweet_count,
+			p.reply_count,
+			p.media_count,
+			p.quote_count,
+			p.quoted_post_id,
+			p.edited_at,
+			u.username
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		WHERE p.is_retweet = 0
+		AND p.author_id != ?
+		AND p.created_at > ?
+		AND ` + cond + `
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT ?
+	`
+	args := append([]interface{}{userID, since}, condArgs...)
+
+	rows, err := s.db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []PostWithAuthor
+	for rows.Next() {
+		var pwa PostWithAuthor
+		err := rows.Scan(
+			&pwa.Post.ID,
+			&pwa.Post.AuthorID,
+			&pwa.Post.Text,
+			&pwa.Post.CreatedAt,
+			&pwa.Post.IsRetweet,
+			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
+			&pwa.Username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, pwa)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Affinity returns, for each author a user has liked or replied to, how
+// many times they have
+func (s *RankingStore) Affinity(userID string) (map[string]int, error) {
+	query := `
+		SELECT author_id, COUNT(*)
+		FROM (
+			SELECT p.author_id
+			FROM likes l
+			JOIN posts p ON p.id = l.post_id
+			WHERE l.user_id = ?
+			UNION ALL
+			SELECT pp.author_id
+			FROM posts r
+			JOIN posts pp ON pp.id = r.parent_post_id
+			WHERE r.author_id = ?
+		)
+		WHERE author_id != ?
+		GROUP BY author_id
+	`
+
+	rows, err := s.db.Query(query, userID, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query affinity: %w", err)
+	}
+	defer rows.Close()
+
+	affinity := make(map[string]int)
+	for rows.Next() {
+		var authorID string
+		var count int
+		if err := rows.Scan(&authorID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan affinity: %w", err)
+		}
+		affinity[authorID] = count
+	}
+
+	return affinity, rows.Err()
+}
+
+// BlockedPosts returns which of the given posts are by users blocked by or
+// blocking userID
+func (s *RankingStore) BlockedPosts(userID string, postIDs []string) (map[string]bool, error) {
+	return s.matching(postIDs, blockedBetween("p.author_id"), userID, userID)
+}
+
+// MutedPosts returns which of the given posts userID has muted
+func (s *RankingStore) MutedPosts(userID string, postIDs []string) (map[string]bool, error) {
+	return s.matching(postIDs, mutedPost("p"), userID, time.Now().Unix())
+}
+
+// SeenPosts returns which of the given posts userID has been shown in their
+// ranked feed
+func (s *RankingStore) SeenPosts(userID string, postIDs []string) (map[string]bool, error) {
+	return s.matching(postIDs, `EXISTS (
+		SELECT 1 FROM feed_seen fs WHERE fs.user_id = ? AND fs.post_id = p.id
+	)`, userID)
+}
+
+// matching returns which of the given posts match cond
+func (s *RankingStore) matching(postIDs []string, cond string, condArgs ...interface{}) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(postIDs) == 0 {
+		return found, nil
+	}
+
+	in, args := inList(postIDs)
+	query := `SELECT p.id FROM posts p WHERE p.id IN (` + in + `) AND ` + cond
+
+	rows, err := s.db.Query(query, append(args, condArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan post ID: %w", err)
+		}
+		found[id] = true
+	}
+
+	return found, rows.Err()
+}
+
+// MarkSeen records that posts were shown to a user in their ranked feed,
+// and forgets posts seen before the given time
+func (s *RankingStore) MarkSeen(userID string, postIDs []string, forgetBefore int64) error {
+	return withTx(s.db, func(tx DBTX) error {
+		_, err := tx.Exec(`DELETE FROM feed_seen WHERE user_id = ? AND seen_at < ?`, userID, forgetBefore)
+		if err != nil {
+			return fmt.Errorf("failed to forget seen posts: %w", err)
+		}
+
+		now := time.Now().Unix()
+		for _, postID := range postIDs {
+			query := `
+				INSERT INTO feed_seen (user_id, post_id, seen_at)
+				VALUES (?, ?, ?)
+				ON CONFLICT (user_id, post_id) DO UPDATE SET seen_at = excluded.seen_at
+			`
+			if _, err := tx.Exec(query, userID, postID, now); err != nil {
+				return fmt.Errorf("failed to mark post seen: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ForgetSeen clears the record of posts shown to a user in their ranked
+// feed, so they can be shown again
+func (s *RankingStore) ForgetSeen(userID string) error {
+	if _, err := s.db.Exec(`DELETE FROM feed_seen WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to forget seen posts: %w", err)
+	}
+	return nil
+}
+
+// inList returns placeholders for an IN clause over values, and the args
+// to bind to them
+func inList(values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(values)), ","), args
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/parser"
+)
+
+// Search snippets wrap matched words in these markers
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// PostSearchResult is a post matching a search, best matches first
+type PostSearchResult struct {
+	PostWithAuthor
+	Snippet string  `json:"snippet"` // Matching text with highlighted words
+	Score   float64 `json:"score"`   // BM25 relevance, higher is better
+}
+
+// MessageSearchResult is a message matching a search, best matches first
+type MessageSearchResult struct {
+	models.MessageWithUser
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// matchQuery turns what a user typed into a full-text MATCH expression.
+// "Quoted text" is matched as a phrase and a trailing * matches any word
+// starting with the prefix; every other word must appear. Words are
+// lowercased so AND, OR and NOT can't act as operators.
+func matchQuery(query string) (string, error) {
+	var terms []string
+	for _, term := range queryTerms(query) {
+		if term = matchTerm(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", ErrEmptyQuery
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+// plainQuery splits what a user typed into lowercase words and phrases
+// that must all appear in the text, for builds without the full-text index
+func plainQuery(query string) ([]string, error) {
+	var terms []string
+	for _, term := range queryTerms(query) {
+		if term = plainTerm(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	return terms, nil
+}
+
+// queryTerms splits a query into its "quoted phrases" and other words
+func queryTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			// Inside quotes: the whole phrase
+			terms = append(terms, part)
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// matchTerm quotes a word or phrase for MATCH, keeping a trailing * as a
+// prefix search. It returns "" if nothing is left to search for.
+func matchTerm(term string) string {
+	term = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(term, `"`, "")))
+	if strings.Trim(term, "*") == "" {
+		return ""
+	}
+
+	// FTS5 only reads * as a prefix search outside the quotes
+	if strings.HasSuffix(term, "*") {
+		return `"` + strings.TrimRight(term, "*") + `"*`
+	}
+	return `"` + term + `"`
+}
+
+// plainTerm lowercases a word or phrase for matching text directly. A
+// trailing * is dropped, since any substring matches anyway.
+func plainTerm(term string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(strings.ReplaceAll(term, `"`, ""))), "*")
+}
+
+// containsTerm is an SQL condition that holds when column contains the
+// plainTerm bound to ?. Builds without the full-text index search with it:
+// there's no stemming or ranking, and only ASCII letters ignore case.
+func containsTerm(column string) string {
+	return "instr(lower(" + column + "), ?) > 0"
+}
+
+// wordSeparators are the characters besides spaces that containsWords
+// reads as breaks between words, as the full-text index's tokenizer does
+const wordSeparators = ".,!?;:'\"()[]{}<>/\\|-_+=*&^%$#@~`\n\r\t…‘’“”"
+
+// wordEndings are the endings containsWords lets a word take, standing in
+// for the full-text index's stemming
+var wordEndings = []string{"", "s", "es", "ed", "ing"}
+
+// containsWords is an SQL condition that holds when column contains the
+// lowercase word or phrase words (an SQL expression) as whole words, so
+// muting "cat" hides "cat." and "cats" but not "category". Builds without
+// the full-text index match word mutes with it.
+func containsWords(column, words string) string {
+	text := "lower(" + column + ")"
+	for _, r := range wordSeparators {
+		text = fmt.Sprintf("replace(%s, char(%d), ' ')", text, r)
+	}
+
+	var endings []string
+	for _, ending := range wordEndings {
+		endings = append(endings, "instr(t, ' ' || "+words+" || '"+ending+" ') > 0")
+	}
+	return "EXISTS (SELECT 1 FROM (SELECT ' ' || " + text + " || ' ' AS t) WHERE " +
+		strings.Join(endings, " OR ") + ")"
+}
+
+// postFilter is a parsed search query compiled to SQL. Posts are aliased
+// p and their authors u.
+type postFilter struct {
+	match string   // MATCH expression for the query's words; "" if none
+	where []string // Conditions the post must meet
+	args  []interface{}
+}
+
+// compilePostSearch turns a parsed query into SQL conditions on posts,
+// post_hashtags, mentions, media and likes
+func compilePostSearch(q *parser.Query) (*postFilter, error) {
+	f := &postFilter{}
+	var matchTerms []string
+
+	for _, c := range q.Clauses {
+		var cond string
+		var args []interface{}
+
+		switch c.Kind {
+		case parser.ClauseWord, parser.ClausePhrase:
+			term := matchTerm(c.Value)
+			if term == "" {
+				continue
+			}
+			if !db.FullTextSearch {
+				cond = containsTerm("p.text")
+				args = []interface{}{plainTerm(c.Value)}
+				break
+			}
+			if !c.Negate {
+				matchTerms = append(matchTerms, term)
+				continue
+			}
+			// Full-text MATCH can't exclude on its own, so look the
+			// excluded posts up separately
+			cond = `p.id IN (SELECT post_id FROM posts_fts WHERE posts_fts MATCH ?)`
+			args = []interface{}{term}
+
+		case parser.ClauseFrom:
+			cond = `u.username = ?`
+			args = []interface{}{c.Value}
+
+		case parser.ClauseTo:
+			cond = `p.parent_post_id IN (
+				SELECT tp.id FROM posts tp JOIN users tu ON tp.author_id = tu.id WHERE tu.username = ?
+			)`
+			args = []interface{}{c.Value}
+
+		case parser.ClauseHashtag:
+			cond = `EXISTS (
+				SELECT 1 FROM post_hashtags ph JOIN hashtags h ON ph.hashtag_id = h.id
+				WHERE ph.post_id = p.id AND h.tag = ?
+			)`
+			args = []interface{}{c.Value}
+
+		case parser.ClauseMention:
+			cond = `EXISTS (
+				SELECT 1 FROM mentions m JOIN users mu ON m.mentioned_user_id = mu.id
+				WHERE m.post_id = p.id AND mu.username = ?
+			)`
+			args = []interface{}{c.Value}
+
+		case parser.ClauseSince:
+			cond = `p.created_at >= ?`
+			args = []interface{}{c.Time.Unix()}
+
+		case parser.ClauseUntil:
+			cond = `p.created_at < ?`
+			args = []interface{}{c.Time.Unix()}
+
+		case parser.ClauseHas:
+			cond = `EXISTS (SELECT 1 FROM media md WHERE md.post_id = p.id)`
+
+		case parser.ClauseIs:
+			if c.Value == "retweet" {
+				cond = `p.is_retweet = 1`
+			} else {
+				cond = `p.parent_post_id IS NOT NULL`
+			}
+
+		case parser.ClauseMinLikes:
+			cond = `p.like_count >= ?`
+			args = []interface{}{c.Count}
+		}
+
+		if c.Negate {
+			cond = "NOT (" + cond + ")"
+		}
+		f.where = append(f.where, cond)
+		f.args = append(f.args, args...)
+	}
+
+	if len(matchTerms) == 0 && len(f.where) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	f.match = strings.Join(matchTerms, " ")
+	return f, nil
+}
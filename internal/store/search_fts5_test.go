@@ -0,0 +1,143 @@
+//go:build sqlite_fts5 || fts5
+
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+)
+
+func TestSearch(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	users := NewUserStore(database)
+	alice, _ := users.Create("alice")
+	bob, _ := users.Create("bob")
+	carol, _ := users.Create("carol")
+
+	posts := NewPostStore(database)
+	var ids []string
+	for _, text := range []string{
+		"Running a golang meetup tonight",
+		"golang golang golang, all day long",
+		"Learning rust this weekend",
+		"The golang gopher is cute",
+	} {
+		post, err := posts.Create(alice.ID, text)
+		if err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		ids = append(ids, post.ID)
+	}
+
+	search := func(query string, limit, offset int) []PostSearchResult {
+		t.Helper()
+		results, err := posts.Search(query, "", limit, offset)
+		if err != nil {
+			t.Fatalf("search %q failed: %v", query, err)
+		}
+		return results
+	}
+
+	// BM25 puts the post that repeats the word first
+	results := search("golang", 10, 0)
+	if len(results) != 3 || results[0].Post.ID != ids[1] {
+		t.Fatalf("expected 3 results led by the repeated word, got %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, HighlightStart+"golang"+HighlightEnd) {
+		t.Errorf("expected a highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	// Pagination
+	if page := search("golang", 2, 2); len(page) != 1 {
+		t.Errorf("expected 1 result on the second page, got %d", len(page))
+	}
+
+	// Stemming, prefixes and phrases
+	if got := search("run", 10, 0); len(got) != 1 || got[0].Post.ID != ids[0] {
+		t.Errorf("expected stemmed match for run, got %+v", got)
+	}
+	if got := search("gop*", 10, 0); len(got) != 1 || got[0].Post.ID != ids[3] {
+		t.Errorf("expected prefix match for gop*, got %+v", got)
+	}
+	if got := search(`"golang meetup"`, 10, 0); len(got) != 1 || got[0].Post.ID != ids[0] {
+		t.Errorf("expected phrase match, got %+v", got)
+	}
+	if got := search(`"meetup golang"`, 10, 0); len(got) != 0 {
+		t.Errorf("expected no match for reversed phrase, got %+v", got)
+	}
+
+	// Operators are treated as words
+	if got := search("golang OR rust", 10, 0); len(got) != 0 {
+		t.Errorf("expected OR to be a plain word, got %+v", got)
+	}
+
+	if _, err := posts.Search("   ", "", 10, 0); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("expected ErrEmptyQuery, got %v", err)
+	}
+
+	// Triggers keep the index in sync with edits and deletes
+	if _, err := posts.Edit(ids[2], alice.ID, "Learning zig this weekend"); err != nil {
+		t.Fatalf("failed to edit post: %v", err)
+	}
+	if got := search("rust", 10, 0); len(got) != 0 {
+		t.Errorf("expected the edited-out word to leave the index, got %+v", got)
+	}
+	if got := search("zig", 10, 0); len(got) != 1 || got[0].Post.ID != ids[2] {
+		t.Errorf("expected the edited-in word to be indexed, got %+v", got)
+	}
+	if err := posts.Delete(ids[1], alice.ID); err != nil {
+		t.Fatalf("failed to delete post: %v", err)
+	}
+	if got := search("golang", 10, 0); len(got) != 2 {
+		t.Errorf("expected deleted post to leave the index, got %d results", len(got))
+	}
+
+	// posts has no INTEGER PRIMARY KEY, so VACUUM may renumber its rowids.
+	// The index is keyed by rowids of its own, so edits still land.
+	if _, err := database.Exec(`UPDATE posts SET rowid = rowid + 1000`); err != nil {
+		t.Fatalf("failed to renumber posts: %v", err)
+	}
+	if _, err := posts.Edit(ids[3], alice.ID, "The zig gopher is cute"); err != nil {
+		t.Fatalf("failed to edit post: %v", err)
+	}
+	if got := search("golang", 10, 0); len(got) != 1 || got[0].Post.ID != ids[0] {
+		t.Errorf("expected only the untouched golang post after renumbering, got %+v", got)
+	}
+	if got := search("zig", 10, 0); len(got) != 2 {
+		t.Errorf("expected both zig posts indexed after renumbering, got %+v", got)
+	}
+
+	// Message search only covers the user's own conversations
+	messages := NewMessageStore(database)
+	messages.Send(alice.ID, bob.ID, "lunch at the golang meetup?")
+	messages.Send(bob.ID, carol.ID, "golang meetup is full")
+
+	found, err := messages.SearchMessages(alice.ID, "meetup", 10, 0)
+	if err != nil {
+		t.Fatalf("failed to search messages: %v", err)
+	}
+	if len(found) != 1 || found[0].Message.SenderID != alice.ID {
+		t.Errorf("expected only alice's message, got %+v", found)
+	}
+
+	// Rebuilding indexes everything that's left
+	indexedPosts, indexedMessages, err := db.RebuildSearchIndex(database)
+	if err != nil {
+		t.Fatalf("failed to rebuild index: %v", err)
+	}
+	if indexedPosts != 3 || indexedMessages != 2 {
+		t.Errorf("expected 3 posts and 2 messages indexed, got %d and %d", indexedPosts, indexedMessages)
+	}
+	if got := search("golang", 10, 0); len(got) != 1 {
+		t.Errorf("expected 1 result after rebuild, got %d", len(got))
+	}
+}
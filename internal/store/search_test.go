@@ -0,0 +1,78 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+)
+
+func TestSearch_Operators(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	users := NewUserStore(database)
+	alice, _ := users.Create("alice")
+	bob, _ := users.Create("bob")
+	carol, _ := users.Create("carol")
+
+	posts := NewPostStore(database)
+	hashtags := NewHashtagStore(database)
+	mentions := NewMentionStore(database)
+	social := NewSocialStore(database)
+
+	golang, _ := posts.Create(alice.ID, "Loving #golang today")
+	hashtags.LinkPostToHashtags(golang.ID, []string{"golang"})
+	social.Like(bob.ID, golang.ID)
+	social.Like(carol.ID, golang.ID)
+
+	reply, _ := posts.CreateReply(bob.ID, "@alice golang is great", golang.ID)
+	mentions.CreateMentions(reply.ID, []string{alice.ID})
+
+	rust, _ := posts.Create(carol.ID, "rust is great too")
+	retweet, _ := posts.Retweet(bob.ID, rust.ID)
+
+	// Backdate one post to test dates
+	old := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local).Unix()
+	database.Exec(`UPDATE posts SET created_at = ? WHERE id = ?`, old, rust.ID)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"from:alice", []string{golang.ID}},
+		{"great -from:bob", []string{rust.ID}},
+		{"to:alice", []string{reply.ID}},
+		{"#golang", []string{golang.ID}},
+		{"@alice", []string{reply.ID}},
+		{"golang is:reply", []string{reply.ID}},
+		{"golang -is:reply", []string{golang.ID}},
+		{"is:retweet", []string{retweet.ID}},
+		{"min_likes:2", []string{golang.ID}},
+		{"great until:2026-01-01", []string{rust.ID}},
+		{"great since:2026-01-01", []string{reply.ID}},
+		{`"is great" -rust`, []string{reply.ID}},
+		{"has:image", nil},
+	}
+
+	for _, tc := range cases {
+		results, err := posts.Search(tc.query, "", 10, 0)
+		if err != nil {
+			t.Errorf("%q: %v", tc.query, err)
+			continue
+		}
+
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Post.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%q: expected %v, got %v", tc.query, tc.want, got)
+		}
+	}
+}
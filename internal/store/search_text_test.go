@@ -0,0 +1,124 @@
+//go:build !(sqlite_fts5 || fts5)
+
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+)
+
+func TestSearch_WithoutIndex(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	users := NewUserStore(database)
+	alice, _ := users.Create("alice")
+	bob, _ := users.Create("bob")
+	carol, _ := users.Create("carol")
+
+	posts := NewPostStore(database)
+	var ids []string
+	for _, text := range []string{
+		"Running a Golang meetup tonight",
+		"Learning rust this weekend",
+		"The golang gopher is cute",
+	} {
+		post, err := posts.Create(alice.ID, text)
+		if err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+		ids = append(ids, post.ID)
+	}
+
+	search := func(query string) []PostSearchResult {
+		t.Helper()
+		results, err := posts.Search(query, "", 10, 0)
+		if err != nil {
+			t.Fatalf("search %q failed: %v", query, err)
+		}
+		return results
+	}
+
+	// Words match anywhere in the text, ignoring case, newest first
+	if got := search("golang"); len(got) != 2 || got[0].Post.ID != ids[2] || got[0].Snippet != got[0].Post.Text {
+		t.Errorf("expected both golang posts, newest first, got %+v", got)
+	}
+	if got := search(`"golang meetup" run*`); len(got) != 1 || got[0].Post.ID != ids[0] {
+		t.Errorf("expected phrase and prefix match, got %+v", got)
+	}
+	if got := search("golang -gopher"); len(got) != 1 || got[0].Post.ID != ids[0] {
+		t.Errorf("expected the excluded word to be left out, got %+v", got)
+	}
+
+	if _, err := posts.Search("   ", "", 10, 0); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("expected ErrEmptyQuery, got %v", err)
+	}
+
+	messages := NewMessageStore(database)
+	messages.Send(alice.ID, bob.ID, "lunch at the golang meetup?")
+	messages.Send(bob.ID, carol.ID, "golang meetup is full")
+
+	found, err := messages.SearchMessages(alice.ID, "Meetup", 10, 0)
+	if err != nil {
+		t.Fatalf("failed to search messages: %v", err)
+	}
+	if len(found) != 1 || found[0].Message.SenderID != alice.ID {
+		t.Errorf("expected only alice's message, got %+v", found)
+	}
+
+	if _, _, err := db.RebuildSearchIndex(database); !errors.Is(err, db.ErrNoFullTextSearch) {
+		t.Errorf("expected ErrNoFullTextSearch, got %v", err)
+	}
+}
+
+func TestMutedWord_WithoutIndex(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+
+	for _, text := range []string{
+		"news: my cat. again",
+		"news: CAT pictures",
+		"news: two cats",
+		"news: category theory",
+		"news: concatenate strings",
+		"news: the season finale!",
+		"news: seasonal finales",
+	} {
+		if _, err := stores.Posts.Create(alice.ID, text); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+	}
+	stores.Mutes.Mute(bob.ID, "word", "cat", nil)
+	stores.Mutes.Mute(bob.ID, "word", "season finale", nil)
+
+	// Word mutes hide whole words, as the full-text index matches them,
+	// not every post the word appears inside
+	results, err := stores.Posts.Search("news", bob.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Post.Text)
+	}
+	sort.Strings(got)
+	want := "news: category theory,news: concatenate strings,news: seasonal finales"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/auth"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+type SessionStore struct {
+	db DBTX
+}
+
+func NewSessionStore(db DBTX) *SessionStore {
+	return &SessionStore{db: db}
+}
+
+// Create starts a new session for a user and returns its token. The token
+// itself is never stored, only its hash.
+func (s *SessionStore) Create(userID string, ttl time.Duration) (string, *models.Session, error) {
+	token, err := auth.NewToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		UserID:    userID,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	// Drop this user's expired sessions while we're here
+	if _, err := s.db.Exec(`DELETE FROM sessions WHERE user_id = ? AND expires_at <= ?`, userID, session.CreatedAt); err != nil {
+		return "", nil, fmt.Errorf("failed to clean up sessions: %w", err)
+	}
+
+	query := `
+		INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	if _, err := s.db.Exec(query, auth.HashToken(token), userID, session.CreatedAt, session.ExpiresAt); err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return token, session, nil
+}
+
+// GetUser returns the user a session token belongs to
+func (s *SessionStore) GetUser(token string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.username, u.created_at, s.expires_at
+		FROM sessions s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.token_hash = ?
+	`
+
+	var user models.User
+	var expiresAt int64
+	err := s.db.QueryRow(query, auth.HashToken(token)).Scan(
+		&user.ID,
+		&user.Username,
+		&user.CreatedAt,
+		&expiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if expiresAt <= time.Now().Unix() {
+		return nil, ErrSessionExpired
+	}
+
+	return &user, nil
+}
+
+// Delete ends a single session
+func (s *SessionStore) Delete(token string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE token_hash = ?`, auth.HashToken(token))
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteAllForUser ends every session of a user and returns how many there were
+func (s *SessionStore) DeleteAllForUser(userID string) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteOthers ends every session of a user except the one for keepToken
+func (s *SessionStore) DeleteOthers(userID, keepToken string) (int64, error) {
+	result, err := s.db.Exec(
+		`DELETE FROM sessions WHERE user_id = ? AND token_hash != ?`,
+		userID, auth.HashToken(keepToken),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionStore_Lifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	schema := `
+		CREATE TABLE sessions (
+			token_hash TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL
+		);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create sessions table: %v", err)
+	}
+
+	user, err := NewUserStore(db).Create("alice")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	sessions := NewSessionStore(db)
+
+	token, _, err := sessions.Create(user.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	got, err := sessions.GetUser(token)
+	if err != nil {
+		t.Fatalf("failed to get session user: %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("expected user %s, got %s", user.ID, got.ID)
+	}
+
+	expired, _, err := sessions.Create(user.ID, -time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	if _, err := sessions.GetUser(expired); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("expected ErrSessionExpired, got %v", err)
+	}
+
+	count, err := sessions.DeleteAllForUser(user.ID)
+	if err != nil {
+		t.Fatalf("failed to delete sessions: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 sessions deleted, got %d", count)
+	}
+	if _, err := sessions.GetUser(token); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound after logout, got %v", err)
+	}
+}
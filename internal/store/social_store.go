@@ -2,7 +2,6 @@ package store
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 
@@ -10,18 +9,27 @@ import (
 )
 
 type SocialStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewSocialStore(db *sql.DB) *SocialStore {
+func NewSocialStore(db DBTX) *SocialStore {
 	return &SocialStore{db: db}
 }
 
-// Follow creates a follow relationship
+// Follow creates a follow relationship, unless either user has blocked
+// the other
 func (s *SocialStore) Follow(followerID, followeeID string) error {
 	// Check if trying to follow yourself
 	if followerID == followeeID {
-		return errors.New("cannot follow yourself")
+		return ErrFollowSelf
+	}
+
+	blocked, err := eitherBlocked(s.db, followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
 	}
 
 	// Check if already following
@@ -30,45 +38,68 @@ func (s *SocialStore) Follow(followerID, followeeID string) error {
 		return err
 	}
 	if exists {
-		return errors.New("already following this user")
+		return ErrAlreadyFollowing
 	}
 
-	now := time.Now().Unix()
-	query := `
-		INSERT INTO follows (follower_id, followee_id, created_at)
-		VALUES (?, ?, ?)
-	`
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO follows (follower_id, followee_id, created_at)
+			VALUES (?, ?, ?)
+		`
 
-	_, err = s.db.Exec(query, followerID, followeeID, now)
-	if err != nil {
-		return fmt.Errorf("failed to follow: %w", err)
-	}
+		if _, err := tx.Exec(query, followerID, followeeID, time.Now().Unix()); err != nil {
+			return fmt.Errorf("failed to follow: %w", err)
+		}
 
-	return nil
+		return bumpFollowCounts(tx, followerID, followeeID, 1)
+	})
 }
 
 // Unfollow removes a follow relationship
 func (s *SocialStore) Unfollow(followerID, followeeID string) error {
+	return withTx(s.db, func(tx DBTX) error {
+		removed, err := removeFollow(tx, followerID, followeeID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrNotFollowing
+		}
+		return nil
+	})
+}
+
+// removeFollow deletes a follow, if there is one, and updates both users'
+// counts. It reports whether there was one.
+func removeFollow(tx DBTX, followerID, followeeID string) (bool, error) {
 	query := `
 		DELETE FROM follows
 		WHERE follower_id = ? AND followee_id = ?
 	`
 
-	result, err := s.db.Exec(query, followerID, followeeID)
+	result, err := tx.Exec(query, followerID, followeeID)
 	if err != nil {
-		return fmt.Errorf("failed to unfollow: %w", err)
+		return false, fmt.Errorf("failed to unfollow: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("not following this user")
+		return false, nil
 	}
 
-	return nil
+	return true, bumpFollowCounts(tx, followerID, followeeID, -1)
+}
+
+// bumpFollowCounts updates the counts of a follow being added or removed
+func bumpFollowCounts(tx DBTX, followerID, followeeID string, delta int) error {
+	if err := bump(tx, "users", "following_count", followerID, delta); err != nil {
+		return err
+	}
+	return bump(tx, "users", "follower_count", followeeID, delta)
 }
 
 // IsFollowing checks if follower follows followee
@@ -87,74 +118,88 @@ func (s *SocialStore) IsFollowing(followerID, followeeID string) (bool, error) {
 	return count > 0, nil
 }
 
-// GetFollowing returns list of users that the given user follows
-func (s *SocialStore) GetFollowing(userID string) ([]models.User, error) {
+// Connection is a user in a list of follows, likes or blocks, with when
+// they followed, liked or were blocked
+type Connection struct {
+	models.User
+	Since int64 `json:"since"`
+}
+
+// Cursor returns the connection's position in its list
+func (c Connection) Cursor() models.Cursor {
+	return models.Cursor{CreatedAt: c.Since, ID: c.ID}
+}
+
+// GetFollowing returns a page of the users the given user follows, most
+// recently followed first
+func (s *SocialStore) GetFollowing(userID string, page Page) ([]Connection, error) {
 	query := `
-		SELECT u.id, u.username, u.created_at
+		SELECT u.id, u.username, u.created_at, f.created_at
 		FROM users u
 		JOIN follows f ON u.id = f.followee_id
 		WHERE f.follower_id = ?
-		ORDER BY u.username
 	`
 
-	rows, err := s.db.Query(query, userID)
+	users, err := queryConnections(s.db, query, "f.created_at", userID, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get following: %w", err)
 	}
-	defer rows.Close()
-
-	var users []models.User
-	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
-		}
-		users = append(users, user)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating users: %w", err)
-	}
 
 	return users, nil
 }
 
-// GetFollowers returns list of users that follow the given user
-func (s *SocialStore) GetFollowers(userID string) ([]models.User, error) {
+// GetFollowers returns a page of the users who follow the given user, most
+// recent followers first
+func (s *SocialStore) GetFollowers(userID string, page Page) ([]Connection, error) {
 	query := `
-		SELECT u.id, u.username, u.created_at
+		SELECT u.id, u.username, u.created_at, f.created_at
 		FROM users u
 		JOIN follows f ON u.id = f.follower_id
 		WHERE f.followee_id = ?
-		ORDER BY u.username
 	`
 
-	rows, err := s.db.Query(query, userID)
+	users, err := queryConnections(s.db, query, "f.created_at", userID, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get followers: %w", err)
 	}
+
+	return users, nil
+}
+
+// queryConnections pages through query, which selects a user and the time
+// of the connection (in the since column) for the single argument arg
+func queryConnections(q DBTX, query, since, arg string, page Page) ([]Connection, error) {
+	keyset, keysetArgs := page.keyset(since, "u.id")
+	orderBy, limit := page.orderBy(since, "u.id")
+
+	query += " AND " + keyset + orderBy
+	args := append([]interface{}{arg}, keysetArgs...)
+
+	rows, err := q.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var users []models.User
+	var users []Connection
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt)
+		var c Connection
+		err := rows.Scan(&c.ID, &c.Username, &c.CreatedAt, &c.Since)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		users = append(users, user)
+		users = append(users, c)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating users: %w", err)
 	}
 
-	return users, nil
+	return order(page, users), nil
 }
 
-// GetFollowingByUsername returns list of users that the given username follows
-func (s *SocialStore) GetFollowingByUsername(username string) ([]models.User, error) {
+// GetFollowingByUsername returns a page of the users the given username follows
+func (s *SocialStore) GetFollowingByUsername(username string, page Page) ([]Connection, error) {
 	// First get the user ID
 	userStore := NewUserStore(s.db)
 	user, err := userStore.GetByUsername(username)
@@ -162,11 +207,11 @@ func (s *SocialStore) GetFollowingByUsername(username string) ([]models.User, er
 		return nil, err
 	}
 
-	return s.GetFollowing(user.ID)
+	return s.GetFollowing(user.ID, page)
 }
 
-// GetFollowersByUsername returns list of users that follow the given username
-func (s *SocialStore) GetFollowersByUsername(username string) ([]models.User, error) {
+// GetFollowersByUsername returns a page of the users who follow the given username
+func (s *SocialStore) GetFollowersByUsername(username string, page Page) ([]Connection, error) {
 	// First get the user ID
 	userStore := NewUserStore(s.db)
 	user, err := userStore.GetByUsername(username)
@@ -174,23 +219,17 @@ func (s *SocialStore) GetFollowersByUsername(username string) ([]models.User, er
 		return nil, err
 	}
 
-	return s.GetFollowers(user.ID)
+	return s.GetFollowers(user.ID, page)
 }
 
-// GetFollowCounts returns the number of following and followers for a user
+// GetFollowCounts returns the number of following and followers for a
+// user, or zeros if there's no such user
 func (s *SocialStore) GetFollowCounts(userID string) (following int, followers int, err error) {
-	// Get following count
-	query := `SELECT COUNT(*) FROM follows WHERE follower_id = ?`
-	err = s.db.QueryRow(query, userID).Scan(&following)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get following count: %w", err)
-	}
+	query := `SELECT following_count, follower_count FROM users WHERE id = ?`
 
-	// Get followers count
-	query = `SELECT COUNT(*) FROM follows WHERE followee_id = ?`
-	err = s.db.QueryRow(query, userID).Scan(&followers)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get followers count: %w", err)
+	err = s.db.QueryRow(query, userID).Scan(&following, &followers)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("failed to get follow counts: %w", err)
 	}
 
 	return following, followers, nil
@@ -198,69 +237,76 @@ func (s *SocialStore) GetFollowCounts(userID string) (following int, followers i
 
 // Like adds a like to a post
 func (s *SocialStore) Like(userID, postID string) error {
+	// Check the post's author hasn't blocked the user, or been blocked
+	var authorID string
+	err := s.db.QueryRow(`SELECT author_id FROM posts WHERE id = ?`, postID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get post: %w", err)
+	}
+
+	blocked, err := eitherBlocked(s.db, userID, authorID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+
 	// Check if already liked
 	exists, err := s.HasLiked(userID, postID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return errors.New("already liked this post")
+		return ErrAlreadyLiked
 	}
 
-	now := time.Now().Unix()
-	query := `
-		INSERT INTO likes (user_id, post_id, created_at)
-		VALUES (?, ?, ?)
-	`
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			INSERT INTO likes (user_id, post_id, created_at)
+			VALUES (?, ?, ?)
+		`
 
-	_, err = s.db.Exec(query, userID, postID, now)
-	if err != nil {
-		// Check if post exists
-		if err.Error() == "FOREIGN KEY constraint failed" {
-			return errors.New("post not found")
+		_, err := tx.Exec(query, userID, postID, time.Now().Unix())
+		if err != nil {
+			// Check if post exists
+			if err.Error() == "FOREIGN KEY constraint failed" {
+				return ErrPostNotFound
+			}
+			return fmt.Errorf("failed to like post: %w", err)
 		}
-		return fmt.Errorf("failed to like post: %w", err)
-	}
-
-	// Create a notification for the post author
-	postStore := NewPostStore(s.db)
-	post, err := postStore.GetByID(postID)
-	if err != nil {
-		return fmt.Errorf("failed to get post for notification: %w", err)
-	}
-
-	notificationStore := NewNotificationStore(s.db)
-	err = notificationStore.Create(post.AuthorID, userID, "like", &postID)
-	if err != nil {
-		// Log the error but don't block the like action
-		fmt.Printf("Warning: failed to create notification: %v\n", err)
-	}
 
-	return nil
+		return bump(tx, "posts", "like_count", postID, 1)
+	})
 }
 
 // Unlike removes a like from a post
 func (s *SocialStore) Unlike(userID, postID string) error {
-	query := `
-		DELETE FROM likes
-		WHERE user_id = ? AND post_id = ?
-	`
+	return withTx(s.db, func(tx DBTX) error {
+		query := `
+			DELETE FROM likes
+			WHERE user_id = ? AND post_id = ?
+		`
 
-	result, err := s.db.Exec(query, userID, postID)
-	if err != nil {
-		return fmt.Errorf("failed to unlike post: %w", err)
-	}
+		result, err := tx.Exec(query, userID, postID)
+		if err != nil {
+			return fmt.Errorf("failed to unlike post: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		return errors.New("post not liked")
-	}
+		if rowsAffected == 0 {
+			return ErrNotLiked
+		}
 
-	return nil
+		return bump(tx, "posts", "like_count", postID, -1)
+	})
 }
 
 // HasLiked checks if a user has liked a post
@@ -279,46 +325,29 @@ func (s *SocialStore) HasLiked(userID, postID string) (bool, error) {
 	return count > 0, nil
 }
 
-// GetLikes returns list of users who liked a post
-func (s *SocialStore) GetLikes(postID string) ([]models.User, error) {
+// GetLikes returns a page of the users who liked a post, most recent first
+func (s *SocialStore) GetLikes(postID string, page Page) ([]Connection, error) {
 	query := `
-		SELECT u.id, u.username, u.created_at
+		SELECT u.id, u.username, u.created_at, l.created_at
 		FROM users u
 		JOIN likes l ON u.id = l.user_id
 		WHERE l.post_id = ?
-		ORDER BY l.created_at DESC
 	`
 
-	rows, err := s.db.Query(query, postID)
+	users, err := queryConnections(s.db, query, "l.created_at", postID, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get likes: %w", err)
 	}
-	defer rows.Close()
-
-	var users []models.User
-	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan user: %w", err)
-		}
-		users = append(users, user)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating users: %w", err)
-	}
 
 	return users, nil
 }
 
-// GetLikeCount returns the number of likes for a post
+// GetLikeCount returns the number of likes for a post, or 0 if there's no
+// such post
 func (s *SocialStore) GetLikeCount(postID string) (int, error) {
-	query := `SELECT COUNT(*) FROM likes WHERE post_id = ?`
-
 	var count int
-	err := s.db.QueryRow(query, postID).Scan(&count)
-	if err != nil {
+	err := s.db.QueryRow(`SELECT like_count FROM posts WHERE id = ?`, postID).Scan(&count)
+	if err != nil && err != sql.ErrNoRows {
 		return 0, fmt.Errorf("failed to get like count: %w", err)
 	}
 
@@ -0,0 +1,278 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+)
+
+// TimelineStore keeps materialized home timelines for fan-out-on-write:
+// each post is copied into its readers' timelines when it's written, so
+// reading a feed is a range scan of one user's rows
+type TimelineStore struct {
+	db DBTX
+}
+
+func NewTimelineStore(db DBTX) *TimelineStore {
+	return &TimelineStore{db: db}
+}
+
+// Add puts a post in one user's timeline
+func (s *TimelineStore) Add(userID string, post *models.Post) error {
+	query := `
+		INSERT OR IGNORE INTO timelines (user_id, post_id, author_id, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := s.db.Exec(query, userID, post.ID, post.AuthorID, post.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add post to timeline: %w", err)
+	}
+
+	return nil
+}
+
+// FanOut puts a post in its author's timeline and the timelines of all
+// their followers
+func (s *TimelineStore) FanOut(post *models.Post) error {
+	query := `
+		INSERT OR IGNORE INTO timelines (user_id, post_id, author_id, created_at)
+		SELECT ?, ?, ?, ?
+		UNION ALL
+		SELECT follower_id, ?, ?, ?
+		FROM follows
+		WHERE followee_id = ?
+	`
+
+	_, err := s.db.Exec(query,
+		post.AuthorID, post.ID, post.AuthorID, post.CreatedAt,
+		post.ID, post.AuthorID, post.CreatedAt, post.AuthorID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fan out post: %w", err)
+	}
+
+	return nil
+}
+
+// Backfill copies an author's latest posts, up to limit, into a user's
+// timeline, as when the user starts following them
+func (s *TimelineStore) Backfill(userID, authorID string, limit int) error {
+	query := `
+		INSERT OR IGNORE INTO timelines (user_id, post_id, author_id, created_at)
+		SELECT ?, id, author_id, created_at
+		FROM posts
+		WHERE author_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`
+
+	_, err := s.db.Exec(query, userID, authorID, limit)
+	if err != nil {
+		return fmt.Errorf("failed to backfill timeline: %w", err)
+	}
+
+	return nil
+}
+
+// BackfillFollowers copies an author's latest posts, up to limit, into the
+// timelines of all their followers
+func (s *TimelineStore) BackfillFollowers(authorID string, limit int) error {
+	query := `
+		INSERT OR IGNORE INTO timelines (user_id, post_id, author_id, created_at)
+		SELECT f.follower_id, p.id, p.author_id, p.created_at
+		FROM follows f
+		JOIN (
+			SELECT id, author_id, created_at
+			FROM posts
+			WHERE author_id = ?
+			ORDER BY created_at DESC, id DESC
+			LIMIT ?
+		) p
+		WHERE f.followee_id = ?
+	`
+
+	_, err := s.db.Exec(query, authorID, limit, authorID)
+	if err != nil {
+		return fmt.Errorf("failed to backfill timelines: %w", err)
+	}
+
+	return nil
+}
+
+// Remove takes an author's posts out of a user's timeline
+func (s *TimelineStore) Remove(userID, authorID string) error {
+	query := `DELETE FROM timelines WHERE user_id = ? AND author_id = ?`
+
+	if _, err := s.db.Exec(query, userID, authorID); err != nil {
+		return fmt.Errorf("failed to remove posts from timeline: %w", err)
+	}
+
+	return nil
+}
+
+// Rebuild refills every timeline from scratch: each user's own posts and
+// those of the users they follow, up to limit per author. Authors with more
+// than pullOver followers only get their own timeline filled.
+func (s *TimelineStore) Rebuild(limit, pullOver int) error {
+	return withTx(s.db, func(tx DBTX) error {
+		if _, err := tx.Exec(`DELETE FROM timelines`); err != nil {
+			return fmt.Errorf("failed to clear timelines: %w", err)
+		}
+
+		query := `
+			INSERT OR IGNORE INTO timelines (user_id, post_id, author_id, created_at)
+			SELECT r.user_id, p.id, p.author_id, p.created_at
+			FROM (
+				SELECT id AS user_id, id AS author_id FROM users
+				UNION ALL
+				SELECT f.follower_id, f.followee_id
+				FROM follows f
+				WHERE (SELECT follower_count FROM users c WHERE c.id = f.followee_id) <= ?
+			) r
+			JOIN (
+				SELECT id, author_id, created_at,
+					ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY created_at DESC, id DESC) AS n
+				FROM posts
+			) p ON p.author_id = r.author_id
+			WHERE p.n <= ?
+		`
+
+		if _, err := tx.Exec(query, pullOver, limit); err != nil {
+			return fmt.Errorf("failed to rebuild timelines: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetTimeline returns a page of a user's materialized timeline, leaving
+// out users blocked either way and anything the user has muted. It's read
+// in index order, so only as many rows as the page needs are looked at.
+func (s *TimelineStore) GetTimeline(userID string, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("t.created_at", "t.post_id")
+	orderBy, limit := page.orderBy("t.created_at", "t.post_id")
+
+	query := `
+		SELECT
+			p.id,
+			p.author_id,
+			p.text,
+			p.created_at,
+			p.is_retweet,
+			p.original_post_id,
+			p.parent_post_id,
+			p.like_count,
+			p.retweet_count,
+			p.reply_count,
+			p.media_count,
+			p.quote_count,
+			p.quoted_post_id,
+			p.edited_at,
+			u.username
+		FROM timelines t
+		JOIN posts p ON p.id = t.post_id
+		JOIN users u ON p.author_id = u.id
+		LEFT JOIN posts op ON p.original_post_id = op.id
+		WHERE t.user_id = ?
+		AND NOT ` + blockedBetween("p.author_id") + `
+		AND NOT ` + blockedBetween("op.author_id") + `
+		AND NOT ` + mutedPost("p") + `
+		AND NOT ` + retweetedLater("p") + `
+		AND ` + keyset + orderBy
+	now := time.Now().Unix()
+	args := []interface{}{userID, userID, userID, userID, userID, userID, now, userID, userID, userID, now}
+	args = append(args, keysetArgs...)
+
+	return s.queryPosts(page, query, append(args, limit)...)
+}
+
+// GetPulled returns a page of posts by the users a user follows who have
+// more than pullOver followers. Their posts aren't fanned out, so they're
+// merged into the timeline when it's read.
+func (s *TimelineStore) GetPulled(userID string, pullOver int, page Page) ([]PostWithAuthor, error) {
+	keyset, keysetArgs := page.keyset("p.created_at", "p.id")
+	orderBy, limit := page.orderBy("p.created_at", "p.id")
+
+	query := `
+		SELECT
+			p.id,
+			p.author_id,
+			p.text,
+			p.created_at,
+			p.is_retweet,
+			p.original_post_id,
+			p.parent_post_id,
+			p.like_count,
+			p.retweet_count,
+			p.reply_count,
+			p.media_count,
+			p.quote_count,
+			p.quoted_post_id,
+			p.edited_at,
+			u.username
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		LEFT JOIN posts op ON p.original_post_id = op.id
+		WHERE p.author_id IN (
+			SELECT f.followee_id
+			FROM follows f
+			WHERE f.follower_id = ?
+			AND (SELECT follower_count FROM users c WHERE c.id = f.followee_id) > ?
+		)
+		AND NOT ` + blockedBetween("p.author_id") + `
+		AND NOT ` + blockedBetween("op.author_id") + `
+		AND NOT ` + mutedPost("p") + `
+		AND NOT ` + retweetedLater("p") + `
+		AND ` + keyset + orderBy
+	now := time.Now().Unix()
+	args := []interface{}{userID, pullOver, userID, userID, userID, userID, userID, now, userID, userID, userID, now}
+	args = append(args, keysetArgs...)
+
+	return s.queryPosts(page, query, append(args, limit)...)
+}
+
+// queryPosts runs a query for a page of posts with their authors
+func (s *TimelineStore) queryPosts(page Page, query string, args ...interface{}) ([]PostWithAuthor, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []PostWithAuthor
+	for rows.Next() {
+		var pwa PostWithAuthor
+		err := rows.Scan(
+			&pwa.Post.ID,
+			&pwa.Post.AuthorID,
+			&pwa.Post.Text,
+			&pwa.Post.CreatedAt,
+			&pwa.Post.IsRetweet,
+			&pwa.Post.OriginalPostID,
+			&pwa.Post.ParentPostID,
+			&pwa.Post.LikeCount,
+			&pwa.Post.RetweetCount,
+			&pwa.Post.ReplyCount,
+			&pwa.Post.MediaCount,
+			&pwa.Post.QuoteCount,
+			&pwa.Post.QuotedPostID,
+			&pwa.Post.EditedAt,
+			&pwa.Username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, pwa)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posts: %w", err)
+	}
+
+	if err := resolve(s.db, posts); err != nil {
+		return nil, err
+	}
+	return order(page, posts), nil
+}
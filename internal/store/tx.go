@@ -0,0 +1,44 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is what stores run their queries on: the database itself, or a
+// transaction when the stores came from Stores.InTx
+type DBTX interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// withTx runs fn in a transaction. When q already is a transaction, fn joins
+// it and the outermost caller decides whether to commit.
+func withTx(q DBTX, fn func(tx DBTX) error) error {
+	db, ok := q.(*sql.DB)
+	if !ok {
+		return fn(q)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InTx runs fn with stores that share one transaction. The transaction
+// commits if fn returns nil and rolls back otherwise. Inside fn, only use
+// the stores it is given: the database is locked until fn returns.
+func (s *Stores) InTx(fn func(tx *Stores) error) error {
+	return withTx(s.db, func(tx DBTX) error {
+		return fn(newStores(tx))
+	})
+}
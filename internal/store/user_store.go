@@ -2,7 +2,6 @@ package store
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 
@@ -11,10 +10,10 @@ import (
 )
 
 type UserStore struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewUserStore(db *sql.DB) *UserStore {
+func NewUserStore(db DBTX) *UserStore {
 	return &UserStore{db: db}
 }
 
@@ -33,7 +32,7 @@ func (s *UserStore) Create(username string) (*models.User, error) {
 	if err != nil {
 		// Check for unique constraint violation
 		if err.Error() == "UNIQUE constraint failed: users.username" {
-			return nil, errors.New("username already exists")
+			return nil, ErrUsernameTaken
 		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -45,6 +44,62 @@ func (s *UserStore) Create(username string) (*models.User, error) {
 	}, nil
 }
 
+// CreateWithPassword creates a new user with a password hash from auth.HashPassword
+func (s *UserStore) CreateWithPassword(username, passwordHash string) (*models.User, error) {
+	id := ulid.Make().String()
+	now := time.Now().Unix()
+
+	query := `
+		INSERT INTO users (id, username, password_hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := s.db.Exec(query, id, username, passwordHash, now)
+	if err != nil {
+		if err.Error() == "UNIQUE constraint failed: users.username" {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return &models.User{
+		ID:        id,
+		Username:  username,
+		CreatedAt: now,
+	}, nil
+}
+
+// GetPasswordHash returns a user's password hash, empty if none was ever set
+func (s *UserStore) GetPasswordHash(userID string) (string, error) {
+	var hash string
+	err := s.db.QueryRow(`SELECT password_hash FROM users WHERE id = ?`, userID).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get password: %w", err)
+	}
+	return hash, nil
+}
+
+// SetPasswordHash replaces a user's password hash
+func (s *UserStore) SetPasswordHash(userID, passwordHash string) error {
+	result, err := s.db.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 // GetByUsername retrieves a user by username
 func (s *UserStore) GetByUsername(username string) (*models.User, error) {
 	query := `
@@ -61,7 +116,7 @@ func (s *UserStore) GetByUsername(username string) (*models.User, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -86,7 +141,7 @@ func (s *UserStore) GetByID(id string) (*models.User, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
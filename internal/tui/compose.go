@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/validation"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type composeKind int
+
+const (
+	composePost composeKind = iota
+	composeReply
+	composeMessage
+)
+
+// composer is the text box for a new post, reply or message
+type composer struct {
+	kind     composeKind
+	parentID string // post replied to
+	to       string // username replied to or messaged
+	input    textarea.Model
+}
+
+func (c *composer) title() string {
+	switch c.kind {
+	case composeReply:
+		return "Reply to @" + c.to
+	case composeMessage:
+		return "Message @" + c.to
+	}
+	return "New post"
+}
+
+func (c *composer) view(width int) string {
+	title := accent.Render(c.title())
+	if c.kind != composeMessage {
+		count := fmt.Sprintf("%d/%d", len([]rune(c.input.Value())), validation.MaxPostLength)
+		title += " " + dim.Render(count)
+	}
+	return title + "\n" + c.input.View() + "\n" + dim.Render("enter send · esc cancel")
+}
+
+func (m *Model) startCompose(kind composeKind, parentID, to string) tea.Cmd {
+	input := textarea.New()
+	input.ShowLineNumbers = false
+	input.SetHeight(3)
+	input.SetWidth(m.width - 4)
+	if kind != composeMessage {
+		input.CharLimit = validation.MaxPostLength
+	}
+
+	m.compose = &composer{kind: kind, parentID: parentID, to: to, input: input}
+	return m.compose.input.Focus()
+}
+
+func (m *Model) updateCompose(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		m.compose = nil
+		return nil
+	case "enter":
+		m.send()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.compose.input, cmd = m.compose.input.Update(msg)
+	return cmd
+}
+
+// send publishes what was composed. On failure the text is kept so it can
+// be fixed.
+func (m *Model) send() {
+	c := m.compose
+	text := strings.TrimSpace(c.input.Value())
+	if text == "" {
+		return
+	}
+
+	switch c.kind {
+	case composePost, composeReply:
+		in := service.NewPost{Text: text}
+		if c.kind == composeReply {
+			in.ParentID = &c.parentID
+		}
+		if _, err := m.services.Posts.Publish(m.user, in); m.report(err) {
+			return
+		}
+
+	case composeMessage:
+		if _, err := m.services.Messages.Send(m.user.ID, c.to, text); m.report(err) {
+			return
+		}
+	}
+
+	m.compose = nil
+
+	switch c.kind {
+	case composePost:
+		m.setStatus("Posted")
+		if m.pane == paneFeed {
+			m.report(m.loadFeed(true))
+		}
+	case composeReply:
+		m.setStatus("Replied to @" + c.to)
+		if m.pane == paneThread {
+			m.report(m.loadThread())
+		}
+	case composeMessage:
+		m.setStatus("Sent to @" + c.to)
+		if m.pane == paneChat {
+			m.report(m.loadChat())
+		} else if m.pane == paneMessages {
+			m.report(m.loadConversations())
+		}
+	}
+}
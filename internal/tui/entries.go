@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/display"
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// entry is one selectable item in a pane
+type entry interface {
+	render() string
+}
+
+// postEntry is a post in the feed or a thread, with what's needed to draw
+// it so scrolling doesn't hit the database
+type postEntry struct {
+	store.PostWithAuthor
+	mediaCount   int
+	likeCount    int
+	retweetCount int
+	liked        bool
+	retweeted    bool
+	indent       int // Levels below the post a thread is about
+}
+
+// targetID is the post that likes, retweets and replies apply to: the
+// original for a retweet
+func (e *postEntry) targetID() string {
+	if e.Post.IsRetweet && e.Post.OriginalPostID != nil {
+		return *e.Post.OriginalPostID
+	}
+	return e.Post.ID
+}
+
+func (e *postEntry) render() string {
+	heart := "♡"
+	if e.liked {
+		heart = "❤"
+	}
+	stats := fmt.Sprintf("%s %d  ↻ %d", heart, e.likeCount, e.retweetCount)
+	text := display.FormatPostWithMedia(e.PostWithAuthor, e.mediaCount) + "\n" + dim.Render(stats)
+	if e.indent == 0 {
+		return text
+	}
+
+	pad := strings.Repeat("  ", e.indent-1) + dim.Render("↳ ")
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = pad + lines[i]
+		if i == 0 {
+			pad = strings.Repeat("  ", e.indent)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type notificationEntry struct {
+	models.NotificationGroup
+}
+
+// postID is the post the notification is about, if any
+func (e *notificationEntry) postID() string {
+	switch e.Type {
+	case "like", "retweet", "reply", "mention", "quote", "poll":
+		if e.TargetID != nil {
+			return *e.TargetID
+		}
+	}
+	return ""
+}
+
+func (e *notificationEntry) render() string {
+	line := display.FormatNotificationGroup(e.NotificationGroup)
+	line += " " + dim.Render("("+display.FormatTimeAgo(e.CreatedAt)+")")
+	if !e.Read {
+		line += " 🔴"
+	}
+	return line
+}
+
+type conversationEntry struct {
+	models.Conversation
+}
+
+func (e *conversationEntry) render() string {
+	header := "@" + e.OtherUsername + " " + dim.Render(display.FormatTimeAgo(e.LastMessageAt))
+	if e.UnreadCount > 0 {
+		header += fmt.Sprintf(" 🔴 %d new", e.UnreadCount)
+	}
+	return header + "\n" + e.LastMessage
+}
+
+type messageEntry struct {
+	models.MessageWithUser
+}
+
+func (e *messageEntry) render() string {
+	header := "@" + e.SenderName + " " + dim.Render(display.FormatTimeAgo(e.Message.CreatedAt))
+	return header + "\n" + strings.TrimSpace(e.Message.Text)
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+)
+
+// newPostEntry loads what's needed to draw a post. Its counts come with
+// it; only whether the user liked and retweeted it is looked up.
+func (m *Model) newPostEntry(pwa store.PostWithAuthor) (*postEntry, error) {
+	target := pwa.Post
+	if pwa.Original != nil {
+		target = pwa.Original.Post
+	}
+
+	e := &postEntry{
+		PostWithAuthor: pwa,
+		mediaCount:     pwa.Post.MediaCount,
+		likeCount:      target.LikeCount,
+		retweetCount:   target.RetweetCount,
+	}
+
+	var err error
+	if e.liked, err = m.stores.Social.HasLiked(m.user.ID, e.targetID()); err != nil {
+		return nil, err
+	}
+	if e.retweeted, err = m.stores.Posts.HasRetweeted(m.user.ID, e.targetID()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// loadFeed loads the first page of the feed, or with reset false, the page
+// after the posts already loaded
+func (m *Model) loadFeed(reset bool) error {
+	l := m.lists[paneFeed]
+	if reset {
+		*l = list{}
+	}
+
+	page := store.Page{Limit: pageSize}
+	if n := len(l.entries); n > 0 {
+		c := l.entries[n-1].(*postEntry).Cursor()
+		page.Before = &c
+	}
+
+	posts, err := m.services.Feed.Feed(m.user.ID, page.Probe())
+	if err != nil {
+		return err
+	}
+	posts, next := store.Paginate(posts, page)
+
+	if reset && len(posts) > 0 {
+		c := posts[0].Cursor()
+		m.feedNewest = &c
+	}
+	for _, pwa := range posts {
+		e, err := m.newPostEntry(pwa)
+		if err != nil {
+			return err
+		}
+		l.entries = append(l.entries, e)
+	}
+	l.more = next != nil
+
+	if reset {
+		m.newPosts = 0
+	}
+	return nil
+}
+
+// loadNotifications loads the first page of notifications, or with reset
+// false, one page more, and marks them read. Groups change as notifications
+// arrive, so all of them are reloaded rather than paged by cursor.
+func (m *Model) loadNotifications(reset bool) error {
+	l := m.lists[paneNotifications]
+	limit := pageSize
+	if !reset {
+		limit = len(l.entries) + pageSize
+	}
+
+	page := store.Page{Limit: limit}
+	groups, err := m.stores.Notifications.GetGroupedNotifications(m.user.ID, false, page.Probe())
+	if err != nil {
+		return err
+	}
+	groups, next := store.Paginate(groups, page)
+
+	l.entries = l.entries[:0]
+	for _, g := range groups {
+		l.entries = append(l.entries, &notificationEntry{g})
+	}
+	l.more = next != nil
+	if reset {
+		l.cursor, l.top = 0, 0
+	}
+
+	if err := m.stores.Notifications.MarkAsRead(m.user.ID); err != nil {
+		return err
+	}
+	m.unreadNotifications = 0
+	return nil
+}
+
+func (m *Model) loadConversations() error {
+	conversations, err := m.stores.Messages.GetConversations(m.user.ID, store.Page{Limit: conversationLimit})
+	if err != nil {
+		return err
+	}
+
+	l := m.lists[paneMessages]
+	l.entries = l.entries[:0]
+	for _, c := range conversations {
+		l.entries = append(l.entries, &conversationEntry{c})
+	}
+	return nil
+}
+
+// loadChat loads the open conversation, marking it read, and keeps the
+// selection on the newest message unless the user scrolled up
+func (m *Model) loadChat() error {
+	if m.chat == nil {
+		return nil
+	}
+
+	messages, err := m.services.Messages.Conversation(m.user.ID, m.chat.OtherUsername, store.Page{Limit: chatLimit})
+	if err != nil {
+		return err
+	}
+
+	// Conversations come newest first; chats read oldest first
+	l := m.lists[paneChat]
+	atEnd := l.cursor >= len(l.entries)-1
+	l.entries = l.entries[:0]
+	for i := len(messages) - 1; i >= 0; i-- {
+		l.entries = append(l.entries, &messageEntry{messages[i]})
+	}
+	if atEnd {
+		l.cursor = len(l.entries) - 1
+	}
+
+	if n, err := m.stores.Messages.GetUnreadCount(m.user.ID); err == nil {
+		m.unreadMessages = n
+	}
+	return nil
+}
+
+func (m *Model) loadThread() error {
+	if m.threadID == "" {
+		return nil
+	}
+
+	thread, err := m.stores.Posts.GetThread(m.threadID, m.user.ID, store.ThreadOptions{Sort: store.ThreadSortTop})
+	if err != nil {
+		return err
+	}
+
+	l := m.lists[paneThread]
+	l.entries = l.entries[:0]
+	for _, pwa := range thread.Ancestors {
+		e, err := m.newPostEntry(pwa)
+		if err != nil {
+			return err
+		}
+		l.entries = append(l.entries, e)
+	}
+
+	var walkErr error
+	thread.Post.Walk(func(node *store.ThreadNode, depth int) {
+		if walkErr != nil {
+			return
+		}
+		e, err := m.newPostEntry(node.PostWithAuthor)
+		if err != nil {
+			walkErr = err
+			return
+		}
+		e.indent = depth
+		l.entries = append(l.entries, e)
+	})
+	return walkErr
+}
@@ -0,0 +1,525 @@
+// Package tui is the full-screen terminal client started by `twt tui`. It
+// reads from the stores and acts through the services, like the CLI
+// commands do, and draws posts and notifications with the display package.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/models"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// pageSize is how many posts or notifications are loaded at a time;
+	// moving past the last one loads the next page
+	pageSize = 20
+
+	// chatLimit is how many messages of a conversation are shown
+	chatLimit = 200
+
+	// conversationLimit is how many of the latest conversations are shown
+	conversationLimit = 100
+
+	// refreshInterval is how often unread badges are updated
+	refreshInterval = 5 * time.Second
+)
+
+type pane int
+
+const (
+	paneFeed pane = iota
+	paneNotifications
+	paneMessages
+	paneThread
+	paneChat // a conversation, opened from paneMessages
+	paneCount
+)
+
+// list is the entries of a pane and the position in them
+type list struct {
+	entries []entry
+	cursor  int
+	top     int  // first entry on screen
+	more    bool // another page may follow
+}
+
+func (l *list) selected() entry {
+	if l.cursor < 0 || l.cursor >= len(l.entries) {
+		return nil
+	}
+	return l.entries[l.cursor]
+}
+
+// statusLog collects the services' warnings so they show in the status
+// line instead of being printed over the screen
+type statusLog struct {
+	last string
+}
+
+func (l *statusLog) Printf(format string, v ...interface{}) {
+	l.last = fmt.Sprintf(format, v...)
+}
+
+type tickMsg time.Time
+
+// Model is the state of the TUI
+type Model struct {
+	stores   *store.Stores
+	services *service.Services
+	warnings *statusLog
+	user     *models.User
+
+	width, height int
+
+	pane     pane
+	lists    [paneCount]*list
+	back     pane                 // where Esc goes from a thread
+	threadID string               // root of the open thread
+	chat     *models.Conversation // open conversation
+
+	feedNewest          *models.Cursor // newest post loaded into the feed
+	newPosts            int
+	unreadNotifications int
+	unreadMessages      int
+
+	compose *composer
+
+	status    string
+	statusErr bool
+}
+
+// New creates the TUI for user and loads their feed. Services are created
+// here so their warnings go to the status line.
+func New(stores *store.Stores, user *models.User, opts service.Options) *Model {
+	warnings := &statusLog{}
+	m := &Model{
+		stores:   stores,
+		services: service.New(stores, warnings, opts),
+		warnings: warnings,
+		user:     user,
+	}
+	for p := range m.lists {
+		m.lists[p] = &list{}
+	}
+
+	m.report(m.loadFeed(true))
+	m.refreshBadges()
+
+	return m
+}
+
+// Run shows the TUI for user until they quit
+func Run(stores *store.Stores, user *models.User, opts service.Options) error {
+	_, err := tea.NewProgram(New(stores, user, opts), tea.WithAltScreen()).Run()
+	return err
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.compose != nil {
+			m.compose.input.SetWidth(m.width - 4)
+		}
+		return m, nil
+
+	case tickMsg:
+		m.refreshBadges()
+		if m.pane == paneChat {
+			m.report(m.loadChat())
+		}
+		return m, tick()
+
+	case tea.KeyMsg:
+		if m.compose != nil {
+			return m, m.updateCompose(msg)
+		}
+		return m, m.handleKey(msg)
+	}
+
+	if m.compose != nil {
+		var cmd tea.Cmd
+		m.compose.input, cmd = m.compose.input.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	m.status = ""
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return tea.Quit
+
+	case "tab":
+		m.switchTo(m.nextTab(1))
+	case "shift+tab":
+		m.switchTo(m.nextTab(-1))
+	case "1":
+		m.switchTo(paneFeed)
+	case "2":
+		m.switchTo(paneNotifications)
+	case "3":
+		m.switchTo(paneMessages)
+	case "4":
+		if m.threadID != "" {
+			m.switchTo(paneThread)
+		}
+
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-5)
+	case "pgdown":
+		m.move(5)
+	case "home", "g":
+		m.move(-len(m.current().entries))
+	case "end", "G":
+		m.move(len(m.current().entries))
+
+	case "enter":
+		m.open()
+	case "esc":
+		m.goBack()
+	case "ctrl+r", "R":
+		m.reload()
+
+	case "l":
+		m.toggleLike()
+	case "t":
+		m.toggleRetweet()
+	case "r":
+		return m.reply()
+	case "n":
+		return m.startCompose(composePost, "", "")
+	}
+
+	return nil
+}
+
+func (m *Model) current() *list {
+	return m.lists[m.pane]
+}
+
+// tabs lists the panes shown in the header, in order
+func (m *Model) tabs() []pane {
+	tabs := []pane{paneFeed, paneNotifications, paneMessages}
+	if m.threadID != "" {
+		tabs = append(tabs, paneThread)
+	}
+	return tabs
+}
+
+// tabOf is the tab a pane belongs to
+func tabOf(p pane) pane {
+	if p == paneChat {
+		return paneMessages
+	}
+	return p
+}
+
+func (m *Model) nextTab(step int) pane {
+	tabs := m.tabs()
+	for i, p := range tabs {
+		if p == tabOf(m.pane) {
+			return tabs[(i+step+len(tabs))%len(tabs)]
+		}
+	}
+	return paneFeed
+}
+
+// switchTo shows pane p, loading what it shows. Notifications and messages
+// are always reloaded, since looking at them reads them.
+func (m *Model) switchTo(p pane) {
+	m.pane = p
+
+	switch p {
+	case paneFeed:
+		if len(m.current().entries) == 0 || m.newPosts > 0 {
+			m.report(m.loadFeed(true))
+		}
+	case paneNotifications:
+		m.report(m.loadNotifications(true))
+	case paneMessages:
+		m.report(m.loadConversations())
+	case paneThread:
+		m.report(m.loadThread())
+	case paneChat:
+		m.report(m.loadChat())
+	}
+}
+
+func (m *Model) reload() {
+	switch m.pane {
+	case paneFeed:
+		m.report(m.loadFeed(true))
+	default:
+		m.switchTo(m.pane)
+	}
+	m.refreshBadges()
+}
+
+// move moves the selection by delta, loading the next page when it runs
+// past the end of a pane that has more
+func (m *Model) move(delta int) {
+	l := m.current()
+	l.cursor += delta
+
+	if l.cursor >= len(l.entries) && l.more {
+		switch m.pane {
+		case paneFeed:
+			m.report(m.loadFeed(false))
+		case paneNotifications:
+			m.report(m.loadNotifications(false))
+		}
+	}
+
+	if l.cursor >= len(l.entries) {
+		l.cursor = len(l.entries) - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+}
+
+// open opens the thread of the selected post or notification, or the
+// selected conversation
+func (m *Model) open() {
+	switch e := m.current().selected().(type) {
+	case *postEntry:
+		m.openThread(e.targetID())
+	case *notificationEntry:
+		if id := e.postID(); id != "" {
+			m.openThread(id)
+		}
+	case *conversationEntry:
+		m.chat = &e.Conversation
+		m.lists[paneChat] = &list{}
+		m.switchTo(paneChat)
+	}
+}
+
+func (m *Model) openThread(postID string) {
+	if m.pane != paneThread {
+		m.back = m.pane
+	}
+	m.threadID = postID
+	m.lists[paneThread] = &list{}
+	m.switchTo(paneThread)
+}
+
+func (m *Model) goBack() {
+	switch m.pane {
+	case paneThread:
+		m.switchTo(m.back)
+	case paneChat:
+		m.chat = nil
+		m.switchTo(paneMessages)
+	}
+}
+
+func (m *Model) toggleLike() {
+	e, ok := m.current().selected().(*postEntry)
+	if !ok {
+		return
+	}
+
+	var err error
+	if e.liked {
+		err = m.services.Social.Unlike(m.user.ID, e.targetID())
+	} else {
+		err = m.services.Social.Like(m.user.ID, e.targetID())
+	}
+	if m.report(err) {
+		return
+	}
+
+	if e.liked {
+		m.setStatus("Unliked")
+		e.likeCount--
+	} else {
+		m.setStatus("Liked ❤")
+		e.likeCount++
+	}
+	e.liked = !e.liked
+}
+
+func (m *Model) toggleRetweet() {
+	e, ok := m.current().selected().(*postEntry)
+	if !ok {
+		return
+	}
+
+	var err error
+	if e.retweeted {
+		err = m.services.Posts.Unretweet(m.user.ID, e.targetID())
+	} else {
+		_, err = m.services.Posts.Retweet(m.user.ID, e.targetID())
+	}
+	if m.report(err) {
+		return
+	}
+
+	if e.retweeted {
+		m.setStatus("Unretweeted")
+		e.retweetCount--
+	} else {
+		m.setStatus("Retweeted ↻")
+		e.retweetCount++
+	}
+	e.retweeted = !e.retweeted
+}
+
+// reply replies to the selected post, or to the other user of the selected
+// or open conversation
+func (m *Model) reply() tea.Cmd {
+	switch e := m.current().selected().(type) {
+	case *postEntry:
+		return m.startCompose(composeReply, e.targetID(), e.Username)
+	case *conversationEntry:
+		return m.startCompose(composeMessage, "", e.OtherUsername)
+	}
+
+	if m.pane == paneChat && m.chat != nil {
+		return m.startCompose(composeMessage, "", m.chat.OtherUsername)
+	}
+	return nil
+}
+
+// refreshBadges updates the unread counts and how many new posts are
+// waiting above the feed
+func (m *Model) refreshBadges() {
+	if n, err := m.stores.Notifications.GetUnreadCount(m.user.ID); err == nil {
+		m.unreadNotifications = n
+	}
+	if n, err := m.stores.Messages.GetUnreadCount(m.user.ID); err == nil {
+		m.unreadMessages = n
+	}
+	if m.feedNewest != nil {
+		page := store.Page{After: m.feedNewest, Limit: pageSize}
+		if posts, err := m.services.Feed.Feed(m.user.ID, page); err == nil {
+			m.newPosts = len(posts)
+		}
+	}
+}
+
+// report shows err in the status line and reports whether there was one.
+// Otherwise it shows the latest warning from the services, if any.
+func (m *Model) report(err error) bool {
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		m.statusErr = true
+		return true
+	}
+	if m.warnings.last != "" {
+		m.status = "Warning: " + m.warnings.last
+		m.statusErr = true
+		m.warnings.last = ""
+	}
+	return false
+}
+
+func (m *Model) setStatus(text string) {
+	m.status = text
+	m.statusErr = false
+	m.report(nil)
+}
+
+// badge formats an unread count for a tab
+func badge(n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n >= pageSize:
+		return fmt.Sprintf(" (%d+)", pageSize)
+	default:
+		return fmt.Sprintf(" (%d)", n)
+	}
+}
+
+func (m *Model) tabTitle(p pane) string {
+	switch p {
+	case paneFeed:
+		return "1 Feed" + badge(m.newPosts)
+	case paneNotifications:
+		return "2 Notifications" + badge(m.unreadNotifications)
+	case paneMessages:
+		return "3 Messages" + badge(m.unreadMessages)
+	case paneThread:
+		return "4 Thread"
+	}
+	return ""
+}
+
+func (m *Model) help() string {
+	switch m.pane {
+	case paneFeed, paneThread:
+		return "↑/↓ move · enter thread · l like · t retweet · r reply · n post · ctrl+r refresh · esc back · q quit"
+	case paneNotifications:
+		return "↑/↓ move · enter open post · n post · ctrl+r refresh · tab switch · q quit"
+	case paneMessages:
+		return "↑/↓ move · enter open · r reply · tab switch · q quit"
+	case paneChat:
+		return "↑/↓ scroll · r reply · esc back · q quit"
+	}
+	return ""
+}
+
+func (m *Model) emptyText() string {
+	switch m.pane {
+	case paneFeed:
+		return "Your feed is empty. Follow some users, or press n to post."
+	case paneNotifications:
+		return "No notifications yet."
+	case paneMessages:
+		return "No conversations yet."
+	case paneThread:
+		return "This thread is empty."
+	case paneChat:
+		return "No messages yet. Press r to write one."
+	}
+	return ""
+}
+
+func (m *Model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	header := m.viewTabs()
+	footer := m.viewFooter()
+
+	composeView := ""
+	if m.compose != nil {
+		composeView = m.compose.view(m.width)
+	}
+
+	bodyHeight := m.height - lipglossHeight(header) - lipglossHeight(footer) - lipglossHeight(composeView) - 1
+	body := m.viewList(m.current(), bodyHeight)
+
+	parts := []string{header, body}
+	if composeView != "" {
+		parts = append(parts, composeView)
+	}
+	parts = append(parts, footer)
+	return strings.Join(parts, "\n")
+}
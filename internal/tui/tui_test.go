@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RazinShafayet2007/twitter-cli/internal/db"
+	"github.com/RazinShafayet2007/twitter-cli/internal/service"
+	"github.com/RazinShafayet2007/twitter-cli/internal/store"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(m *Model, keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m.Update(msg)
+	}
+}
+
+func TestModel(t *testing.T) {
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+	defer database.Close()
+
+	stores := store.NewStores(database)
+	alice, _ := stores.Users.Create("alice")
+	bob, _ := stores.Users.Create("bob")
+	stores.Social.Follow(alice.ID, bob.ID)
+	post, _ := stores.Posts.Create(bob.ID, "hello from bob")
+	stores.Notifications.Create(alice.ID, bob.ID, "follow", nil)
+
+	m := New(stores, alice, service.Options{})
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	if m.unreadNotifications != 1 {
+		t.Errorf("expected a notification badge, got %d", m.unreadNotifications)
+	}
+	if view := m.View(); !strings.Contains(view, "hello from bob") || !strings.Contains(view, "Notifications (1)") {
+		t.Errorf("expected the feed and the badge on screen, got:\n%s", view)
+	}
+
+	// Like the selected post, then unlike it
+	press(m, "l")
+	if liked, _ := stores.Social.HasLiked(alice.ID, post.ID); !liked {
+		t.Fatalf("expected l to like the post, status %q", m.status)
+	}
+	if view := m.View(); !strings.Contains(view, "❤ 1") {
+		t.Errorf("expected the like counted on screen, got:\n%s", view)
+	}
+	press(m, "l")
+	if liked, _ := stores.Social.HasLiked(alice.ID, post.ID); liked {
+		t.Errorf("expected a second l to unlike the post")
+	}
+	if view := m.View(); !strings.Contains(view, "♡ 0") {
+		t.Errorf("expected the unlike counted on screen, got:\n%s", view)
+	}
+
+	// Reply from the thread view
+	press(m, "enter")
+	if m.pane != paneThread {
+		t.Fatalf("expected enter to open the thread, got pane %d", m.pane)
+	}
+	press(m, "r", "n", "i", "c", "e", "enter")
+	if m.compose != nil {
+		t.Fatalf("expected the reply to be sent, status %q", m.status)
+	}
+	if len(m.lists[paneThread].entries) != 2 {
+		t.Errorf("expected the reply in the thread, got %d posts", len(m.lists[paneThread].entries))
+	}
+
+	// Looking at notifications reads them
+	press(m, "esc", "2")
+	if count, _ := stores.Notifications.GetUnreadCount(alice.ID); count != 0 || m.unreadNotifications != 0 {
+		t.Errorf("expected notifications to be read, got %d", count)
+	}
+
+	// Message bob from the conversation
+	stores.Messages.Send(bob.ID, alice.ID, "hi alice")
+	press(m, "3", "enter", "r", "y", "o", "enter")
+	messages, _ := stores.Messages.GetConversation(alice.ID, bob.ID, store.Page{Limit: 10})
+	if len(messages) != 2 || m.pane != paneChat || len(m.lists[paneChat].entries) != 2 {
+		t.Errorf("expected alice's reply in the chat, got %+v", messages)
+	}
+	if count, _ := stores.Messages.GetUnreadCount(alice.ID); count != 0 {
+		t.Errorf("expected opening the chat to read it, got %d unread", count)
+	}
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	accent    = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	dim       = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	errorText = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	activeTab   = lipgloss.NewStyle().Reverse(true).Bold(true).Padding(0, 1)
+	inactiveTab = lipgloss.NewStyle().Padding(0, 1)
+
+	// Every entry has a left border so the selected one can be marked
+	// without the text shifting
+	entryStyle = lipgloss.NewStyle().
+			Border(lipgloss.HiddenBorder(), false, false, false, true).
+			PaddingLeft(1)
+	selectedStyle = entryStyle.
+			Border(lipgloss.ThickBorder(), false, false, false, true).
+			BorderForeground(lipgloss.Color("6"))
+)
+
+func lipglossHeight(s string) int {
+	if s == "" {
+		return 0
+	}
+	return lipgloss.Height(s)
+}
+
+func (m *Model) viewTabs() string {
+	var tabs []string
+	for _, p := range m.tabs() {
+		style := inactiveTab
+		if p == tabOf(m.pane) {
+			style = activeTab
+		}
+		tabs = append(tabs, style.Render(m.tabTitle(p)))
+	}
+
+	title := accent.Render("twt") + " " + dim.Render("@"+m.user.Username)
+	if m.pane == paneChat && m.chat != nil {
+		title += dim.Render(" · chat with @" + m.chat.OtherUsername)
+	}
+
+	return title + "  " + strings.Join(tabs, " ") + "\n"
+}
+
+func (m *Model) viewFooter() string {
+	status := m.status
+	if m.statusErr {
+		status = errorText.Render(status)
+	}
+	return status + "\n" + dim.Render(m.help())
+}
+
+// viewList draws the entries of l that fit in height lines, scrolling so
+// the selected one is on screen
+func (m *Model) viewList(l *list, height int) string {
+	if height < 1 {
+		return ""
+	}
+	if len(l.entries) == 0 {
+		return dim.Render(m.emptyText()) + strings.Repeat("\n", height-1)
+	}
+
+	blocks := make(map[int][]string)
+	block := func(i int) []string {
+		if b, ok := blocks[i]; ok {
+			return b
+		}
+		style := entryStyle
+		if i == l.cursor {
+			style = selectedStyle
+		}
+		text := style.Width(m.width - 2).Render(l.entries[i].render())
+		b := append(strings.Split(text, "\n"), "")
+		blocks[i] = b
+		return b
+	}
+
+	// Scroll so the selection is on screen
+	if l.top > l.cursor {
+		l.top = l.cursor
+	}
+	for l.top < l.cursor {
+		used := 0
+		for i := l.top; i <= l.cursor; i++ {
+			used += len(block(i))
+		}
+		if used <= height {
+			break
+		}
+		l.top++
+	}
+
+	var lines []string
+	for i := l.top; i < len(l.entries) && len(lines) < height; i++ {
+		lines = append(lines, block(i)...)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
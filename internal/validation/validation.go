@@ -2,14 +2,24 @@ package validation
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const (
 	MaxPostLength     = 280
 	MaxUsernameLength = 15
 	MinUsernameLength = 3
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // bcrypt ignores anything longer
+
+	MinPollOptions      = 2
+	MaxPollOptions      = 4
+	MaxPollOptionLength = 25
+	MinPollDuration     = 5 * time.Minute
+	MaxPollDuration     = 7 * 24 * time.Hour
 )
 
 // ValidateUsername checks if a username is valid
@@ -33,6 +43,19 @@ func ValidateUsername(username string) error {
 	return nil
 }
 
+// ValidatePassword checks if a password is acceptable
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	if len(password) > MaxPasswordLength {
+		return errors.New("password must be at most 72 bytes")
+	}
+
+	return nil
+}
+
 // ValidatePostText checks if post text is valid
 func ValidatePostText(text string) error {
 	text = strings.TrimSpace(text)
@@ -48,6 +71,35 @@ func ValidatePostText(text string) error {
 	return nil
 }
 
+// ValidatePoll checks a poll's options, which must be distinct, and how
+// long it stays open
+func ValidatePoll(options []string, duration time.Duration) error {
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return errors.New("a poll needs 2 to 4 options")
+	}
+
+	seen := make(map[string]bool)
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return errors.New("poll options cannot be empty")
+		}
+		if len(option) > MaxPollOptionLength {
+			return fmt.Errorf("poll option %q exceeds 25 characters", option)
+		}
+		if seen[strings.ToLower(option)] {
+			return fmt.Errorf("poll option %q is given twice", option)
+		}
+		seen[strings.ToLower(option)] = true
+	}
+
+	if duration < MinPollDuration || duration > MaxPollDuration {
+		return errors.New("a poll must stay open between 5 minutes and 7 days")
+	}
+
+	return nil
+}
+
 // SanitizeUsername cleans and lowercases username
 func SanitizeUsername(username string) string {
 	return strings.ToLower(strings.TrimSpace(username))
@@ -35,7 +35,7 @@ for PLATFORM in "${PLATFORMS[@]}"; do
     
     echo "Building for ${GOOS}/${GOARCH}..."
     
-    GOOS="$GOOS" GOARCH="$GOARCH" go build \
+    GOOS="$GOOS" GOARCH="$GOARCH" go build -tags sqlite_fts5 \
         -ldflags="-X 'main.Version=$VERSION' -X 'main.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)'" \
         -o "${OUTPUT_DIR}/${OUTPUT_NAME}" \
         .